@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/git"
+	"github.com/thalesfsp/committer/internal/prompt"
+	"github.com/thalesfsp/committer/internal/provider"
+	"github.com/thalesfsp/committer/internal/release"
+	"github.com/thalesfsp/committer/internal/semver"
+	"github.com/thalesfsp/committer/internal/tui"
+	"github.com/thalesfsp/customerror"
+)
+
+// Changelog command flags.
+var (
+	// Ref the changelog starts after, defaults to the latest version tag.
+	changelogFrom string
+
+	// Ref the changelog ends at.
+	changelogTo string
+
+	// Version of the release, defaults to `to` if it's a version tag,
+	// otherwise Unreleased.
+	changelogRelease string
+
+	// Rewrite the entries in user-facing language with the LLM.
+	changelogPolish bool
+
+	// Prepend the release to the changelog file, instead of printing it.
+	changelogWrite bool
+
+	// Path of the changelog file, relative to the root of the repository.
+	changelogFile string
+)
+
+// changelogCmd generates the changelog from the commit history.
+var changelogCmd = &cobra.Command{
+	Use:   "changelog",
+	Short: "Generates the changelog of a release from the conventional commits",
+	Long: `Walks the commits between two refs, by default from the latest version
+tag to HEAD, groups them by type, and scope, and renders them as a Keep a
+Changelog section, or with the template set by "changelog-template"
+(text/template, rendered with .Version, .Date, and .Sections).
+
+Docs, tests, CI, style, and chore commits are left out. With --polish,
+the LLM rewrites the entries in user-facing language. With --write, the
+release is prepended to CHANGELOG.md: the Unreleased section is replaced,
+and released versions already there are never duplicated.`,
+	Example: `  Print the unreleased changes.
+  $ committer changelog
+
+  Add the changes of v1.3.0 to CHANGELOG.md.
+  $ committer changelog --to v1.3.0 --polish --write`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		// Exit if the current directory is not a Git repository.
+		if !repo.IsRepo() {
+			cliLogger.Fatalln(errorcatalog.MustGet(
+				errorcatalog.ErrNotGitRepo).New())
+		}
+
+		tmpl, err := release.LoadChangelogTemplate(cfg.Path("changelog-template"))
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		version, from, err := changelogRange()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		gitCommits, err := repo.CommitsBetween(from, changelogTo)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		rel := release.Release{Version: version, Sections: release.GroupChangelog(releaseCommits(gitCommits))}
+
+		if version != release.Unreleased {
+			if rel.Date, err = repo.CommitDate(changelogTo); err != nil {
+				cliLogger.Fatalln(err)
+			}
+		}
+
+		if changelogPolish && len(rel.Sections) > 0 {
+			rel.Sections = polishChangelog(rel.Sections)
+		}
+
+		section, err := release.RenderChangelog(tmpl, rel)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		if !changelogWrite {
+			fmt.Print(section)
+
+			return
+		}
+
+		path, err := writeChangelog(section, version)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		fmt.Printf("Added %s to %s\n", version, path)
+	},
+}
+
+// changelogRange returns the version of the release, and the ref the
+// changelog starts after: the flag, otherwise the latest version tag lower
+// than the version, or "" if there's none, so the changelog covers the whole
+// history.
+func changelogRange() (string, string, error) {
+	version := changelogRelease
+
+	current, err := semver.Parse(changelogTo)
+	isRelease := err == nil && repo.IsTag(changelogTo)
+
+	if version == "" {
+		version = release.Unreleased
+
+		if isRelease {
+			version = changelogTo
+		}
+	}
+
+	if changelogFrom != "" {
+		return version, changelogFrom, nil
+	}
+
+	tags, err := repo.LatestTags(0)
+	if err != nil {
+		return "", "", err
+	}
+
+	previous := []string{}
+
+	for _, tag := range tags {
+		if v, err := semver.Parse(tag); err == nil && (!isRelease || v.Compare(current) < 0) {
+			previous = append(previous, tag)
+		}
+	}
+
+	from, _, _ := semver.Latest(previous)
+
+	return version, from, nil
+}
+
+// polishChangelog asks the LLM to rewrite the entries in user-facing
+// language. If it fails, the entries are kept as they are.
+func polishChangelog(sections []release.Section) []release.Section {
+	providerInUse, err := initializeProviders()
+	if err != nil {
+		cliLogger.Fatalln(err)
+	}
+
+	tmpl, err := prompt.Load(prompt.ChangelogName, cfg.Path("changelog-prompt-template"))
+	if err != nil {
+		cliLogger.Fatalln(err)
+	}
+
+	p, err := tmpl.Render(prompt.Data{Commits: release.Messages(sections)})
+	if err != nil {
+		cliLogger.Fatalln(err)
+	}
+
+	tui.SpinnerStart("Polishing changelog...")
+
+	response, err := provider.CallLLM(context.Background(), providerInUse, llmAPICallTimeout, p)
+
+	tui.SpinnerStop()
+
+	if err == nil {
+		sections, err = release.Polish(sections, response)
+	}
+
+	if err != nil {
+		cliLogger.Warnln(fmt.Sprintf("Failed to polish the changelog, keeping the commit subjects: %s", err))
+	}
+
+	return sections
+}
+
+// writeChangelog prepends the section of the version to the changelog file,
+// returning its path.
+func writeChangelog(section, version string) (string, error) {
+	path := changelogFile
+
+	if !filepath.IsAbs(path) {
+		repoRoot, err := repo.Root()
+		if err != nil {
+			return "", err
+		}
+
+		path = filepath.Join(repoRoot, path)
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToWriteChangelog, customerror.WithError(err)).NewFailedToError()
+	}
+
+	changelog, err := release.PrependChangelog(string(existing), section, version)
+	if err != nil {
+		return "", err
+	}
+
+	//nolint:gosec // The changelog is meant to be read by everyone.
+	if err := os.WriteFile(path, []byte(changelog), 0o644); err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToWriteChangelog, customerror.WithError(err)).NewFailedToError()
+	}
+
+	return path, nil
+}
+
+// releaseCommits converts commits of the history to commits of a release.
+func releaseCommits(gitCommits []git.Commit) []semver.Commit {
+	commits := make([]semver.Commit, 0, len(gitCommits))
+
+	for _, c := range gitCommits {
+		commits = append(commits, semver.Commit(c))
+	}
+
+	return commits
+}
+
+func init() {
+	addGenerationFlags(changelogCmd)
+
+	changelogCmd.Flags().StringVar(&changelogFrom, "from", "",
+		"Ref the changelog starts after, defaults to the latest version tag")
+	changelogCmd.Flags().StringVar(&changelogTo, "to", "HEAD", "Ref the changelog ends at")
+	changelogCmd.Flags().StringVar(&changelogRelease, "release", "",
+		"Version of the release, defaults to --to if it's a version tag, otherwise Unreleased")
+	changelogCmd.Flags().BoolVar(&changelogPolish, "polish", false,
+		"Rewrite the entries in user-facing language with the LLM")
+	changelogCmd.Flags().BoolVarP(&changelogWrite, "write", "w", false,
+		"Prepend the release to the changelog file, instead of printing it")
+	changelogCmd.Flags().StringVar(&changelogFile, "file", "CHANGELOG.md",
+		"Path of the changelog file, relative to the root of the repository")
+
+	rootCmd.AddCommand(changelogCmd)
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/thalesfsp/committer/internal/config"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/customerror"
+)
+
+// Config command flags.
+var (
+	// Write to the user configuration file instead of the repository one.
+	configGlobal bool
+
+	// Print the effective configuration as JSON.
+	configJSON bool
+)
+
+// configCmd groups the configuration commands.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect, and change the configuration",
+	Long: `Settings are loaded, from the lowest to the highest precedence, from:
+  - Defaults
+  - User configuration file: ~/.config/committer/config.yaml
+  - Repository configuration file: .committer.yaml
+  - Environment variables, e.g.: COMMITTER_CHUNK_THRESHOLD
+  - Flags`,
+}
+
+// configShowCmd prints the effective configuration.
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Prints the effective configuration, and where each value came from",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		settings := cfg.Settings()
+
+		if configJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+
+			if err := enc.Encode(settings); err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+
+		for _, s := range settings {
+			source := s.Source
+			if s.Path != "" {
+				source = fmt.Sprintf("%s (%s)", s.Source, s.Path)
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Value, source)
+		}
+
+		w.Flush()
+	},
+}
+
+// configGetCmd prints the effective value of a setting.
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Prints the effective value of a setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		s, ok := cfg.Get(args[0])
+		if !ok {
+			cliLogger.Fatalln(errorcatalog.MustGet(
+				errorcatalog.ErrInvalidConfigKey,
+				customerror.WithField("key", args[0]),
+			).NewInvalidError())
+		}
+
+		fmt.Println(s.Value)
+	},
+}
+
+// configSetCmd changes a setting in the repository, or user configuration
+// file.
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Changes a setting in the repository, or user (--global) configuration file",
+	Example: `  Use Anthropic in the current repository.
+  $ committer config set provider anthropic
+
+  Use Claude everywhere.
+  $ committer config set --global model claude-3-5-sonnet-20240620`,
+	Args: cobra.ExactArgs(2),
+	Run: func(_ *cobra.Command, args []string) {
+		key, value := args[0], args[1]
+
+		if !config.IsKnown(key) {
+			cliLogger.Fatalln(errorcatalog.MustGet(
+				errorcatalog.ErrInvalidConfigKey,
+				customerror.WithField("key", key),
+			).NewInvalidError())
+		}
+
+		path, err := configPath(configGlobal)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		if err := config.Save(path, key, value); err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		fmt.Printf("%s set to %q in %s\n", key, value, path)
+	},
+}
+
+// configPath returns the path of the user configuration file if global,
+// otherwise the one of the current repository.
+func configPath(global bool) (string, error) {
+	if global {
+		return config.UserPath()
+	}
+
+	repoRoot, err := repo.Root()
+	if err != nil {
+		return "", err
+	}
+
+	return config.RepoPath(repoRoot), nil
+}
+
+func init() {
+	configShowCmd.Flags().BoolVar(&configJSON, "json", false, "Print as JSON")
+	configSetCmd.Flags().BoolVarP(&configGlobal, "global", "g", false,
+		"Write to the user configuration file")
+
+	configCmd.AddCommand(configShowCmd, configGetCmd, configSetCmd)
+
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thalesfsp/committer/internal/hook"
+	"github.com/thalesfsp/committer/internal/prompt"
+	"github.com/thalesfsp/committer/internal/provider"
+	"github.com/thalesfsp/committer/internal/shared"
+	"github.com/thalesfsp/committer/internal/tui"
+)
+
+// hookCmd groups the git hook commands.
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Install, and manage the prepare-commit-msg git hook",
+	Long: `Installs committer as a prepare-commit-msg git hook, so the commit
+message is generated, and pre-filled for plain "git commit", or commits
+from IDEs. Nothing is generated for merges, squashes, amends, or when a
+message is given, e.g.: "git commit -m". Generation failures never block
+the commit. The hooks directory set by core.hooksPath is respected, and an
+existing hook is kept, and called after committer's.
+
+Generation is configured as usual: configuration files, and environment
+variables. Secrets found in the staged changes skip the generation unless
+the "secrets" setting is "redact", or "off".`,
+}
+
+// hookInstallCmd installs the hook.
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Installs the prepare-commit-msg git hook",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		dir, err := repo.HooksDir()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Falls back to committer from the PATH.
+		executable, err := os.Executable()
+		if err != nil {
+			executable = shared.Name
+		}
+
+		status, err := hook.Install(dir, executable)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		fmt.Printf("Installed %s\n", status.Path)
+
+		if status.Chained != "" {
+			fmt.Println(tui.HintStyle.Render(fmt.Sprintf("The existing hook was moved to %s, and is still called.", status.Chained)))
+		}
+	},
+}
+
+// hookUninstallCmd uninstalls the hook.
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Uninstalls the prepare-commit-msg git hook, restoring the previous one",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		dir, err := repo.HooksDir()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		if err := hook.Uninstall(dir); err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		fmt.Println("Uninstalled the hook")
+	},
+}
+
+// hookStatusCmd prints whether the hook is installed.
+var hookStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Prints whether the prepare-commit-msg git hook is installed",
+	Args:  cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		dir, err := repo.HooksDir()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		status, err := hook.GetStatus(dir)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		switch {
+		case status.Installed:
+			fmt.Printf("Installed: %s\n", status.Path)
+		case status.Foreign:
+			fmt.Printf("Not installed, a different hook exists: %s\n", status.Path)
+		default:
+			fmt.Printf("Not installed: %s\n", status.Path)
+		}
+
+		if status.Chained != "" {
+			fmt.Printf("Chained: %s\n", status.Chained)
+		}
+	},
+}
+
+// hookRunCmd is the entry point of the hook, called by git.
+var hookRunCmd = &cobra.Command{
+	Use:    "run <message-file> [source] [sha]",
+	Short:  "Generates the commit message into the message file, called by the hook",
+	Hidden: true,
+	Args:   cobra.RangeArgs(1, 3),
+	Run: func(_ *cobra.Command, args []string) {
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+
+		if !hook.ShouldGenerate(source) {
+			return
+		}
+
+		// Nobody is there to answer prompts.
+		autoAccept = true
+
+		// The commit must go on: the user can still write the message.
+		if err := runHook(args[0], source); err != nil {
+			cliLogger.Warnln(fmt.Sprintf("Skipping commit message generation: %s", err))
+		}
+	},
+}
+
+// runHook generates the commit message of the staged changes, and prepends it
+// to the message file, keeping the comments git added, see hook.Prepend.
+func runHook(path, source string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	diff, excluded, err := repo.Diff(pathFilter())
+	if err != nil {
+		return err
+	}
+
+	// Nothing staged, e.g.: "git commit --allow-empty".
+	if diff == "" && len(excluded) == 0 {
+		return nil
+	}
+
+	providerInUse, err := initializeProviders()
+	if err != nil {
+		return err
+	}
+
+	templates, err := prompt.LoadTemplates(
+		cfg.Path("commit-template"),
+		cfg.Path("summarize-template"),
+	)
+	if err != nil {
+		return err
+	}
+
+	rules, err := enabledLintRules()
+	if err != nil {
+		return err
+	}
+
+	issues, err := issueRules()
+	if err != nil {
+		return err
+	}
+
+	// Only the configured trailers, e.g.: signoff, there are no flags.
+	trailers, err := commitTrailers()
+	if err != nil {
+		return err
+	}
+
+	// Like the message file, the LLM follows commit.template.
+	commitTemplate, err := repo.CommitTemplate()
+	if err != nil {
+		return err
+	}
+
+	redact, err := scanSecrets(diff)
+	if err != nil {
+		return err
+	}
+
+	stats, err := repo.Stats(excluded)
+	if err != nil {
+		return err
+	}
+
+	branch, _ := repo.CurrentBranch()
+	recentLog, _ := repo.RecentLog(10)
+
+	data := prompt.Data{
+		Stats:          stats,
+		Branch:         branch,
+		RecentLog:      recentLog,
+		Examples:       recentExamples(diff),
+		CommitTemplate: commitTemplate,
+	}
+
+	chunks, budget, err := chunkDiff(templates, data, redact(diff))
+	if err != nil {
+		return err
+	}
+
+	message, err := provider.GenerateCommitMessageOnce(
+		context.Background(),
+		providerInUse,
+		llmAPICallTimeout,
+		templates,
+		data,
+		chunks,
+		budget,
+		exampleLintRules(rules, data.Examples),
+		issues,
+	)
+	if err != nil {
+		return err
+	}
+
+	message, err = repo.AddTrailers(message, trailers)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // Keeps the mode of the file created by git.
+	return os.WriteFile(path, []byte(hook.Prepend(string(content), message, source)), 0o644)
+}
+
+func init() {
+	addGenerationFlags(hookRunCmd)
+
+	hookCmd.AddCommand(hookInstallCmd, hookUninstallCmd, hookStatusCmd, hookRunCmd)
+
+	rootCmd.AddCommand(hookCmd)
+}
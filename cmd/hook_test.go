@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thalesfsp/committer/internal/config"
+	"github.com/thalesfsp/committer/internal/git"
+	"github.com/thalesfsp/committer/internal/hook"
+)
+
+// TestHookCommands verifies the hook is installed in, and uninstalled from the
+// hooks directory of the repository.
+func TestHookCommands(t *testing.T) {
+	fake := git.NewFake()
+	fake.HooksPath = t.TempDir()
+
+	previous := repo
+	repo = fake
+
+	t.Cleanup(func() { repo = previous })
+
+	hookInstallCmd.Run(hookInstallCmd, nil)
+
+	if status, err := hook.GetStatus(fake.HooksPath); err != nil || !status.Installed {
+		t.Fatalf("expected the hook installed, got %+v, %v", status, err)
+	}
+
+	hookUninstallCmd.Run(hookUninstallCmd, nil)
+
+	if status, err := hook.GetStatus(fake.HooksPath); err != nil || status.Installed {
+		t.Errorf("expected the hook uninstalled, got %+v, %v", status, err)
+	}
+}
+
+// TestRunHook verifies nothing is generated, nor written without staged
+// changes, e.g.: "git commit --allow-empty".
+func TestRunHook(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	fake := git.NewFake()
+
+	previous, previousCfg := repo, cfg
+	repo = fake
+
+	t.Cleanup(func() { repo, cfg = previous, previousCfg })
+
+	var err error
+
+	if cfg, err = config.Load(""); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+
+	if err := os.WriteFile(path, []byte("# comment\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runHook(path, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if content, _ := os.ReadFile(path); string(content) != "# comment\n" {
+		t.Errorf("expected the message file untouched, got %q", content)
+	}
+}
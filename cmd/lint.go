@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/lint"
+	"github.com/thalesfsp/committer/internal/tui"
+	"github.com/thalesfsp/customerror"
+)
+
+// lintCmd lints a commit message.
+var lintCmd = &cobra.Command{
+	Use:   "lint [file|-]",
+	Short: "Lints a commit message, from a file, or stdin",
+	Long: `Lints a commit message against the lint rules: the defaults, overridden
+by the commitlint configuration file (.commitlintrc.*), if any. The
+defaults are the rules of the commit prompt, stricter than commitlint's
+config-conventional: subjects under 50 characters, in imperative mood,
+and body lines under 72 characters. Comments, and the diff of
+"git commit -v", below the scissors line, are ignored. Exits with a
+non-zero status if there are errors, so it can be used from a commit-msg
+hook:
+
+  #!/bin/sh
+  committer lint "$1"`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		var (
+			content []byte
+			err     error
+		)
+
+		if len(args) == 0 || args[0] == "-" {
+			content, err = io.ReadAll(os.Stdin)
+		} else {
+			content, err = os.ReadFile(args[0])
+		}
+
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		rules, err := loadLintRules()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		violations := lint.Lint(string(content), rules)
+
+		for _, v := range violations {
+			style := tui.WarningStyle
+			if v.Level == lint.Error {
+				style = tui.ErrorStyle
+			}
+
+			fmt.Fprintln(os.Stderr, style.Render(fmt.Sprintf("%s: %s", v.Level, v)))
+		}
+
+		if lint.HasErrors(violations) {
+			cliLogger.Fatalln(errorcatalog.MustGet(
+				errorcatalog.ErrInvalidCommitMessage,
+				customerror.WithField("violations", len(violations)),
+			).NewInvalidError())
+		}
+	},
+}
+
+// lintConfigPath returns the path of the configured commitlint file, or of the
+// one found at the root of the repository, if any.
+func lintConfigPath() string {
+	path := cfg.Path("lint-config")
+
+	if path == "" {
+		if repoRoot, err := repo.Root(); err == nil {
+			path = lint.FindConfigFile(repoRoot)
+		}
+	}
+
+	return path
+}
+
+// loadLintRules loads the lint rules from the commitlint file, if any, on top of
+// the defaults.
+func loadLintRules() (lint.Rules, error) {
+	return lint.LoadRules(lintConfigPath())
+}
+
+// enabledLintRules loads the lint rules generated messages are checked against,
+// unless linting is disabled, in which case they're nil. The LLM follows the
+// commit template, if any, over the conventional format, so without a
+// commitlint file, the default header rules are skipped, instead of asking for
+// repairs the template disagrees with.
+func enabledLintRules() (lint.Rules, error) {
+	if cfg.String("lint") == "false" {
+		return nil, nil
+	}
+
+	path := lintConfigPath()
+
+	rules, err := lint.LoadRules(path)
+	if err != nil || path != "" {
+		return rules, err
+	}
+
+	commitTemplate, err := repo.CommitTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	if commitTemplate != "" {
+		return rules.Without(lint.HeaderRules...), nil
+	}
+
+	return rules, nil
+}
+
+// exampleLintRules returns the lint rules, without the ones most of the
+// examples violate. The LLM matches the style of the recent commits, even over
+// the conventional format, e.g.: "PAY-123: Fix thing", so without a commitlint
+// file, the rules the history disagrees with are skipped, instead of asking for
+// repairs the examples disagree with.
+func exampleLintRules(rules lint.Rules, examples []string) lint.Rules {
+	if rules == nil || lintConfigPath() != "" {
+		return rules
+	}
+
+	return rules.Without(lint.Disagreeing(rules, examples)...)
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thalesfsp/committer/internal/config"
+	"github.com/thalesfsp/committer/internal/git"
+	"github.com/thalesfsp/committer/internal/lint"
+)
+
+// TestEnabledLintRules verifies the default header rules are skipped when
+// following a commit template, unless a commitlint file sets them.
+func TestEnabledLintRules(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	fake := git.NewFake()
+	fake.RootDir = t.TempDir()
+	fake.Template = "Why:\nRefs:\n"
+
+	previous, previousCfg := repo, cfg
+	repo = fake
+
+	t.Cleanup(func() { repo, cfg = previous, previousCfg })
+
+	var err error
+
+	if cfg, err = config.Load(""); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := enabledLintRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := rules[lint.RuleTypeEnum]; ok {
+		t.Error("expected the header rules skipped")
+	}
+
+	if _, ok := rules[lint.RuleBodyMaxLineLength]; !ok {
+		t.Error("expected the body rules kept")
+	}
+
+	path := filepath.Join(fake.RootDir, ".commitlintrc.json")
+
+	if err := os.WriteFile(path, []byte(`{"rules": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if rules, err = enabledLintRules(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := rules[lint.RuleTypeEnum]; !ok {
+		t.Error("expected the header rules of the commitlint file")
+	}
+}
+
+// TestExampleLintRules verifies the rules most recent commits violate are
+// skipped, unless a commitlint file sets them.
+func TestExampleLintRules(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	fake := git.NewFake()
+	fake.RootDir = t.TempDir()
+
+	previous, previousCfg := repo, cfg
+	repo = fake
+
+	t.Cleanup(func() { repo, cfg = previous, previousCfg })
+
+	var err error
+
+	if cfg, err = config.Load(""); err != nil {
+		t.Fatal(err)
+	}
+
+	examples := []string{"PAY-123: Fix double charge", "PAY-124: Add refunds"}
+
+	if rules := exampleLintRules(lint.DefaultRules(), examples); len(lint.Lint(examples[0], rules)) != 0 {
+		t.Error("expected the rules the examples violate skipped")
+	}
+
+	if rules := exampleLintRules(nil, examples); rules != nil {
+		t.Errorf("expected linting kept disabled, got %v", rules)
+	}
+
+	path := filepath.Join(fake.RootDir, ".commitlintrc.json")
+
+	if err := os.WriteFile(path, []byte(`{"rules": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if rules := exampleLintRules(lint.DefaultRules(), examples); len(lint.Lint(examples[0], rules)) == 0 {
+		t.Error("expected the rules of the commitlint file")
+	}
+}
@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/prompt"
+	"github.com/thalesfsp/committer/internal/provider"
+	"github.com/thalesfsp/committer/internal/pullrequest"
+	"github.com/thalesfsp/committer/internal/shared"
+	"github.com/thalesfsp/committer/internal/tui"
+)
+
+// Pull request command flags.
+var (
+	// Branch the pull request is merged into.
+	prBase string
+
+	// File the pull request is written to, instead of stdout.
+	prFile string
+)
+
+// prCmd generates the title, and description of a pull request.
+var prCmd = &cobra.Command{
+	Use:   "pr",
+	Short: "Generates the title, and description of a pull request of the current branch",
+	Long: `Diffs the current branch against its merge base with the base branch,
+by default "pr-base", or the one the push remote's HEAD points to, e.g.:
+origin/main, and asks the LLM for the title, and description of the pull
+request, from the commits, and the diff, chunked, and summarized if too
+big.
+
+The description follows the repository's pull request template, e.g.:
+.github/pull_request_template.md, or the one set by "pr-template". The
+title is printed on the first line, followed by a blank line, and the
+description, to stdout, or to the file set by --file.`,
+	Example: `  Open a pull request with GitHub's CLI.
+  $ committer pr --base main -f pr.md
+  $ gh pr create --title "$(head -n1 pr.md)" --body "$(tail -n+3 pr.md)"`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		// Exit if the current directory is not a Git repository.
+		if !repo.IsRepo() {
+			cliLogger.Fatalln(errorcatalog.MustGet(
+				errorcatalog.ErrNotGitRepo).New())
+		}
+
+		providerInUse, err := initializeProviders()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Same map-reduce as commit messages, reduced with the pull request
+		// template.
+		prTemplate, err := prompt.Load(prompt.PullRequestName, cfg.Path("pr-prompt-template"))
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		summarizeTemplate, err := prompt.Load(prompt.SummarizeName, cfg.Path("summarize-template"))
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		templates := &prompt.Templates{Commit: prTemplate, Summarize: summarizeTemplate}
+
+		descriptionTemplate, err := loadDescriptionTemplate()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		base := prBase
+		if base == "" {
+			base = cfg.String("pr-base")
+		}
+
+		if base == "" {
+			if base, err = repo.DefaultBranch(); err != nil {
+				cliLogger.Fatalln(err)
+			}
+		}
+
+		mergeBase, err := repo.MergeBase(base)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		gitCommits, err := repo.CommitsBetween(mergeBase, "HEAD")
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		if len(gitCommits) == 0 {
+			fmt.Println(tui.HintStyle.Render(fmt.Sprintf("The branch has no commits since %s.", base)))
+
+			shared.NothingToDo()
+		}
+
+		commits := make([]string, 0, len(gitCommits))
+
+		for _, c := range gitCommits {
+			commits = append(commits, c.Message)
+		}
+
+		tui.SpinnerStart("Getting diff...")
+
+		diff, excluded, err := repo.RangeDiff(pathFilter(), mergeBase, "HEAD")
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		tui.SpinnerStop()
+
+		redact, err := scanSecrets(diff)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		stats, err := repo.RangeStats(excluded, mergeBase, "HEAD")
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		branch, _ := repo.CurrentBranch()
+
+		data := prompt.Data{
+			Base:                base,
+			Branch:              branch,
+			Commits:             commits,
+			DescriptionTemplate: descriptionTemplate,
+			Stats:               stats,
+		}
+
+		chunks, budget, err := chunkDiff(templates, data, redact(diff))
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		tui.SpinnerStart("Generating pull request...")
+
+		response, err := provider.GenerateCommitMessageOnce(
+			context.Background(),
+			providerInUse,
+			llmAPICallTimeout,
+			templates,
+			data,
+			chunks,
+			budget,
+			nil,
+			nil,
+		)
+
+		tui.SpinnerStop()
+
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		pr := pullrequest.Parse(response)
+
+		if pr.Title == "" {
+			cliLogger.Fatalln(errorcatalog.MustGet(
+				errorcatalog.ErrEmptyPullRequest).NewMissingError())
+		}
+
+		if prFile == "" {
+			fmt.Print(pr)
+
+			return
+		}
+
+		//nolint:gosec // The description isn't sensitive.
+		if err := os.WriteFile(prFile, []byte(pr.String()), 0o644); err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		fmt.Printf("Written to %s\n", prFile)
+	},
+}
+
+// loadDescriptionTemplate loads the pull request template set by
+// "pr-template", otherwise the one found in the repository, if any.
+func loadDescriptionTemplate() (string, error) {
+	path := cfg.Path("pr-template")
+
+	if path == "" {
+		if repoRoot, err := repo.Root(); err == nil {
+			path = pullrequest.FindTemplate(repoRoot)
+		}
+	}
+
+	if path == "" {
+		return "", nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+func init() {
+	addGenerationFlags(prCmd)
+
+	prCmd.Flags().StringVar(&prBase, "base", "",
+		"Branch the pull request is merged into, defaults to the push remote's default branch")
+	prCmd.Flags().StringVarP(&prFile, "file", "f", "",
+		"File the pull request is written to, instead of stdout")
+
+	rootCmd.AddCommand(prCmd)
+}
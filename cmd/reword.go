@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/git"
+	"github.com/thalesfsp/committer/internal/issue"
+	"github.com/thalesfsp/committer/internal/lint"
+	"github.com/thalesfsp/committer/internal/prompt"
+	"github.com/thalesfsp/committer/internal/provider"
+	"github.com/thalesfsp/committer/internal/shared"
+	"github.com/thalesfsp/committer/internal/trailer"
+	"github.com/thalesfsp/committer/internal/tui"
+	"github.com/thalesfsp/customerror"
+)
+
+// rewordCmd regenerates the messages of past commits.
+var rewordCmd = &cobra.Command{
+	Use:   "reword <rev-range>",
+	Short: "Regenerates the messages of past, unpushed commits",
+	Long: `Regenerates the message of every commit of the range, e.g.:
+HEAD~3..HEAD, or HEAD~3 for short, from its diff, and shows the old, and
+the new messages side by side. History is rewritten only after
+confirmation, keeping the changes, authors, and dates of the commits, and
+leaving the working tree, and the index untouched. The trailers of the
+old messages, e.g.: Signed-off-by, are kept, and the configured ones, or
+the ones set by the flags are added.
+
+The range must end at HEAD, and commits already on the upstream branch
+are never rewritten. Without upstream branch, commits already on any
+remote branch are only rewritten with --force.`,
+	Example: `  Reword the last 3 commits.
+  $ committer reword HEAD~3
+
+  Reword the commits of the branch.
+  $ committer reword main..HEAD`,
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		// Exit if the current directory is not a Git repository.
+		if !repo.IsRepo() {
+			cliLogger.Fatalln(errorcatalog.MustGet(
+				errorcatalog.ErrNotGitRepo).New())
+		}
+
+		from, err := rewordRange(args[0])
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		commits, err := repo.CommitsBetween(from, "HEAD")
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		if len(commits) == 0 {
+			fmt.Println(tui.HintStyle.Render(fmt.Sprintf("There are no commits in %s.", args[0])))
+
+			shared.NothingToDo()
+		}
+
+		hashes := make([]string, 0, len(commits))
+
+		for _, c := range commits {
+			hashes = append(hashes, c.Hash)
+		}
+
+		if err := checkUnpushed(hashes...); err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		providerInUse, err := initializeProviders()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		templates, err := prompt.LoadTemplates(
+			cfg.Path("commit-template"),
+			cfg.Path("summarize-template"),
+		)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		rules, err := enabledLintRules()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Issue references of the branch, added to generated messages.
+		issues, err := issueRules()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Trailers, e.g.: co-authors, added to every commit.
+		trailers, err := commitTrailers()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		signing, err := commitSigning()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		commitTemplate, err := repo.CommitTemplate()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		branch, _ := repo.CurrentBranch()
+
+		// Every message is generated before anything is rewritten, so exiting
+		// leaves the history untouched.
+		messages := map[string]string{}
+
+		for i, c := range commits {
+			message, err := rewordCommit(providerInUse, templates, rules, issues, branch, commitTemplate, c, i, len(commits))
+			if err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			message, err = keepTrailers(c.Message, message, trailers)
+			if err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			fmt.Printf("%s\n%s\n\n",
+				tui.QuestionStyle.Render(fmt.Sprintf("Commit %d of %d: %s", i+1, len(commits), c.Hash[:min(7, len(c.Hash))])),
+				tui.Compare("Old", c.Message, "New", message, tui.CompareWidth),
+			)
+
+			if message != c.Message {
+				messages[c.Hash] = message
+			}
+		}
+
+		if len(messages) == 0 {
+			fmt.Println(tui.HintStyle.Render("The messages are the same."))
+
+			shared.NothingToDo()
+		}
+
+		if !autoAccept && !tui.MustPromptYesNoTea("Would you like to rewrite the history?", false) {
+			shared.NothingToDo()
+		}
+
+		tui.SpinnerStart("Rewording commits...")
+
+		var previous string
+
+		err = runGit(signing.SignsCommits(), func() error {
+			previous, err = repo.RewordCommits(from, messages, signing)
+
+			return err
+		})
+
+		tui.SpinnerStop()
+
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		fmt.Printf("%s\n", tui.HintStyle.Render(fmt.Sprintf(
+			"Reworded %d commits. Undo with: git reset --soft %s", len(messages), previous[:min(7, len(previous))])))
+	},
+}
+
+// rewordRange returns the commit the range starts after. Ranges are
+// "<from>..<to>", where `to` defaults to, and must be HEAD, or just "<from>".
+func rewordRange(revRange string) (string, error) {
+	from, to, found := strings.Cut(revRange, "..")
+
+	invalid := func(reason string) error {
+		return errorcatalog.MustGet(
+			errorcatalog.ErrInvalidRevisionRange,
+			customerror.WithField("range", revRange),
+			customerror.WithError(errors.New(reason)),
+		).NewInvalidError()
+	}
+
+	if from == "" || strings.HasPrefix(to, ".") {
+		return "", invalid("expected <from>..HEAD, or <from>")
+	}
+
+	if !found || to == "" {
+		to = "HEAD"
+	}
+
+	head, err := repo.ResolveCommit("HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	if end, err := repo.ResolveCommit(to); err != nil || end != head {
+		return "", invalid("it must end at HEAD")
+	}
+
+	return repo.ResolveCommit(from)
+}
+
+// rewordCommit generates the new message of the commit, from its diff.
+func rewordCommit(
+	providerInUse provider.LLM,
+	templates *prompt.Templates,
+	rules lint.Rules,
+	issues *issue.Rules,
+	branch, commitTemplate string,
+	c git.Commit,
+	i, total int,
+) (string, error) {
+	diff, excluded, err := repo.CommitDiff(pathFilter(), c.Hash)
+	if err != nil {
+		return "", err
+	}
+
+	redact, err := scanSecrets(diff)
+	if err != nil {
+		return "", err
+	}
+
+	stats, err := repo.CommitStats(excluded, c.Hash)
+	if err != nil {
+		return "", err
+	}
+
+	data := prompt.Data{Stats: stats, Branch: branch, CommitTemplate: commitTemplate}
+
+	chunks, budget, err := chunkDiff(templates, data, redact(diff))
+	if err != nil {
+		return "", err
+	}
+
+	tui.SpinnerStart(fmt.Sprintf("Generating message %d of %d...", i+1, total))
+
+	message, err := provider.GenerateCommitMessageOnce(
+		context.Background(),
+		providerInUse,
+		llmAPICallTimeout,
+		templates,
+		data,
+		chunks,
+		budget,
+		rules,
+		issues,
+	)
+
+	tui.SpinnerStop()
+
+	if err != nil {
+		return "", err
+	}
+
+	if message == "" {
+		return "", errorcatalog.MustGet(errorcatalog.ErrEmptyCommitMessage).NewMissingError()
+	}
+
+	return message, nil
+}
+
+// keepTrailers adds the trailers of the old message, e.g.: Signed-off-by, and
+// the given ones to the new message.
+func keepTrailers(old, message string, trailers []string) (string, error) {
+	return repo.AddTrailers(message, slices.Concat(trailer.FromMessage(old), trailers))
+}
+
+// checkUnpushed fails if any of the commits is already on the upstream branch,
+// as rewriting them would diverge from it. Without upstream branch, e.g.: the
+// branch was pushed without -u, commits already on any remote branch are only
+// rewritten with --force.
+func checkUnpushed(commits ...string) error {
+	pushed := []string{}
+
+	if upstream := repo.Upstream(); upstream != "" {
+		for _, c := range commits {
+			if repo.IsAncestor(c, upstream) {
+				pushed = append(pushed, c[:min(7, len(c))])
+			}
+		}
+
+		if len(pushed) == 0 {
+			return nil
+		}
+
+		return errorcatalog.MustGet(
+			errorcatalog.ErrCommitsPushed,
+			customerror.WithField("upstream", upstream),
+			customerror.WithField("commits", strings.Join(pushed, ", ")),
+		).New()
+	}
+
+	if force {
+		return nil
+	}
+
+	remoteBranches := []string{}
+
+	for _, c := range commits {
+		branches, err := repo.RemoteBranches(c)
+		if err != nil {
+			return err
+		}
+
+		if len(branches) > 0 {
+			pushed = append(pushed, c[:min(7, len(c))])
+		}
+
+		for _, branch := range branches {
+			if !slices.Contains(remoteBranches, branch) {
+				remoteBranches = append(remoteBranches, branch)
+			}
+		}
+	}
+
+	if len(pushed) == 0 {
+		return nil
+	}
+
+	return errorcatalog.MustGet(
+		errorcatalog.ErrCommitsOnRemote,
+		customerror.WithField("branches", strings.Join(remoteBranches, ", ")),
+		customerror.WithField("commits", strings.Join(pushed, ", ")),
+	).New()
+}
+
+func init() {
+	addGenerationFlags(rewordCmd)
+	addTrailerFlags(rewordCmd)
+	addSigningFlags(rewordCmd)
+
+	rewordCmd.Flags().BoolVar(&force, "force", false,
+		"Rewrite commits already on remote branches, when the branch has no upstream branch")
+
+	rootCmd.AddCommand(rewordCmd)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thalesfsp/committer/internal/git"
+)
+
+// TestCheckUnpushed verifies only commits not on the upstream branch can be
+// rewritten.
+func TestCheckUnpushed(t *testing.T) {
+	fake := git.NewFake()
+
+	previous := repo
+	repo = fake
+
+	t.Cleanup(func() { repo = previous })
+
+	fake.Worktree["a.txt"] = "a\n"
+
+	if err := fake.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fake.Commit("feat: add a", git.Signing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	head, _ := fake.ResolveCommit("HEAD")
+
+	if err := checkUnpushed(head); err != nil {
+		t.Errorf("without upstream branch, commits must be rewritable: %v", err)
+	}
+
+	// Pushed without -u, or on another branch.
+	fake.Remotes = map[string][]string{"origin/feature": {head}}
+
+	if err := checkUnpushed(head); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("commits on remote branches must require --force, got %v", err)
+	}
+
+	force = true
+
+	if err := checkUnpushed(head); err != nil {
+		t.Errorf("with --force, commits on remote branches must be rewritable: %v", err)
+	}
+
+	force = false
+	fake.Remotes = nil
+
+	if err := fake.Push(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := checkUnpushed(head); err == nil {
+		t.Error("pushed commits must not be rewritable")
+	}
+
+	if err := fake.CommitAmend("feat: add a file", git.Signing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	amended, _ := fake.ResolveCommit("HEAD")
+
+	if err := checkUnpushed(amended); err != nil {
+		t.Errorf("amended commits must be rewritable: %v", err)
+	}
+}
+
+// TestKeepTrailers verifies the trailers of the old message are kept, along
+// with the configured ones, without duplicates.
+func TestKeepTrailers(t *testing.T) {
+	previous := repo
+	repo = git.NewFake()
+
+	t.Cleanup(func() { repo = previous })
+
+	old := "fix: bug\n\nSigned-off-by: Me <me@example.com>\nReviewed-by: Jane <jane@example.com>"
+
+	message, err := keepTrailers(old, "fix: prevent double charge\n\nBody.", []string{
+		"Signed-off-by: Me <me@example.com>",
+		"Co-authored-by: Joe <joe@example.com>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "fix: prevent double charge\n\nBody.\n\n" +
+		"Signed-off-by: Me <me@example.com>\n" +
+		"Reviewed-by: Jane <jane@example.com>\n" +
+		"Co-authored-by: Joe <joe@example.com>"
+
+	if message != expected {
+		t.Errorf("message = %q, want %q", message, expected)
+	}
+}
@@ -1,22 +1,35 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/thalesfsp/committer/internal/config"
 	"github.com/thalesfsp/committer/internal/errorcatalog"
 	"github.com/thalesfsp/committer/internal/git"
+	"github.com/thalesfsp/committer/internal/gitdiff"
+	"github.com/thalesfsp/committer/internal/issue"
+	"github.com/thalesfsp/committer/internal/model"
+	"github.com/thalesfsp/committer/internal/pathfilter"
+	"github.com/thalesfsp/committer/internal/prompt"
 	"github.com/thalesfsp/committer/internal/provider"
+	"github.com/thalesfsp/committer/internal/release"
+	"github.com/thalesfsp/committer/internal/secrets"
+	"github.com/thalesfsp/committer/internal/semver"
 	"github.com/thalesfsp/committer/internal/shared"
+	"github.com/thalesfsp/committer/internal/textsplitter"
+	"github.com/thalesfsp/committer/internal/trailer"
 	"github.com/thalesfsp/committer/internal/tui"
 	"github.com/thalesfsp/customerror"
-	"github.com/thalesfsp/inference/anthropic"
-	"github.com/thalesfsp/inference/huggingface"
-	"github.com/thalesfsp/inference/ollama"
 	"github.com/thalesfsp/inference/openai"
 	"github.com/thalesfsp/sypl/v2"
 	"github.com/thalesfsp/sypl/v2/level"
@@ -28,6 +41,9 @@ var (
 	// Auto-accept mode: add all, approve generated message, push, skip tag.
 	autoAccept bool
 
+	// Strategy used to split large diffs into chunks.
+	chunkStrategy string
+
 	// Threshold for how large a diff chunk can be before splitting.
 	chunkThreshold int
 
@@ -39,8 +55,106 @@ var (
 
 	// The provider for the LLM service.
 	llmProvider string
+
+	// What to do when staged changes contain secrets.
+	secretsMode string
+
+	// Number of recent commits shown to the LLM as examples of the style of
+	// the repository.
+	styleExamples int
+
+	// Only use recent commits changing the same files as examples.
+	styleExamplesTouched bool
+
+	// Sign off commits, certifying the Developer Certificate of Origin.
+	signoff bool
+
+	// Co-authors of the commit, identities, or names, or emails of known
+	// co-authors.
+	coAuthors []string
+
+	// Pick co-authors of the commit from the known ones.
+	pickCoAuthors bool
+
+	// Custom trailers of the commit, e.g.: "Reviewed-by: Jane <jane@example.com>".
+	customTrailers []string
+
+	// Sign commits with the GPG, or SSH key of git.
+	signCommits bool
+
+	// Key signing commits, and tags, instead of user.signingKey.
+	signingKey string
+
+	// Format of the signatures, instead of gpg.format.
+	signingFormat string
+
+	// Pre-release identifier of the suggested tag, e.g.: "rc".
+	preRelease string
+
+	// Build metadata of the suggested tag.
+	buildMetadata string
+
+	// Sign tags with the configured GPG, or SSH key.
+	signTag bool
+
+	// Regenerate the message of HEAD, and amend it with the staged changes.
+	amend bool
+
+	// Rewrite commits already on remote branches, without upstream branch.
+	force bool
+
+	// Generate, and print the message, without touching anything.
+	dryRun bool
+
+	// Format of the result: text, or json.
+	outputFormat string
+
+	// File the message is written to.
+	messageFile string
+)
+
+// Formats of the result.
+const (
+	outputText = "text"
+	outputJSON = "json"
 )
 
+// outputFormats are the allowed formats of the result.
+var outputFormats = []string{outputText, outputJSON}
+
+// maxExamplesShare limits the style examples to a share of the context window
+// of the model, e.g.: 4 is a quarter.
+const maxExamplesShare = 4
+
+// result is the generated commit message, and how it was generated, printed
+// with --output json.
+type result struct {
+	// Message is the generated commit message.
+	Message string `json:"message"`
+
+	// Provider, and Model produced the message, e.g.: a fallback.
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+
+	// Usage of the LLM, including the summaries of the chunks.
+	Usage provider.Usage `json:"usage"`
+
+	// Chunks is the number of chunks the diff was split into.
+	Chunks int `json:"chunks"`
+
+	// DurationMs is how long the generation took, in milliseconds.
+	DurationMs int64 `json:"durationMs"`
+
+	// Committed tells if the message was committed, false with --dry-run.
+	Committed bool `json:"committed"`
+}
+
+// cfg is the effective configuration, loaded before any command runs.
+var cfg *config.Config
+
+// repo is the Git repository of the current directory.
+var repo git.Repository = git.NewExec()
+
 // Logger setup for the CLI with default settings.
 var cliLogger = sypl.NewDefault(
 	shared.Name,
@@ -64,13 +178,17 @@ Providers:
   OPENAI_API_KEY env var to be set while Claude (Anthropic)
   requires the ANTHROPIC_API_KEY env var. For the Ollama provider
   you can set its endpoint by setting the OLLAMA_ENDPOINT env var.
-  Hugging Face provider requires HUGGINGFACE_API_KEY env var.`,
+  Hugging Face provider requires HUGGINGFACE_API_KEY env var.
+  The openai-compatible provider talks to any endpoint speaking the
+  OpenAI chat completions protocol (vLLM, LM Studio, gateways), see
+  the openai-compatible.* settings of "committer config".`,
 	Example: `  Use Anthropic provider with their most capable model.
   $ committer -p anthropic -m claude-3-5-sonnet-20240620
   
   Use Hugging Face provider with Qwen/Qwen2.5-Coder-32B-Instruct
   $ committer -p huggingface -m Qwen/Qwen2.5-Coder-32B-Instruct
   `,
+	PersistentPreRunE: loadConfig,
 	Run: func(_ *cobra.Command, _ []string) {
 		// Check if debug mode is enabled and set a breakpoint if so.
 		if shared.IsDebugMode() {
@@ -78,32 +196,117 @@ Providers:
 		}
 
 		// Exit if the current directory is not a Git repository.
-		if !git.IsCurrentDirectoryGitRepo() {
+		if !repo.IsRepo() {
 			cliLogger.Fatalln(errorcatalog.MustGet(
 				errorcatalog.ErrNotGitRepo).New())
 		}
 
-		// Initialize the LLM provider using configuration provided by the user.
-		providerInUse, err := provider.InitializeLLMProvider(
-			llmProvider,
-			llmModel,
+		// Initialize the LLM provider, and its fallbacks, using configuration
+		// provided by the user.
+		providerInUse, err := initializeProviders()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		if !slices.Contains(outputFormats, outputFormat) {
+			cliLogger.Fatalln(errorcatalog.MustGet(
+				errorcatalog.ErrInvalidOutputFormat,
+				customerror.WithField("output", outputFormat),
+			).NewInvalidError())
+		}
+
+		// Spinners are drawn on stderr, so stdout only has the JSON document.
+		if outputFormat == outputJSON {
+			tui.SetOutput(os.Stderr)
+		}
+
+		// Load, and validate the prompt templates before any LLM call.
+		templates, err := prompt.LoadTemplates(
+			cfg.Path("commit-template"),
+			cfg.Path("summarize-template"),
 		)
 		if err != nil {
 			cliLogger.Fatalln(err)
 		}
 
+		// Lint rules generated messages are checked against, if enabled.
+		rules, err := enabledLintRules()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Issue references of the branch, added to generated messages.
+		issues, err := issueRules()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Trailers, e.g.: co-authors, are known before generating, so
+		// mistakes don't waste a call to the LLM.
+		trailers, err := commitTrailers()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		signing, err := commitSigning()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Messages given with "git commit -m" skip commit.template, so the
+		// LLM follows it instead.
+		commitTemplate, err := repo.CommitTemplate()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Amending only rewrites unpushed commits, with what's already staged.
+		if amend {
+			if !repo.HasCommits() {
+				fmt.Fprintln(hintOutput(), tui.HintStyle.Render("There's no commit to amend."))
+
+				shared.NothingToDo()
+			}
+
+			head, err := repo.ResolveCommit("HEAD")
+			if err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			if err := checkUnpushed(head); err != nil {
+				cliLogger.Fatalln(err)
+			}
+		}
+
 		// If there are no changes to be committed, exit the process.
-		if !git.HasStagedChanges() && !git.IsDirty() {
+		if !amend && !repo.HasStagedChanges() && !repo.IsDirty() {
 			shared.NothingToDo()
 		}
 
-		// Stage changes: auto-add in auto-accept mode, otherwise prompt.
-		if !git.HasStagedChanges() {
-			if autoAccept || tui.MustPromptYesNoTea(
-				"Would you like to add all changes?", false) {
+		// Stage changes: auto-add in auto-accept mode, otherwise prompt. Dry
+		// runs, and scripts without auto-accept, only use what's staged.
+		if !amend && !repo.HasStagedChanges() {
+			if dryRun || (!autoAccept && !canPrompt()) {
+				fmt.Fprintln(hintOutput(), tui.HintStyle.Render("Nothing is staged."))
+
+				shared.NothingToDo()
+			}
+
+			choice := "All changes"
+
+			if !autoAccept {
+				choice = tui.MustPromptWithChoices("What would you like to stage?", []string{
+					"All changes",
+					"Pick files, and hunks",
+					"Nothing, exit",
+				})
+			}
+
+			switch choice {
+			case "All changes":
 				tui.SpinnerStart("Adding files...")
 
-				if err := git.GitAddAll(); err != nil {
+				if err := repo.AddAll(); err != nil {
 					tui.SpinnerStop()
 
 					cliLogger.Fatalln(
@@ -115,7 +318,11 @@ Providers:
 				}
 
 				tui.SpinnerStop()
-			} else {
+			case "Pick files, and hunks":
+				if err := pickChanges(); err != nil {
+					cliLogger.Fatalln(err)
+				}
+			default:
 				shared.NothingToDo()
 			}
 		}
@@ -123,38 +330,103 @@ Providers:
 		// Retrieve and process the Git diff and stats.
 		tui.SpinnerStart("Getting diff...")
 
-		diff, err := git.GetGitDiff()
+		var (
+			diff     string
+			excluded []string
+		)
+
+		// The amended commit has the changes of HEAD too.
+		if amend {
+			diff, excluded, err = repo.AmendDiff(pathFilter())
+		} else {
+			diff, excluded, err = repo.Diff(pathFilter())
+		}
+
 		if err != nil {
 			cliLogger.Fatalln(err)
 		}
 
 		tui.SpinnerStop()
 
+		redact, err := scanSecrets(diff)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		diff = redact(diff)
+
 		tui.SpinnerStart("Getting stats...")
 
-		stats, err := git.GetGitStats()
+		var stats string
+
+		if amend {
+			stats, err = repo.AmendStats(excluded)
+		} else {
+			stats, err = repo.Stats(excluded)
+		}
+
 		if err != nil {
 			cliLogger.Fatalln(err)
 		}
 
 		tui.SpinnerStop()
 
-		// If needed, chunk the Git diff based on the defined threshold.
+		// Branch, and recent history are optional context for the prompts.
+		branch, _ := repo.CurrentBranch()
+		recentLog, _ := repo.RecentLog(10)
+
+		data := prompt.Data{
+			Stats:          stats,
+			Branch:         branch,
+			RecentLog:      recentLog,
+			Examples:       recentExamples(diff),
+			CommitTemplate: commitTemplate,
+		}
+
+		// If needed, chunk the Git diff to fit the context window.
 		tui.SpinnerStart("Generating chunks...")
 
-		chunks, err := provider.ChunkDiff(chunkThreshold, diff)
+		chunks, budget, err := chunkDiff(templates, data, diff)
 		if err != nil {
 			cliLogger.Fatalln(err)
 		}
 
 		tui.SpinnerStop()
 
-		// Generate the commit message by communicating with the LLM.
-		commitMessage, err := provider.GenerateCommitMessageLoop(
-			providerInUse,
-			llmAPICallTimeout,
-			stats, chunks,
-			autoAccept)
+		// Generate the commit message by communicating with the LLM, without
+		// the review loop if the user can't be prompted.
+		start := time.Now()
+
+		var commitMessage string
+
+		if canPrompt() {
+			commitMessage, err = provider.GenerateCommitMessageLoop(
+				providerInUse,
+				llmAPICallTimeout,
+				templates,
+				data,
+				chunks,
+				budget,
+				exampleLintRules(rules, data.Examples),
+				issues,
+				autoAccept)
+		} else {
+			tui.SpinnerStart("Generating commit message...")
+
+			commitMessage, err = provider.GenerateCommitMessageOnce(
+				context.Background(),
+				providerInUse,
+				llmAPICallTimeout,
+				templates,
+				data,
+				chunks,
+				budget,
+				exampleLintRules(rules, data.Examples),
+				issues)
+
+			tui.SpinnerStop()
+		}
+
 		if err != nil {
 			cliLogger.Fatalln(err)
 		}
@@ -165,30 +437,77 @@ Providers:
 				errorcatalog.ErrEmptyCommitMessage).NewMissingError())
 		}
 
+		commitMessage, err = repo.AddTrailers(commitMessage, trailers)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		name, model, _ := strings.Cut(providerInUse.Used(), "/")
+
+		res := result{
+			Message:    commitMessage,
+			Provider:   name,
+			Model:      model,
+			Usage:      providerInUse.Usage(),
+			Chunks:     len(chunks),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+
+		if messageFile != "" {
+			//nolint:gosec // The message isn't sensitive.
+			if err := os.WriteFile(messageFile, []byte(commitMessage+"\n"), 0o644); err != nil {
+				cliLogger.Fatalln(errorcatalog.MustGet(
+					errorcatalog.ErrFailedToWriteMessageFile,
+					customerror.WithError(err),
+				).NewFailedToError())
+			}
+		}
+
+		if dryRun {
+			printResult(res)
+
+			os.Exit(0)
+		}
+
 		// Commit the changes using the generated commit message.
 		tui.SpinnerStart("Committing changes...")
 
-		if err := git.GitCommit(commitMessage); err != nil {
+		commit := repo.Commit
+		if amend {
+			commit = repo.CommitAmend
+		}
+
+		if err := runGit(signing.SignsCommits(), func() error {
+			return commit(commitMessage, signing)
+		}); err != nil {
 			cliLogger.Fatalln(err)
 		}
 
 		tui.SpinnerStop()
 
-		// Push: auto-push in auto-accept mode, otherwise prompt.
+		// Scripts get the result, users already reviewed it.
+		if !canPrompt() {
+			res.Committed = true
+
+			printResult(res)
+		}
+
+		// Push: auto-push in auto-accept mode, otherwise prompt, if possible.
 		tui.SpinnerStart("Pushing changes...")
 
-		if autoAccept || tui.MustPromptYesNoTea("Would you like to push the commits?", true) {
-			if err := git.GitPush(); err != nil {
+		if autoAccept || (canPrompt() && tui.MustPromptYesNoTea("Would you like to push the commits?", true)) {
+			if err := repo.Push(); err != nil {
 				cliLogger.Fatalln(err)
 			}
 		}
 
 		tui.SpinnerStop()
 
-		// Skip tagging in auto-accept mode. Otherwise, offer smart tagging.
-		if !autoAccept {
+		// Skip tagging in auto-accept mode, and scripts. Otherwise, offer
+		// smart tagging.
+		if !autoAccept && canPrompt() {
 			if tui.MustPromptYesNoTea("Would you like to tag the commit?", false) {
-				handleTagging()
+				handleTagging(providerInUse)
 			}
 		}
 
@@ -197,107 +516,642 @@ Providers:
 	},
 }
 
-// bumpPatch takes a semver tag like "v1.2.3" and returns "v1.2.4".
-// Returns empty string if the tag doesn't match a recognized semver pattern.
-func bumpPatch(tag string) string {
-	raw := tag
-	prefix := ""
+// loadConfig loads the effective configuration, and applies it to the flags
+// of the command which weren't explicitly set, so flags keep the highest
+// precedence.
+func loadConfig(cmd *cobra.Command, _ []string) error {
+	repoRoot := ""
 
-	if strings.HasPrefix(raw, "v") {
-		prefix = "v"
-		raw = raw[1:]
+	if root, err := repo.Root(); err == nil {
+		repoRoot = root
 	}
 
-	parts := strings.Split(raw, ".")
-	if len(parts) != 3 {
-		return ""
+	c, err := config.Load(repoRoot)
+	if err != nil {
+		return err
 	}
 
-	patch, err := strconv.Atoi(parts[2])
+	// Unknown keys are likely misspelled, and would be silently ignored.
+	for _, s := range c.Unknown() {
+		fmt.Fprintf(os.Stderr, "%s\n", tui.WarningStyle.Render(fmt.Sprintf("Unknown setting %q in %s, ignored.", s.Key, s.Path)))
+	}
+
+	var setErr error
+
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			c.Set(f.Name, f.Value.String(), config.SourceFlag)
+
+			return
+		}
+
+		s, ok := c.Get(f.Name)
+		if !ok || s.Source == config.SourceDefault {
+			return
+		}
+
+		if err := f.Value.Set(s.Value); err != nil && setErr == nil {
+			setErr = errorcatalog.MustGet(
+				errorcatalog.ErrInvalidConfigKey,
+				customerror.WithField("key", f.Name),
+				customerror.WithError(err),
+			).NewInvalidError()
+		}
+	})
+
+	cfg = c
+
+	return setErr
+}
+
+// canPrompt checks if the user can be prompted: stdin, and stdout are
+// terminals, and the output isn't meant for scripts, e.g.: --dry-run, or
+// --output json.
+func canPrompt() bool {
+	return tui.IsInteractive() && !dryRun && outputFormat != outputJSON
+}
+
+// hintOutput returns where everything but the result is printed: stderr with
+// --output json, so stdout only has the JSON document.
+func hintOutput() io.Writer {
+	if outputFormat == outputJSON {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+// printResult prints the result in the output format: the message, or JSON.
+func printResult(res result) {
+	if outputFormat != outputJSON {
+		fmt.Println(res.Message)
+
+		return
+	}
+
+	out, err := json.MarshalIndent(res, "", "  ")
 	if err != nil {
-		return ""
+		cliLogger.Fatalln(err)
 	}
 
-	return fmt.Sprintf("%s%s.%s.%d", prefix, parts[0], parts[1], patch+1)
+	fmt.Println(string(out))
 }
 
-// handleTagging implements the smart tagging flow: fetches remote tags,
-// displays the latest 3, suggests next patch version, and lets user
-// accept or enter a custom tag.
-func handleTagging() {
-	tui.SpinnerStart("Fetching tags...")
+// initializeProviders creates the fallback chain: the provider, and model set
+// by the user, followed by the configured fallbacks. Providers failing to
+// initialize, e.g.: missing API key, are skipped with a warning.
+func initializeProviders() (*provider.Chain, error) {
+	retries, err := strconv.Atoi(cfg.String("retries"))
+	if err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidConfigKey,
+			customerror.WithField("key", "retries"),
+			customerror.WithError(err),
+		).NewInvalidError()
+	}
 
-	if err := git.GitFetchTags(); err != nil {
-		tui.SpinnerStop()
-		cliLogger.Warnln("Failed to fetch remote tags, proceeding with local tags")
+	backoff, err := time.ParseDuration(cfg.String("retry-backoff"))
+	if err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidConfigKey,
+			customerror.WithField("key", "retry-backoff"),
+			customerror.WithError(err),
+		).NewInvalidError()
 	}
 
-	tags, err := git.GitGetLatestTags(3)
+	links := []provider.ChainLink{}
 
-	tui.SpinnerStop()
+	var firstErr error
 
-	if err != nil || len(tags) == 0 {
-		// No existing tags — fall back to manual input.
-		fmt.Println(tui.HintStyle.Render("No existing tags found."))
+	for _, spec := range providerSpecs() {
+		// Models may contain colons, e.g.: "ollama:llama3:8b".
+		name, model, _ := strings.Cut(spec, ":")
 
-		tag := tui.MustPromptForInputTea("Enter the tag name:")
-		if tag == "" {
-			return
+		llm, err := provider.InitializeLLMProvider(name, model, openAICompatibleOptions())
+		if err != nil {
+			cliLogger.Warnln(fmt.Sprintf("Skipping provider %s: %s", spec, err))
+
+			if firstErr == nil {
+				firstErr = err
+			}
+
+			continue
 		}
 
-		if err := git.GitTag(tag); err != nil {
-			cliLogger.Fatalln(err)
+		links = append(links, provider.ChainLink{Label: name + "/" + model, LLM: llm})
+	}
+
+	if len(links) == 0 {
+		return nil, firstErr
+	}
+
+	return provider.NewChain(retries, backoff, links...), nil
+}
+
+// providerSpecs returns the provider, and model set by the user, followed by
+// the configured fallbacks, e.g.: "ollama:llama3:8b".
+func providerSpecs() []string {
+	return append([]string{llmProvider + ":" + llmModel}, cfg.List("fallback")...)
+}
+
+// diffBudget returns the number of tokens of the diff fitting in any of the
+// templates, rendered with the data, in the context window of every model of
+// the chain, and the encoding of the first one, counting them.
+// --chunk-threshold overrides the budget, if set.
+func diffBudget(data prompt.Data, templates ...*prompt.Template) (int, string, error) {
+	budget, encoding := 0, ""
+
+	for i, spec := range providerSpecs() {
+		// Models may contain colons, e.g.: "ollama:llama3:8b".
+		_, name, _ := strings.Cut(spec, ":")
+
+		m, _ := model.Lookup(name)
+
+		if i == 0 {
+			encoding = m.Encoding
+
+			if chunkThreshold > 0 {
+				return chunkThreshold, encoding, nil
+			}
 		}
 
-		if err := git.GitPushTags(); err != nil {
-			cliLogger.Fatalln(err)
+		count := textsplitter.NewCounter(m.Encoding, "")
+
+		overhead := 0
+
+		for _, tmpl := range templates {
+			p, err := tmpl.Render(data)
+			if err != nil {
+				return 0, "", err
+			}
+
+			overhead = max(overhead, count(p))
 		}
 
-		return
+		if b := m.Budget(overhead); i == 0 || b < budget {
+			budget = b
+		}
+	}
+
+	return budget, encoding, nil
+}
+
+// chunkDiff chunks the diff, if needed, so every chunk fits in the prompts,
+// along with the data, see diffBudget. The budget is returned too, as the
+// summaries of the chunks must also fit in it.
+func chunkDiff(templates *prompt.Templates, data prompt.Data, diff string) ([]string, provider.Budget, error) {
+	tokens, encoding, err := diffBudget(data, templates.Commit, templates.Summarize)
+	if err != nil {
+		return nil, provider.Budget{}, err
+	}
+
+	budget := provider.Budget{Encoding: encoding, Tokens: tokens}
+
+	chunks, err := provider.ChunkDiff(chunkStrategy, encoding, tokens, diff)
+
+	return chunks, budget, err
+}
+
+// recentExamples returns the messages of the recent commits, latest first,
+// as examples of the style of the repository, only the ones changing the
+// files of the diff with --style-examples-touched. When amending, HEAD isn't
+// an example. Older ones are left out past a share of the context window of
+// the model, as they take from the tokens left for the diff.
+func recentExamples(diff string) []string {
+	if styleExamples <= 0 {
+		return nil
+	}
+
+	paths := []string{}
+
+	if styleExamplesTouched {
+		for _, f := range gitdiff.Parse(diff) {
+			paths = append(paths, f.Path)
+		}
+	}
+
+	commits, _ := repo.RecentCommits(styleExamples+1, paths...)
+
+	if head, err := repo.ResolveCommit("HEAD"); err == nil && amend && len(commits) > 0 && commits[0].Hash == head {
+		commits = commits[1:]
+	}
+
+	m, _ := model.Lookup(llmModel)
+
+	count := textsplitter.NewCounter(m.Encoding, "")
+
+	examples, tokens := []string{}, 0
+
+	for _, c := range commits[:min(styleExamples, len(commits))] {
+		if tokens += count(c.Message); tokens > m.ContextWindow/maxExamplesShare {
+			break
+		}
+
+		examples = append(examples, c.Message)
+	}
+
+	return examples
+}
+
+// openAICompatibleOptions returns the configuration of the OpenAI-compatible
+// provider.
+func openAICompatibleOptions() provider.OpenAICompatibleOptions {
+	return provider.OpenAICompatibleOptions{
+		BaseURL:   cfg.String("openai-compatible.base-url"),
+		APIKeyEnv: cfg.String("openai-compatible.api-key-env"),
+		Headers:   cfg.Map("openai-compatible.headers"),
+	}
+}
+
+// pickChanges lets the user pick the files, and hunks to be staged, and
+// stages them.
+func pickChanges() error {
+	diff, err := repo.UnstagedDiff()
+	if err != nil {
+		return err
+	}
+
+	untracked, err := repo.UntrackedFiles()
+	if err != nil {
+		return err
+	}
+
+	files := gitdiff.Parse(diff)
+
+	if len(files) == 0 && len(untracked) == 0 {
+		shared.NothingToDo()
+	}
+
+	patch, selected := tui.MustPickChanges(files, untracked)
+
+	if len(patch) == 0 && len(selected) == 0 {
+		shared.NothingToDo()
+	}
+
+	if len(patch) > 0 {
+		var b strings.Builder
+
+		for _, f := range patch {
+			b.WriteString(f.String())
+		}
+
+		if err := repo.ApplyCached(b.String()); err != nil {
+			return err
+		}
+	}
+
+	return repo.StageFiles(selected)
+}
+
+// issueRules returns the rules adding issue references of the branch name to
+// generated messages.
+func issueRules() (*issue.Rules, error) {
+	return issue.New(
+		cfg.List("issue-patterns"),
+		cfg.String("issue-placement"),
+		cfg.String("issue-footer"),
+		cfg.String("issue-closing-keyword"),
+	)
+}
+
+// commitTrailers returns the trailers added to commit messages: the
+// configured, and custom ones, the given, and picked co-authors, and the
+// sign-off, if enabled.
+func commitTrailers() ([]string, error) {
+	picked := []string{}
+
+	if len(coAuthors) > 0 || (pickCoAuthors && canPrompt()) {
+		// Without identity, the user is just another candidate.
+		user, _ := repo.Identity()
+
+		authors, err := repo.Authors()
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := trailer.Candidates(user, cfg.List("co-authors"), authors)
+
+		for _, query := range coAuthors {
+			coAuthor, err := trailer.Resolve(query, candidates)
+			if err != nil {
+				return nil, err
+			}
+
+			picked = append(picked, coAuthor)
+		}
+
+		if pickCoAuthors && canPrompt() && len(candidates) > 0 {
+			picked = append(picked, tui.MustPickMany("Who are the co-authors?", candidates)...)
+		}
+	}
+
+	user := ""
+
+	if cfg.String("signoff") == "true" {
+		identity, err := repo.Identity()
+		if err != nil {
+			return nil, err
+		}
+
+		user = identity
+	}
+
+	return trailer.Build(append(cfg.List("trailers"), customTrailers...), picked, user)
+}
+
+// commitSigning returns how commits are signed, on top of the git
+// configuration.
+func commitSigning() (git.Signing, error) {
+	return git.NewSigning(cfg.String("sign") == "true", cfg.String("signing-key"), cfg.String("signing-format"))
+}
+
+// runGit runs fn, running git, pausing the spinner if git may need the
+// terminal, e.g.: to prompt for the passphrase of the signing key.
+func runGit(needsTerminal bool, fn func() error) error {
+	if needsTerminal {
+		return tui.SpinnerPause(fn)
+	}
+
+	return fn()
+}
+
+// pathFilter returns the filter of the files sent to the LLM.
+func pathFilter() *pathfilter.Filter {
+	return pathfilter.New(
+		cfg.List("include"),
+		cfg.List("exclude"),
+		cfg.String("default-excludes") != "false",
+	)
+}
+
+// scanSecrets scans the diff for secrets, before any part of it is sent to the
+// LLM. Depending on the mode, found secrets abort the process, are redacted, or
+// the user is asked what to do, e.g.: send them as is, if they're false
+// positives. Either way, the user is warned they're about to be committed. It
+// returns the function to apply to the diff, or to any part of it, before it's
+// sent.
+func scanSecrets(diff string) (func(string) string, error) {
+	keep := func(diff string) string { return diff }
+
+	if secretsMode == secrets.ModeOff {
+		return keep, nil
+	}
+
+	if !secrets.IsMode(secretsMode) {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidSecretsMode,
+			customerror.WithField("mode", secretsMode),
+		).NewInvalidError()
+	}
+
+	scanner, err := secrets.New(cfg.Path("secrets-rules"))
+	if err != nil {
+		return nil, err
+	}
+
+	findings := scanner.Scan(diff)
+	if len(findings) == 0 {
+		return keep, nil
+	}
+
+	// The report goes to stderr, so it doesn't mix with the result, e.g.: with
+	// --output json.
+	fmt.Fprintf(
+		os.Stderr,
+		"%s\n\n%s\n\n",
+		tui.ErrorStyle.Render("Secrets found in the staged changes:"),
+		tui.ErrorStyle.Render(secrets.Report(findings)),
+	)
+
+	// Secrets of removed lines are sent, but not committed.
+	if slices.ContainsFunc(findings, func(f secrets.Finding) bool { return !f.Removed }) {
+		fmt.Fprintf(os.Stderr, "%s\n\n", tui.WarningStyle.Render("They're about to be committed. Unstage them with: git restore --staged <file>"))
+	}
+
+	mode := secretsMode
+
+	// There's no one to ask in auto-accept mode, or from scripts.
+	if mode == secrets.ModeAsk && (autoAccept || !canPrompt()) {
+		mode = secrets.ModeBlock
+	}
+
+	if mode == secrets.ModeAsk {
+		switch tui.MustPromptWithChoices("What would you like to do?", []string{
+			"Redact secrets, and continue",
+			"Send as is, they're false positives",
+			"Exit",
+		}) {
+		case "Redact secrets, and continue":
+			mode = secrets.ModeRedact
+		case "Send as is, they're false positives":
+			return keep, nil
+		default:
+			shared.NothingToDo()
+		}
+	}
+
+	if mode == secrets.ModeRedact {
+		return func(diff string) string { return secrets.Redact(diff, findings) }, nil
+	}
+
+	return nil, errorcatalog.MustGet(
+		errorcatalog.ErrSecretsFound,
+		customerror.WithField("findings", len(findings)),
+	).New()
+}
+
+// suggestVersion suggests the next version after the latest version,
+// inferred from the conventional commits since then. It prints the commits
+// which drove the decision.
+func suggestVersion(latestTag string, latest semver.Version, commits []semver.Commit) (string, error) {
+	bump, reasons := semver.Infer(commits)
+
+	next, err := latest.Next(bump, preRelease, buildMetadata)
+	if err != nil {
+		return "", err
+	}
+
+	if bump == semver.None {
+		fmt.Fprintf(hintOutput(), "%s\n\n", tui.HintStyle.Render(fmt.Sprintf(
+			"No features, fixes, or breaking changes since %s, suggesting a patch.", latestTag)))
+
+		return next.String(), nil
 	}
 
-	// Display latest tags.
-	fmt.Printf("\n%s\n", tui.QuestionStyle.Render("Latest tags:"))
+	fmt.Fprintf(hintOutput(), "%s\n", tui.QuestionStyle.Render(fmt.Sprintf("Suggesting a %s bump since %s, because of:", bump, latestTag)))
 
-	for _, t := range tags {
-		fmt.Printf("  %s\n", t)
+	for _, r := range reasons {
+		fmt.Fprintf(hintOutput(), "  %s %s\n", tui.HintStyle.Render(r.Hash[:min(7, len(r.Hash))]), r.Header)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(hintOutput())
 
-	// Suggest next patch version based on the latest tag.
-	suggested := bumpPatch(tags[0])
+	return next.String(), nil
+}
+
+// generateReleaseNotes generates the release notes of the tag, from the
+// commits since the previous one: by the LLM, unless the "release-notes"
+// setting is "commits", then, or if the LLM fails, from the template listing
+// the commits by type.
+func generateReleaseNotes(
+	providerInUse provider.LLM,
+	tag string,
+	previousTag string,
+	commits []semver.Commit,
+) (string, error) {
+	fallback := release.Notes(tag, commits)
+
+	if cfg.String("release-notes") == "commits" || len(commits) == 0 {
+		return fallback, nil
+	}
+
+	tmpl, err := prompt.Load(prompt.ReleaseNotesName, cfg.Path("release-notes-template"))
+	if err != nil {
+		return "", err
+	}
 
-	choices := []string{}
+	messages := make([]string, 0, len(commits))
 
-	if suggested != "" {
-		choices = append(choices, suggested+" (suggested)")
+	for _, c := range commits {
+		messages = append(messages, c.Message)
 	}
 
-	choices = append(choices, "Enter custom tag")
+	p, err := tmpl.Render(prompt.Data{Tag: tag, PreviousTag: previousTag, Commits: messages})
+	if err != nil {
+		return "", err
+	}
+
+	tui.SpinnerStart("Generating release notes...")
+
+	notes, err := provider.CallLLM(context.Background(), providerInUse, llmAPICallTimeout, p)
+
+	tui.SpinnerStop()
 
-	choice := tui.MustPromptWithChoices("Which tag would you like to use?", choices)
+	if err != nil {
+		cliLogger.Warnln(fmt.Sprintf("Failed to generate release notes, listing the commits instead: %s", err))
+
+		return fallback, nil
+	}
+
+	return strings.TrimSpace(notes) + "\n", nil
+}
+
+// handleTagging implements the smart tagging flow: fetches remote tags,
+// displays the latest 3, suggests the next version, inferred from the
+// commits since the latest one, and lets user accept or enter a custom tag.
+// The tag is annotated with release notes, optionally signed, and only the
+// new tag is pushed.
+func handleTagging(providerInUse provider.LLM) {
+	tui.SpinnerStart("Fetching tags...")
+
+	if err := repo.FetchTags(); err != nil {
+		tui.SpinnerStop()
+		cliLogger.Warnln("Failed to fetch remote tags, proceeding with local tags")
+	}
+
+	tags, err := repo.LatestTags(0)
+
+	tui.SpinnerStop()
+
+	if err != nil {
+		cliLogger.Warnln(fmt.Sprintf("Failed to get tags: %s", err))
+	}
+
+	// Notes cover the commits since the latest version, or all of them.
+	latestTag, latest, found := semver.Latest(tags)
+
+	gitCommits, err := repo.CommitsSince(latestTag)
+	if err != nil {
+		cliLogger.Fatalln(err)
+	}
+
+	commits := releaseCommits(gitCommits)
 
 	var tag string
 
-	switch {
-	case strings.HasSuffix(choice, "(suggested)"):
-		tag = suggested
-	case choice == "Enter custom tag":
+	if len(tags) == 0 {
+		// No existing tags — fall back to manual input.
+		fmt.Fprintln(hintOutput(), tui.HintStyle.Render("No existing tags found."))
+
 		tag = tui.MustPromptForInputTea("Enter the tag name:")
+	} else {
+		// Display latest tags.
+		fmt.Fprintf(hintOutput(), "\n%s\n", tui.QuestionStyle.Render("Latest tags:"))
+
+		for _, t := range tags[:min(3, len(tags))] {
+			fmt.Fprintf(hintOutput(), "  %s\n", t)
+		}
+
+		fmt.Fprintln(hintOutput())
+
+		// Suggest the next version based on the commits since the latest tag.
+		choices := []string{}
+
+		suggested := ""
+
+		if found {
+			suggested, err = suggestVersion(latestTag, latest, commits)
+			if err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			choices = append(choices, suggested+" (suggested)")
+		}
+
+		choices = append(choices, "Enter custom tag")
+
+		choice := tui.MustPromptWithChoices("Which tag would you like to use?", choices)
+
+		switch {
+		case strings.HasSuffix(choice, "(suggested)"):
+			tag = suggested
+		case choice == "Enter custom tag":
+			tag = tui.MustPromptForInputTea("Enter the tag name:")
+		}
 	}
 
 	if tag == "" {
 		return
 	}
 
-	if err := git.GitTag(tag); err != nil {
+	notes, err := generateReleaseNotes(providerInUse, tag, latestTag, commits)
+	if err != nil {
+		cliLogger.Fatalln(err)
+	}
+
+	fmt.Fprintf(hintOutput(), "%s\n\n%s\n", tui.QuestionStyle.Render("Release Notes:"), notes)
+
+	switch tui.MustPromptWithChoices("What would you like to do?", []string{
+		"Approve release notes",
+		"Write release notes yourself",
+		"Exit",
+	}) {
+	case "Write release notes yourself":
+		if notes, err = tui.CommitMessageTextArea(); err != nil {
+			cliLogger.Fatalln(err)
+		}
+	case "Exit":
+		shared.NothingToDo()
+	}
+
+	signing, err := git.NewSigning(signTag, cfg.String("signing-key"), cfg.String("signing-format"))
+	if err != nil {
+		cliLogger.Fatalln(err)
+	}
+
+	tui.SpinnerStart("Tagging...")
+
+	if err := runGit(signing.SignsTags(), func() error {
+		return repo.Tag(tag, notes, signing)
+	}); err != nil {
 		cliLogger.Fatalln(err)
 	}
 
-	if err := git.GitPushTags(); err != nil {
+	if err := repo.PushTag(tag); err != nil {
 		cliLogger.Fatalln(err)
 	}
+
+	tui.SpinnerStop()
 }
 
 // Execute is called by main to run the root command and setup the CLI.
@@ -305,30 +1159,87 @@ func Execute() error {
 	return rootCmd.Execute()
 }
 
-// init is used to initialize the command and attach flags to it.
-func init() {
-	// Configure flags for chunk threshold, API call timeout, model, and provider.
-	rootCmd.Flags().BoolVarP(&autoAccept, "auto-accept", "a", false,
+// addGenerationFlags attaches the flags configuring how commit messages are
+// generated to the command.
+func addGenerationFlags(cmd *cobra.Command) {
+	// Configure flags for chunk strategy, chunk threshold, API call timeout, model, and provider.
+	cmd.Flags().BoolVarP(&autoAccept, "auto-accept", "a", false,
 		"Automatically add all files, approve the generated commit message, and push (skip tagging)")
-	rootCmd.Flags().IntVarP(&chunkThreshold, "chunk-threshold", "c", 128000,
-		"Chunk threshold in characters")
-	rootCmd.Flags().DurationVarP(&llmAPICallTimeout,
+	cmd.Flags().StringVar(&chunkStrategy, "chunk-strategy",
+		textsplitter.StrategyDiff, fmt.Sprintf("Diff chunking strategy, allowed: %s",
+			strings.Join(textsplitter.Strategies, ", ")))
+	cmd.Flags().IntVarP(&chunkThreshold, "chunk-threshold", "c", 0,
+		"Maximum tokens of a diff chunk, by default what fits in the context window of the model")
+	cmd.Flags().DurationVarP(&llmAPICallTimeout,
 		"llm-api-call-timeout", "t", 30*time.Second, "LLM API call timeout")
-	rootCmd.Flags().StringVarP(&llmModel, "model", "m",
+	cmd.Flags().StringVarP(&llmModel, "model", "m",
 		"gpt-4o", "Model to be used by the provider for generating commit messages")
 
+	cmd.Flags().StringVar(&secretsMode, "secrets", secrets.ModeAsk,
+		fmt.Sprintf("What to do when staged changes contain secrets, allowed: %s",
+			strings.Join(secrets.Modes, ", ")))
+
+	cmd.Flags().IntVar(&styleExamples, "style-examples", 5,
+		"Number of recent commits shown to the LLM as examples of the style of the repository, 0 disables them")
+	cmd.Flags().BoolVar(&styleExamplesTouched, "style-examples-touched", false,
+		"Only use recent commits changing the same files as examples")
+
 	// Construct the message detailing which providers are allowed.
 	llmProviderMsg := fmt.Sprintf(
 		"LLM providers, allowed: %s",
-		strings.Join([]string{
-			openai.Name,
-			anthropic.Name,
-			ollama.Name,
-			huggingface.Name,
-		}, ", "),
+		strings.Join(provider.Names, ", "),
 	)
 
 	// Assign the provider flag, enabling selection of the desired LLM provider.
-	rootCmd.Flags().StringVarP(&llmProvider, "provider", "p",
+	cmd.Flags().StringVarP(&llmProvider, "provider", "p",
 		openai.Name, llmProviderMsg)
 }
+
+// addTrailerFlags attaches the flags adding trailers to commit messages to the
+// command.
+func addTrailerFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&signoff, "signoff", "s", false,
+		"Add a Signed-off-by trailer, certifying the Developer Certificate of Origin")
+	cmd.Flags().StringArrayVar(&coAuthors, "co-author", nil,
+		`Add a Co-authored-by trailer, as "Name <email>", or a name, or email of a known co-author (repeatable)`)
+	cmd.Flags().BoolVar(&pickCoAuthors, "pick-co-authors", false,
+		"Pick co-authors from the configured ones, and the authors of the repository")
+	cmd.Flags().StringArrayVar(&customTrailers, "trailer", nil,
+		`Add a trailer, e.g.: "Reviewed-by: Jane <jane@example.com>" (repeatable)`)
+}
+
+// addSigningFlags attaches the flags signing commits to the command.
+func addSigningFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&signCommits, "sign", "S", false,
+		"Sign commits with the GPG, or SSH key of git, even if commit.gpgSign is off")
+	cmd.Flags().StringVar(&signingKey, "signing-key", "",
+		"Key signing commits, and tags, instead of user.signingKey, e.g.: a GPG key ID, or the path of an SSH key")
+	cmd.Flags().StringVar(&signingFormat, "signing-format", "",
+		fmt.Sprintf("Format of the signatures, instead of gpg.format, allowed: %s",
+			strings.Join(git.SigningFormats, ", ")))
+}
+
+// init is used to initialize the command and attach flags to it.
+func init() {
+	addGenerationFlags(rootCmd)
+	addTrailerFlags(rootCmd)
+	addSigningFlags(rootCmd)
+
+	rootCmd.Flags().StringVar(&preRelease, "pre-release", "",
+		"Pre-release identifier of the suggested tag, e.g.: rc suggests v1.3.0-rc.1")
+	rootCmd.Flags().StringVar(&buildMetadata, "build-metadata", "",
+		"Build metadata of the suggested tag, e.g.: build.5 suggests v1.3.0+build.5")
+	rootCmd.Flags().BoolVar(&signTag, "sign-tag", false,
+		"Sign tags with the configured GPG, or SSH key (git tag -s)")
+	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false,
+		"Generate, and print the message, without staging, committing, or pushing anything")
+	rootCmd.Flags().StringVarP(&outputFormat, "output", "o", outputText,
+		fmt.Sprintf("Format of the result, allowed: %s. With json, nothing is prompted: "+
+			"the message is committed without review, unless --dry-run", strings.Join(outputFormats, ", ")))
+	rootCmd.Flags().StringVar(&messageFile, "message-file", "",
+		"Write the message to the file, e.g.: for editors")
+	rootCmd.Flags().BoolVar(&amend, "amend", false,
+		"Regenerate the message of the last, unpushed commit, and amend it with the staged changes")
+	rootCmd.Flags().BoolVar(&force, "force", false,
+		"Amend the last commit even if it's on remote branches, when the branch has no upstream branch")
+}
@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/git"
+	"github.com/thalesfsp/committer/internal/prompt"
+	"github.com/thalesfsp/committer/internal/provider"
+	"github.com/thalesfsp/committer/internal/shared"
+	"github.com/thalesfsp/committer/internal/split"
+	"github.com/thalesfsp/committer/internal/textsplitter"
+	"github.com/thalesfsp/committer/internal/tui"
+	"github.com/thalesfsp/customerror"
+)
+
+// splitCmd splits the staged changes into several commits.
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Splits the staged changes into several atomic commits",
+	Long: `Asks the LLM to group the staged files into logically separate
+commits, and shows the proposed plan for editing: move files between
+commits, merge, or rename them. A commit message is then generated for
+every commit, and they're committed in order. If anything fails, the
+commits are undone, and the original index is restored.
+
+Files are never split: all the staged changes of a file go in the same
+commit. To commit unrelated changes of a file separately, run committer
+with nothing staged, pick some of its hunks, commit them, and then split
+the rest.`,
+	Args: cobra.NoArgs,
+	Run: func(_ *cobra.Command, _ []string) {
+		// Exit if the current directory is not a Git repository.
+		if !repo.IsRepo() {
+			cliLogger.Fatalln(errorcatalog.MustGet(
+				errorcatalog.ErrNotGitRepo).New())
+		}
+
+		// Only staged changes are split.
+		if !repo.HasStagedChanges() {
+			shared.NothingToDo()
+		}
+
+		providerInUse, err := initializeProviders()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		templates, err := prompt.LoadTemplates(
+			cfg.Path("commit-template"),
+			cfg.Path("summarize-template"),
+		)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		splitTemplate, err := prompt.Load(prompt.SplitName, cfg.Path("split-template"))
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		rules, err := enabledLintRules()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Issue references of the branch, added to generated messages.
+		issues, err := issueRules()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		// Trailers, e.g.: co-authors, apply to every commit.
+		trailers, err := commitTrailers()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		signing, err := commitSigning()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		commitTemplate, err := repo.CommitTemplate()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		files, err := repo.StagedFiles()
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		if len(files) < 2 {
+			fmt.Println(tui.HintStyle.Render("A single file is staged, there's nothing to split."))
+
+			shared.NothingToDo()
+		}
+
+		filter := pathFilter()
+
+		tui.SpinnerStart("Getting diff...")
+
+		diff, excluded, err := repo.Diff(filter)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		tui.SpinnerStop()
+
+		redact, err := scanSecrets(diff)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		stats, err := repo.Stats(excluded)
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		branch, _ := repo.CurrentBranch()
+		recentLog, _ := repo.RecentLog(10)
+
+		tui.SpinnerStart("Planning commits...")
+
+		plan, err := planCommits(providerInUse, splitTemplate, files, stats, redact(diff))
+
+		tui.SpinnerStop()
+
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		if !autoAccept {
+			plan = tui.MustEditPlan(plan)
+		}
+
+		// Messages are generated before anything is committed, so exiting
+		// while reviewing them leaves the index untouched.
+		messages := make([]string, 0, len(plan.Groups))
+
+		for i, group := range plan.Groups {
+			fmt.Printf("%s\n\n", tui.QuestionStyle.Render(
+				fmt.Sprintf("Commit %d of %d: %s", i+1, len(plan.Groups), group.Title),
+			))
+
+			diff, excluded, err := repo.Diff(filter, group.Files...)
+			if err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			stats, err := repo.Stats(excluded, group.Files...)
+			if err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			data := prompt.Data{
+				Stats:          stats,
+				Branch:         branch,
+				RecentLog:      recentLog,
+				Examples:       recentExamples(diff),
+				CommitTemplate: commitTemplate,
+			}
+
+			chunks, budget, err := chunkDiff(templates, data, redact(diff))
+			if err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			message, err := provider.GenerateCommitMessageLoop(
+				providerInUse,
+				llmAPICallTimeout,
+				templates,
+				data,
+				chunks,
+				budget,
+				exampleLintRules(rules, data.Examples),
+				issues,
+				autoAccept)
+			if err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			if message == "" {
+				cliLogger.Fatalln(errorcatalog.MustGet(
+					errorcatalog.ErrEmptyCommitMessage).NewMissingError())
+			}
+
+			message, err = repo.AddTrailers(message, trailers)
+			if err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			messages = append(messages, message)
+		}
+
+		tui.SpinnerStart("Committing changes...")
+
+		err = runGit(signing.SignsCommits(), func() error {
+			return commitPlan(plan, messages, signing)
+		})
+
+		tui.SpinnerStop()
+
+		if err != nil {
+			cliLogger.Fatalln(err)
+		}
+
+		fmt.Printf("%s\n\n", tui.HintStyle.Render(fmt.Sprintf("Created %d commits.", len(messages))))
+
+		if autoAccept || tui.MustPromptYesNoTea("Would you like to push the commits?", true) {
+			tui.SpinnerStart("Pushing changes...")
+
+			if err := repo.Push(); err != nil {
+				cliLogger.Fatalln(err)
+			}
+
+			tui.SpinnerStop()
+		}
+	},
+}
+
+// planCommits asks the LLM to group the staged files into commits. The diff
+// is left out of the prompt if it doesn't fit in the context window, the
+// grouping then relies on the names of the files, and the stats.
+func planCommits(
+	providerInUse provider.LLM,
+	tmpl *prompt.Template,
+	files []string,
+	stats string,
+	diff string,
+) (*split.Plan, error) {
+	data := prompt.Data{Files: files, Stats: stats}
+
+	budget, encoding, err := diffBudget(data, tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	if textsplitter.NewCounter(encoding, "")(diff) <= budget {
+		data.Diff = diff
+	}
+
+	p, err := tmpl.Render(data)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := provider.CallLLM(context.Background(), providerInUse, llmAPICallTimeout, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return split.ParsePlan(response, files)
+}
+
+// commitPlan commits every group of the plan, in order, with its message. The
+// staged version of the files is committed, even if they were changed since.
+// If anything fails, the commits are undone, and the original index is
+// restored.
+func commitPlan(plan *split.Plan, messages []string, signing git.Signing) (err error) {
+	snapshot, err := repo.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		if restoreErr := repo.RestoreSnapshot(snapshot); restoreErr != nil {
+			err = errors.Join(err, restoreErr)
+		}
+
+		err = errorcatalog.MustGet(
+			errorcatalog.ErrFailedToSplitCommits,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}()
+
+	if err := repo.UnstageAll(); err != nil {
+		return err
+	}
+
+	for i, group := range plan.Groups {
+		if err := repo.StageFromTree(snapshot.Tree, group.Files); err != nil {
+			return err
+		}
+
+		if err := repo.Commit(messages[i], signing); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	addGenerationFlags(splitCmd)
+	addTrailerFlags(splitCmd)
+	addSigningFlags(splitCmd)
+
+	rootCmd.AddCommand(splitCmd)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/thalesfsp/committer/internal/git"
+	"github.com/thalesfsp/committer/internal/split"
+)
+
+// TestCommitPlan verifies every group is committed, in order, with the staged
+// version of its files, and failures undo the commits, and restore the index.
+func TestCommitPlan(t *testing.T) {
+	fake := git.NewFake()
+
+	previous := repo
+	repo = fake
+
+	t.Cleanup(func() { repo = previous })
+
+	fake.Worktree["a.txt"] = "a\n"
+	fake.Worktree["b.txt"] = "b\n"
+	fake.Worktree["c.txt"] = "c\n"
+
+	if err := fake.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Changed since staged, the staged version is committed.
+	fake.Worktree["a.txt"] = "changed\n"
+
+	plan := &split.Plan{Groups: []split.Group{
+		{Title: "Add a", Files: []string{"a.txt"}},
+		{Title: "Add b, and c", Files: []string{"b.txt", "c.txt"}},
+	}}
+
+	if err := commitPlan(plan, []string{"feat: add a", "feat: add b, and c"}, git.Signing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.Commits) != 2 || fake.HasStagedChanges() {
+		t.Fatalf("expected 2 commits, and nothing staged, got %d commits", len(fake.Commits))
+	}
+
+	if files := slices.Sorted(maps.Keys(fake.Commits[0].Files)); fake.Commits[0].Message != "feat: add a" ||
+		strings.Join(files, ",") != "a.txt" || fake.Commits[0].Files["a.txt"] != "a\n" {
+		t.Errorf("first commit = %q, %v", fake.Commits[0].Message, fake.Commits[0].Files)
+	}
+
+	if len(fake.Commits[1].Files) != 3 || fake.Commits[1].Message != "feat: add b, and c" {
+		t.Errorf("second commit = %q, %v", fake.Commits[1].Message, fake.Commits[1].Files)
+	}
+
+	fake.Worktree["d.txt"] = "d\n"
+
+	if err := fake.StageFiles([]string{"d.txt"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The second group has nothing to commit, so it fails.
+	plan = &split.Plan{Groups: []split.Group{
+		{Title: "Add d", Files: []string{"d.txt"}},
+		{Title: "Add e", Files: []string{"e.txt"}},
+	}}
+
+	if err := commitPlan(plan, []string{"feat: add d", "feat: add e"}, git.Signing{}); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	if len(fake.Commits) != 2 || fake.Index["d.txt"] != "d\n" {
+		t.Errorf("expected the commits undone, and d.txt staged, got %d commits, %v", len(fake.Commits), fake.Index)
+	}
+}
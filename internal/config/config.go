@@ -0,0 +1,442 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/shared"
+	"github.com/thalesfsp/customerror"
+	"gopkg.in/yaml.v3"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Sources of a setting, from the lowest to the highest precedence.
+const (
+	SourceDefault = "default"
+	SourceUser    = "user"
+	SourceRepo    = "repo"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Names of the configuration files.
+const (
+	// RepoFileName is the name of the repository configuration file, at the
+	// root of the repository.
+	RepoFileName = ".committer.yaml"
+
+	// UserFileName is the name of the user configuration file, under the
+	// committer directory of the user configuration directory.
+	UserFileName = "config.yaml"
+
+	// EnvPrefix is the prefix of the environment variables overriding
+	// settings, e.g.: COMMITTER_CHUNK_THRESHOLD.
+	EnvPrefix = "COMMITTER_"
+)
+
+// Key describes a known setting.
+type Key struct {
+	// Name of the setting, e.g.: "chunk-threshold". Same as the flag, if any.
+	Name string
+
+	// Default value of the setting.
+	Default string
+
+	// Description of the setting.
+	Description string
+}
+
+// Setting is the effective value of a setting, and where it came from.
+type Setting struct {
+	// Key is the name of the setting.
+	Key string `json:"key"`
+
+	// Value of the setting. Lists are comma separated.
+	Value string `json:"value"`
+
+	// Source is where the value came from, e.g.: SourceRepo.
+	Source string `json:"source"`
+
+	// Path of the file the value came from, if any.
+	Path string `json:"path,omitempty"`
+}
+
+// Config is the effective configuration.
+type Config struct {
+	settings map[string]Setting
+}
+
+// Keys lists the known settings.
+var Keys = []Key{
+	{Name: "auto-accept", Default: "false", Description: "Automatically add all files, approve the generated commit message, and push"},
+	{Name: "build-metadata", Default: "", Description: "Build metadata of the suggested tag, e.g.: build.5"},
+	{Name: "changelog-prompt-template", Default: "", Description: "Path of the template of the prompt polishing changelog entries"},
+	{Name: "changelog-template", Default: "", Description: "Path of the template of the changelog, defaults to Keep a Changelog"},
+	{Name: "chunk-strategy", Default: "diff", Description: "Diff chunking strategy"},
+	{Name: "chunk-threshold", Default: "0", Description: "Maximum tokens of a diff chunk, 0 fits the context window of the model"},
+	{Name: "co-authors", Default: "", Description: "Known co-authors, as \"Name <email>\", picked with --pick-co-authors, or by name with --co-author"},
+	{Name: "commit-template", Default: "", Description: "Path of the template of the commit message prompt"},
+	{Name: "default-excludes", Default: "true", Description: "Exclude lock files, vendored, and generated code from the diff sent to the LLM"},
+	{Name: "exclude", Default: "", Description: "Globs of files excluded from the diff sent to the LLM, e.g.: docs/**,*.svg"},
+	{Name: "fallback", Default: "", Description: "Ordered fallback providers, as provider:model, e.g.: openai:gpt-4o,ollama:llama3"},
+	{Name: "include", Default: "", Description: "Globs of files sent to the LLM even if excluded, or generated"},
+	{Name: "issue-closing-keyword", Default: "", Description: "Keyword closing the GitHub issue of the branch, e.g.: Closes, empty to only reference it"},
+	{Name: "issue-footer", Default: "Refs", Description: "Token of the footer listing the issue references, e.g.: Refs: PAY-1234"},
+	{Name: "issue-patterns", Default: "", Description: "Regexes extracting issue references from the branch name, defaults to Jira keys, and GitHub issue numbers"},
+	{Name: "issue-placement", Default: "off", Description: "Where issue references of the branch name go: footer, prefix, scope, or off"},
+	{Name: "lint", Default: "true", Description: "Lint generated commit messages, and automatically repair violations"},
+	{Name: "lint-config", Default: "", Description: "Path of the commitlint configuration file, defaults to .commitlintrc.* in the repository"},
+	{Name: "llm-api-call-timeout", Default: "30s", Description: "LLM API call timeout"},
+	{Name: "model", Default: "gpt-4o", Description: "Model to be used by the provider for generating commit messages"},
+	{Name: "openai-compatible.api-key-env", Default: "", Description: "Name of the env var holding the API key of the OpenAI-compatible provider"},
+	{Name: "openai-compatible.base-url", Default: "", Description: "Base URL of the OpenAI-compatible provider, e.g.: http://localhost:1234/v1"},
+	{Name: "openai-compatible.headers", Default: "", Description: "Extra headers sent to the OpenAI-compatible provider, values are expanded with env vars"},
+	{Name: "pr-base", Default: "", Description: "Branch pull requests are merged into, defaults to the push remote's default branch"},
+	{Name: "pr-prompt-template", Default: "", Description: "Path of the template of the pull request prompt"},
+	{Name: "pr-template", Default: "", Description: "Path of the pull request template, defaults to .github/pull_request_template.md"},
+	{Name: "pre-release", Default: "", Description: "Pre-release identifier of the suggested tag, e.g.: rc"},
+	{Name: "provider", Default: "openai", Description: "LLM provider"},
+	{Name: "release-notes", Default: "llm", Description: "How release notes of tags are written: llm, or commits (listed by type)"},
+	{Name: "release-notes-template", Default: "", Description: "Path of the template of the release notes prompt"},
+	{Name: "retries", Default: "2", Description: "Retries per provider on transient errors, e.g.: timeouts, 429, or 5xx"},
+	{Name: "retry-backoff", Default: "1s", Description: "Wait before the first retry, doubled on each retry"},
+	{Name: "secrets", Default: "ask", Description: "What to do when staged changes contain secrets: ask, block, redact, or off"},
+	{Name: "secrets-rules", Default: "", Description: "Path of the file with extra secret scanning rules, and allowlist"},
+	{Name: "sign", Default: "false", Description: "Sign commits with the GPG, or SSH key of git, even if commit.gpgSign is off"},
+	{Name: "sign-tag", Default: "false", Description: "Sign tags with the configured GPG, or SSH key"},
+	{Name: "signing-format", Default: "", Description: "Format of the signatures, instead of gpg.format: openpgp, ssh, or x509"},
+	{Name: "signing-key", Default: "", Description: "Key signing commits, and tags, instead of user.signingKey, e.g.: a GPG key ID, or the path of an SSH key"},
+	{Name: "signoff", Default: "false", Description: "Add a Signed-off-by trailer, certifying the Developer Certificate of Origin"},
+	{Name: "split-template", Default: "", Description: "Path of the template of the prompt grouping staged files into commits"},
+	{Name: "style-examples", Default: "5", Description: "Number of recent commits shown to the LLM as examples of the style of the repository, 0 disables them"},
+	{Name: "style-examples-touched", Default: "false", Description: "Only use recent commits changing the same files as examples"},
+	{Name: "summarize-template", Default: "", Description: "Path of the template of the chunk summary prompt"},
+	{Name: "trailers", Default: "", Description: "Trailers added to every commit message, e.g.: Reviewed-by: Jane <jane@example.com>"},
+}
+
+//////
+// Helpers.
+//////
+
+// flatten flattens nested YAML maps into dotted keys. Lists are joined with
+// commas.
+func flatten(prefix string, in map[string]any, out map[string]string) {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+
+		switch value := v.(type) {
+		case map[string]any:
+			flatten(key, value, out)
+		case []any:
+			items := make([]string, 0, len(value))
+
+			for _, item := range value {
+				items = append(items, fmt.Sprint(item))
+			}
+
+			out[key] = strings.Join(items, ",")
+		case nil:
+			out[key] = ""
+		default:
+			out[key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// readFile reads a configuration file into flattened settings. A missing file
+// has no settings.
+func readFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrFailedToLoadConfig,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}
+
+	raw := map[string]any{}
+
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrFailedToLoadConfig,
+			customerror.WithError(fmt.Errorf("%s: %w", path, err)),
+		).NewFailedToError()
+	}
+
+	values := map[string]string{}
+
+	flatten("", raw, values)
+
+	return values, nil
+}
+
+// EnvName returns the environment variable overriding the key, e.g.:
+// "chunk-threshold" -> "COMMITTER_CHUNK_THRESHOLD".
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(key))
+}
+
+//////
+// Exported methods.
+//////
+
+// Get returns the effective setting of the key.
+func (c *Config) Get(key string) (Setting, bool) {
+	s, ok := c.settings[key]
+
+	return s, ok
+}
+
+// String returns the effective value of the key, or "" if unset.
+func (c *Config) String(key string) string {
+	return c.settings[key].Value
+}
+
+// List returns the effective value of the key, split by commas. Empty items
+// are dropped.
+func (c *Config) List(key string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(c.String(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// Path returns the effective value of the key as a path. Relative paths set in
+// a configuration file are relative to the directory of the file.
+func (c *Config) Path(key string) string {
+	s := c.settings[key]
+
+	if s.Value == "" || filepath.IsAbs(s.Value) || s.Path == "" {
+		return s.Value
+	}
+
+	return filepath.Join(filepath.Dir(s.Path), s.Value)
+}
+
+// Map returns the settings nested under the key, e.g.: "headers.x-team" is
+// returned as "x-team" for the "headers" key.
+func (c *Config) Map(key string) map[string]string {
+	values := map[string]string{}
+
+	for k, s := range c.settings {
+		if name, found := strings.CutPrefix(k, key+"."); found {
+			values[name] = s.Value
+		}
+	}
+
+	return values
+}
+
+// Set overrides the key, e.g.: with a flag value.
+func (c *Config) Set(key, value, source string) {
+	c.settings[key] = Setting{Key: key, Value: value, Source: source}
+}
+
+// Settings returns all effective settings, sorted by key.
+func (c *Config) Settings() []Setting {
+	settings := make([]Setting, 0, len(c.settings))
+
+	for _, s := range c.settings {
+		settings = append(settings, s)
+	}
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+
+	return settings
+}
+
+// Unknown returns the settings of the configuration files which aren't known,
+// e.g.: misspelled keys, sorted by key.
+func (c *Config) Unknown() []Setting {
+	settings := []Setting{}
+
+	for _, s := range c.Settings() {
+		if s.Path != "" && !IsKnown(s.Key) {
+			settings = append(settings, s)
+		}
+	}
+
+	return settings
+}
+
+//////
+// Exported functionalities.
+//////
+
+// IsKnown checks if the key is a known setting, or belongs to a known group,
+// e.g.: "openai-compatible.headers.x-api-key".
+func IsKnown(key string) bool {
+	for _, k := range Keys {
+		if key == k.Name || strings.HasPrefix(key, k.Name+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UserPath returns the path of the user configuration file, honoring
+// XDG_CONFIG_HOME, and defaulting to ~/.config/committer/config.yaml.
+func UserPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", errorcatalog.MustGet(
+				errorcatalog.ErrFailedToLoadConfig,
+				customerror.WithError(err),
+			).NewFailedToError()
+		}
+
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, shared.Name, UserFileName), nil
+}
+
+// RepoPath returns the path of the repository configuration file.
+func RepoPath(repoRoot string) string {
+	return filepath.Join(repoRoot, RepoFileName)
+}
+
+// Load loads the effective configuration. Defaults are overridden by the user
+// file, which is overridden by the repository file, which is overridden by
+// environment variables. An empty repoRoot skips the repository file. Env vars
+// only override the known keys, and the ones set in the files, so the keys of
+// a group, e.g.: "openai-compatible.headers.x-api-key", can't be set from env
+// vars, as their names can't be told from the env var's.
+func Load(repoRoot string) (*Config, error) {
+	c := &Config{settings: map[string]Setting{}}
+
+	for _, k := range Keys {
+		c.Set(k.Name, k.Default, SourceDefault)
+	}
+
+	type layer struct {
+		source string
+		path   string
+	}
+
+	userPath, err := UserPath()
+	if err != nil {
+		return nil, err
+	}
+
+	layers := []layer{{SourceUser, userPath}}
+
+	if repoRoot != "" {
+		layers = append(layers, layer{SourceRepo, RepoPath(repoRoot)})
+	}
+
+	for _, l := range layers {
+		values, err := readFile(l.path)
+		if err != nil {
+			return nil, err
+		}
+
+		for key, value := range values {
+			c.settings[key] = Setting{Key: key, Value: value, Source: l.source, Path: l.path}
+		}
+	}
+
+	for key := range c.settings {
+		if value, ok := os.LookupEnv(EnvName(key)); ok {
+			c.Set(key, value, SourceEnv)
+		}
+	}
+
+	return c, nil
+}
+
+// Save sets the key in the configuration file at path, creating it if needed.
+// Comma separated values are stored as lists.
+func Save(path, key, value string) error {
+	raw := map[string]any{}
+
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errorcatalog.MustGet(
+			errorcatalog.ErrFailedToSaveConfig,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}
+
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return errorcatalog.MustGet(
+			errorcatalog.ErrFailedToSaveConfig,
+			customerror.WithError(fmt.Errorf("%s: %w", path, err)),
+		).NewFailedToError()
+	}
+
+	if raw == nil {
+		raw = map[string]any{}
+	}
+
+	// Walk down nested maps for dotted keys.
+	parts := strings.Split(key, ".")
+	node := raw
+
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := node[part].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			node[part] = child
+		}
+
+		node = child
+	}
+
+	var v any = value
+	if strings.Contains(value, ",") {
+		v = strings.Split(value, ",")
+	}
+
+	node[parts[len(parts)-1]] = v
+
+	out, err := yaml.Marshal(raw)
+	if err != nil {
+		return errorcatalog.MustGet(
+			errorcatalog.ErrFailedToSaveConfig,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return errorcatalog.MustGet(
+			errorcatalog.ErrFailedToSaveConfig,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}
+
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		return errorcatalog.MustGet(
+			errorcatalog.ErrFailedToSaveConfig,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}
+
+	return nil
+}
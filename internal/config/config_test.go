@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile writes a configuration file for tests.
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLoad verifies the precedence of defaults, user file, repository file,
+// and environment variables.
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	repo := t.TempDir()
+
+	t.Setenv("XDG_CONFIG_HOME", home)
+
+	writeFile(t, filepath.Join(home, "committer", UserFileName), "provider: anthropic\nmodel: claude\n")
+	writeFile(t, filepath.Join(repo, RepoFileName), "model: repo-model\nmodle: typo\nexclude:\n  - go.sum\n  - vendor/**\n")
+
+	t.Setenv("COMMITTER_CHUNK_THRESHOLD", "1000")
+
+	c, err := Load(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		source string
+	}{
+		{"auto-accept", "false", SourceDefault},
+		{"provider", "anthropic", SourceUser},
+		{"model", "repo-model", SourceRepo},
+		{"chunk-threshold", "1000", SourceEnv},
+	}
+
+	for _, tt := range tests {
+		s, ok := c.Get(tt.key)
+		if !ok {
+			t.Errorf("%s: expected setting to exist", tt.key)
+
+			continue
+		}
+
+		if s.Value != tt.value || s.Source != tt.source {
+			t.Errorf("%s: expected %q from %s, got %q from %s", tt.key, tt.value, tt.source, s.Value, s.Source)
+		}
+	}
+
+	if list := c.List("exclude"); len(list) != 2 || list[1] != "vendor/**" {
+		t.Errorf("unexpected list: %v", list)
+	}
+	if unknown := c.Unknown(); len(unknown) != 1 || unknown[0].Key != "modle" {
+		t.Errorf("expected the misspelled key to be unknown, got %v", unknown)
+	}
+}
+
+// TestSave verifies settings are written, and read back, including nested
+// keys, and lists.
+func TestSave(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	repo := t.TempDir()
+	path := RepoPath(repo)
+
+	if err := Save(path, "provider", "ollama"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := Save(path, "group.nested", "a,b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	c, err := Load(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if v := c.String("provider"); v != "ollama" {
+		t.Errorf("expected ollama, got %q", v)
+	}
+
+	if v := c.String("group.nested"); v != "a,b" {
+		t.Errorf("expected a,b, got %q", v)
+	}
+}
+
+// TestEnvName verifies the environment variable naming.
+func TestEnvName(t *testing.T) {
+	if name := EnvName("llm-api-call-timeout"); name != "COMMITTER_LLM_API_CALL_TIMEOUT" {
+		t.Errorf("unexpected env name: %s", name)
+	}
+}
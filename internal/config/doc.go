@@ -0,0 +1,4 @@
+// Package config loads the committer configuration from the user, and the
+// repository configuration files, and from environment variables, keeping
+// track of where each value came from.
+package config
@@ -10,45 +10,129 @@ import (
 //////
 
 const (
-	ErrEmptyCommitMessage       = "ERR_EMPTY_COMMIT_MESSAGE"         // Missing.
-	ErrFailedToCallLLM          = "ERR_FAILED_TO_CALL_LLM"           // FailedTo.
-	ErrFailedToChunkDiff        = "ERR_FAILED_TO_CHUNK_DIFF"         // FailedTo.
-	ErrFailedToCreateHTTPClient = "ERR_FAILED_TO_CREATE_HTTP_CLIENT" // FailedTo.
-	ErrFailedToGetTags          = "ERR_FAILED_TO_GET_TAGS"           // FailedTo.
-	ErrFailedToGitDiff          = "ERR_FAILED_TO_GIT_DIFF"           // FailedTo.
-	ErrFailedToGitStats         = "ERR_FAILED_TO_GIT_STATS"          // FailedTo.
-	ErrFailedToInitChunker      = "ERR_FAILED_TO_INIT_CHUNKER"       // FailedTo.
-	ErrFailedToInitTea          = "ERR_FAILED_TO_INIT_TEA"           // FailedTo.
-	ErrFailedToRunTeaProgram    = "ERR_FAILED_TO_RUN_TEA_PROGRAM"    // FailedTo.
-	ErrFailedToSetupLLM         = "ERR_FAILED_TO_SETUP_LLM"          // FailedTo.
-	ErrFailedToStageFiles       = "ERR_FAILED_TO_STAGE_FILES"        // FailedTo.
-	ErrInvalidProvider          = "ERR_INVALID_PROVIDER"             // Invalid.
-	ErrNotGitRepo               = "ERR_NOT_GIT_REPO"                 // Required.
+	ErrCommitsOnRemote            = "ERR_COMMITS_ON_REMOTE"              // Required.
+	ErrCommitsPushed              = "ERR_COMMITS_PUSHED"                 // Required.
+	ErrEmptyCommitMessage         = "ERR_EMPTY_COMMIT_MESSAGE"           // Missing.
+	ErrEmptyPullRequest           = "ERR_EMPTY_PULL_REQUEST"             // Missing.
+	ErrFailedToAddTrailers        = "ERR_FAILED_TO_ADD_TRAILERS"         // FailedTo.
+	ErrFailedToCallLLM            = "ERR_FAILED_TO_CALL_LLM"             // FailedTo.
+	ErrFailedToChunkDiff          = "ERR_FAILED_TO_CHUNK_DIFF"           // FailedTo.
+	ErrFailedToCreateHTTPClient   = "ERR_FAILED_TO_CREATE_HTTP_CLIENT"   // FailedTo.
+	ErrFailedToGetIdentity        = "ERR_FAILED_TO_GET_IDENTITY"         // FailedTo.
+	ErrFailedToGetTags            = "ERR_FAILED_TO_GET_TAGS"             // FailedTo.
+	ErrFailedToGitDiff            = "ERR_FAILED_TO_GIT_DIFF"             // FailedTo.
+	ErrFailedToGitLog             = "ERR_FAILED_TO_GIT_LOG"              // FailedTo.
+	ErrFailedToGitStats           = "ERR_FAILED_TO_GIT_STATS"            // FailedTo.
+	ErrFailedToInitChunker        = "ERR_FAILED_TO_INIT_CHUNKER"         // FailedTo.
+	ErrFailedToInitTea            = "ERR_FAILED_TO_INIT_TEA"             // FailedTo.
+	ErrFailedToInstallHook        = "ERR_FAILED_TO_INSTALL_HOOK"         // FailedTo.
+	ErrFailedToLoadConfig         = "ERR_FAILED_TO_LOAD_CONFIG"          // FailedTo.
+	ErrFailedToLoadLintRules      = "ERR_FAILED_TO_LOAD_LINT_RULES"      // FailedTo.
+	ErrFailedToLoadPromptTemplate = "ERR_FAILED_TO_LOAD_PROMPT_TEMPLATE" // FailedTo.
+	ErrFailedToLoadSecretRules    = "ERR_FAILED_TO_LOAD_SECRET_RULES"    // FailedTo.
+	ErrFailedToPolishChangelog    = "ERR_FAILED_TO_POLISH_CHANGELOG"     // FailedTo.
+	ErrFailedToReadCommitTemplate = "ERR_FAILED_TO_READ_COMMIT_TEMPLATE" // FailedTo.
+	ErrFailedToRenderPrompt       = "ERR_FAILED_TO_RENDER_PROMPT"        // FailedTo.
+	ErrFailedToRestoreIndex       = "ERR_FAILED_TO_RESTORE_INDEX"        // FailedTo.
+	ErrFailedToRewordCommits      = "ERR_FAILED_TO_REWORD_COMMITS"       // FailedTo.
+	ErrFailedToRunTeaProgram      = "ERR_FAILED_TO_RUN_TEA_PROGRAM"      // FailedTo.
+	ErrFailedToSaveConfig         = "ERR_FAILED_TO_SAVE_CONFIG"          // FailedTo.
+	ErrFailedToSetupLLM           = "ERR_FAILED_TO_SETUP_LLM"            // FailedTo.
+	ErrFailedToSignCommit         = "ERR_FAILED_TO_SIGN_COMMIT"          // FailedTo.
+	ErrFailedToSignTag            = "ERR_FAILED_TO_SIGN_TAG"             // FailedTo.
+	ErrFailedToSnapshotIndex      = "ERR_FAILED_TO_SNAPSHOT_INDEX"       // FailedTo.
+	ErrFailedToSplitCommits       = "ERR_FAILED_TO_SPLIT_COMMITS"        // FailedTo.
+	ErrFailedToStageFiles         = "ERR_FAILED_TO_STAGE_FILES"          // FailedTo.
+	ErrFailedToSummarizeDiff      = "ERR_FAILED_TO_SUMMARIZE_DIFF"       // FailedTo.
+	ErrFailedToUninstallHook      = "ERR_FAILED_TO_UNINSTALL_HOOK"       // FailedTo.
+	ErrFailedToWriteChangelog     = "ERR_FAILED_TO_WRITE_CHANGELOG"      // FailedTo.
+	ErrFailedToWriteMessageFile   = "ERR_FAILED_TO_WRITE_MESSAGE_FILE"   // FailedTo.
+	ErrInvalidChangelogTemplate   = "ERR_INVALID_CHANGELOG_TEMPLATE"     // Invalid.
+	ErrInvalidChunkStrategy       = "ERR_INVALID_CHUNK_STRATEGY"         // Invalid.
+	ErrInvalidCoAuthor            = "ERR_INVALID_CO_AUTHOR"              // Invalid.
+	ErrInvalidCommitMessage       = "ERR_INVALID_COMMIT_MESSAGE"         // Invalid.
+	ErrInvalidCommitPlan          = "ERR_INVALID_COMMIT_PLAN"            // Invalid.
+	ErrInvalidConfigKey           = "ERR_INVALID_CONFIG_KEY"             // Invalid.
+	ErrInvalidIssuePattern        = "ERR_INVALID_ISSUE_PATTERN"          // Invalid.
+	ErrInvalidIssuePlacement      = "ERR_INVALID_ISSUE_PLACEMENT"        // Invalid.
+	ErrInvalidOutputFormat        = "ERR_INVALID_OUTPUT_FORMAT"          // Invalid.
+	ErrInvalidPromptTemplate      = "ERR_INVALID_PROMPT_TEMPLATE"        // Invalid.
+	ErrInvalidProvider            = "ERR_INVALID_PROVIDER"               // Invalid.
+	ErrInvalidRevisionRange       = "ERR_INVALID_REVISION_RANGE"         // Invalid.
+	ErrInvalidSecretsMode         = "ERR_INVALID_SECRETS_MODE"           // Invalid.
+	ErrInvalidSigningFormat       = "ERR_INVALID_SIGNING_FORMAT"         // Invalid.
+	ErrInvalidTrailer             = "ERR_INVALID_TRAILER"                // Invalid.
+	ErrInvalidVersion             = "ERR_INVALID_VERSION"                // Invalid.
+	ErrNotGitRepo                 = "ERR_NOT_GIT_REPO"                   // Required.
+	ErrSecretsFound               = "ERR_SECRETS_FOUND"                  // Required.
 )
 
 // errorCatalog is the error catalog for the CLI.
 var errorCatalog = customerror.
 	MustNewCatalog(shared.Name).
+	MustSet(ErrCommitsOnRemote, "commits already on remote branches are only rewritten with --force").
+	MustSet(ErrCommitsPushed, "commits already on the upstream branch can't be rewritten").
 	MustSet(ErrEmptyCommitMessage, "commit message").
+	MustSet(ErrEmptyPullRequest, "pull request title").
+	MustSet(ErrFailedToAddTrailers, "add trailers").
 	MustSet(ErrFailedToCallLLM, "call LLM API").
 	MustSet(ErrFailedToChunkDiff, "chunk diff").
 	MustSet(ErrFailedToCreateHTTPClient, "create HTTP client").
+	MustSet(ErrFailedToGetIdentity, "get git identity").
 	MustSet(ErrFailedToGetTags, "retrieve git tags").
 	MustSet(ErrFailedToGitDiff, "obtain git diff").
+	MustSet(ErrFailedToGitLog, "obtain git log").
 	MustSet(ErrFailedToGitStats, "obtain git stats").
 	MustSet(ErrFailedToInitChunker, "initialize chunker").
 	MustSet(ErrFailedToInitTea, "initialize Tea application").
+	MustSet(ErrFailedToInstallHook, "install git hook").
+	MustSet(ErrFailedToLoadConfig, "load configuration").
+	MustSet(ErrFailedToLoadLintRules, "load lint rules").
+	MustSet(ErrFailedToLoadPromptTemplate, "load prompt template").
+	MustSet(ErrFailedToLoadSecretRules, "load secret scanning rules").
+	MustSet(ErrFailedToPolishChangelog, "polish changelog").
+	MustSet(ErrFailedToReadCommitTemplate, "read commit template").
+	MustSet(ErrFailedToRenderPrompt, "render prompt").
+	MustSet(ErrFailedToRestoreIndex, "restore index").
+	MustSet(ErrFailedToRewordCommits, "reword commits").
 	MustSet(ErrFailedToRunTeaProgram, "run Tea program").
+	MustSet(ErrFailedToSaveConfig, "save configuration").
 	MustSet(ErrFailedToSetupLLM, "setup LLM API").
+	MustSet(ErrFailedToSignCommit, "sign commit").
+	MustSet(ErrFailedToSignTag, "sign tag").
+	MustSet(ErrFailedToSnapshotIndex, "snapshot index").
+	MustSet(ErrFailedToSplitCommits, "split commits").
 	MustSet(ErrFailedToStageFiles, "stage files").
+	MustSet(ErrFailedToSummarizeDiff, "summarize diff chunk").
+	MustSet(ErrFailedToUninstallHook, "uninstall git hook").
+	MustSet(ErrFailedToWriteChangelog, "write changelog").
+	MustSet(ErrFailedToWriteMessageFile, "write message file").
+	MustSet(ErrInvalidChangelogTemplate, "changelog template").
+	MustSet(ErrInvalidChunkStrategy, "chunk strategy").
+	MustSet(ErrInvalidCoAuthor, "co-author").
+	MustSet(ErrInvalidCommitMessage, "commit message").
+	MustSet(ErrInvalidCommitPlan, "commit plan").
+	MustSet(ErrInvalidConfigKey, "configuration key").
+	MustSet(ErrInvalidIssuePattern, "issue pattern").
+	MustSet(ErrInvalidIssuePlacement, "issue placement").
+	MustSet(ErrInvalidOutputFormat, "output format").
+	MustSet(ErrInvalidPromptTemplate, "prompt template").
 	MustSet(ErrInvalidProvider, "provider").
-	MustSet(ErrNotGitRepo, "current directory is not a git repository")
+	MustSet(ErrInvalidRevisionRange, "revision range").
+	MustSet(ErrInvalidSecretsMode, "secrets mode").
+	MustSet(ErrInvalidSigningFormat, "signing format").
+	MustSet(ErrInvalidTrailer, "trailer").
+	MustSet(ErrInvalidVersion, "version").
+	MustSet(ErrNotGitRepo, "current directory is not a git repository").
+	MustSet(ErrSecretsFound, "staged changes contain secrets")
 
 //////
 // Exported functionalities.
 //////
 
-// MustGet returns a custom error from the error catalog.
+// MustGet returns a custom error from the error catalog. The catalog ignores
+// options, so they are applied to a child of the catalog entry, which also
+// keeps the shared entry untouched.
 func MustGet(errorCode string, opts ...customerror.Option) *customerror.CustomError {
-	return errorCatalog.MustGet(errorCode, opts...)
+	return errorCatalog.MustGet(errorCode).NewChildError(opts...)
 }
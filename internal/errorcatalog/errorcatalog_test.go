@@ -1,7 +1,10 @@
 package errorcatalog
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/thalesfsp/customerror"
 )
 
 // TestErrorCatalog_ErrFailedToGetTags verifies the error catalog contains
@@ -23,20 +26,61 @@ func TestErrorCatalog_ErrFailedToGetTags(t *testing.T) {
 // be retrieved without panicking.
 func TestErrorCatalog_AllEntriesExist(t *testing.T) {
 	entries := []string{
+		ErrCommitsOnRemote,
+		ErrCommitsPushed,
 		ErrEmptyCommitMessage,
+		ErrEmptyPullRequest,
+		ErrFailedToAddTrailers,
 		ErrFailedToCallLLM,
 		ErrFailedToChunkDiff,
 		ErrFailedToCreateHTTPClient,
+		ErrFailedToGetIdentity,
 		ErrFailedToGetTags,
 		ErrFailedToGitDiff,
+		ErrFailedToGitLog,
 		ErrFailedToGitStats,
 		ErrFailedToInitChunker,
 		ErrFailedToInitTea,
+		ErrFailedToInstallHook,
+		ErrFailedToLoadConfig,
+		ErrFailedToLoadLintRules,
+		ErrFailedToLoadPromptTemplate,
+		ErrFailedToLoadSecretRules,
+		ErrFailedToPolishChangelog,
+		ErrFailedToReadCommitTemplate,
+		ErrFailedToRenderPrompt,
+		ErrFailedToRestoreIndex,
+		ErrFailedToRewordCommits,
 		ErrFailedToRunTeaProgram,
+		ErrFailedToSaveConfig,
 		ErrFailedToSetupLLM,
+		ErrFailedToSignCommit,
+		ErrFailedToSignTag,
+		ErrFailedToSnapshotIndex,
+		ErrFailedToSplitCommits,
 		ErrFailedToStageFiles,
+		ErrFailedToSummarizeDiff,
+		ErrFailedToUninstallHook,
+		ErrFailedToWriteChangelog,
+		ErrFailedToWriteMessageFile,
+		ErrInvalidChangelogTemplate,
+		ErrInvalidChunkStrategy,
+		ErrInvalidCoAuthor,
+		ErrInvalidCommitMessage,
+		ErrInvalidCommitPlan,
+		ErrInvalidConfigKey,
+		ErrInvalidIssuePattern,
+		ErrInvalidIssuePlacement,
+		ErrInvalidOutputFormat,
+		ErrInvalidPromptTemplate,
 		ErrInvalidProvider,
+		ErrInvalidRevisionRange,
+		ErrInvalidSecretsMode,
+		ErrInvalidSigningFormat,
+		ErrInvalidTrailer,
+		ErrInvalidVersion,
 		ErrNotGitRepo,
+		ErrSecretsFound,
 	}
 
 	for _, code := range entries {
@@ -48,3 +92,19 @@ func TestErrorCatalog_AllEntriesExist(t *testing.T) {
 		})
 	}
 }
+
+// TestMustGet_AppliesOptions verifies options, e.g.: the wrapped error, are
+// applied to the returned error without changing the catalog entry.
+func TestMustGet_AppliesOptions(t *testing.T) {
+	cause := errors.New("boom")
+
+	err := MustGet(ErrFailedToGitDiff, customerror.WithError(cause))
+
+	if !errors.Is(err, cause) {
+		t.Errorf("expected error to wrap the cause, got %v", err)
+	}
+
+	if errors.Is(MustGet(ErrFailedToGitDiff), cause) {
+		t.Error("expected the catalog entry to be untouched")
+	}
+}
@@ -0,0 +1,1145 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/gitdiff"
+	"github.com/thalesfsp/committer/internal/pathfilter"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// FakeCommit is a commit of a Fake repository.
+type FakeCommit struct {
+	// Hash of the commit.
+	Hash string
+
+	// Message of the commit.
+	Message string
+
+	// Author of the commit, as "Name <email>".
+	Author string
+
+	// Signed tells if the commit was signed.
+	Signed bool
+
+	// Date of the commit, e.g.: "2024-01-01".
+	Date string
+
+	// Files of the commit, their content keyed by path.
+	Files map[string]string
+}
+
+// FakeTag is a tag of a Fake repository.
+type FakeTag struct {
+	// Name of the tag, e.g.: "v1.2.0".
+	Name string
+
+	// Message of the annotated tag.
+	Message string
+
+	// Commit is the hash of the tagged commit.
+	Commit string
+
+	// Signed tells if the tag was signed.
+	Signed bool
+}
+
+// Fake is an in-memory Repository, for unit tests. Files are kept whole,
+// their content keyed by path, so diffs, and stats are simplified: every
+// changed file is a single hunk. Methods fail with the error set in Errors
+// for their name, e.g.: "Push".
+type Fake struct {
+	// RootDir is the top-level directory, Root fails if empty.
+	RootDir string
+
+	// HooksPath is the hooks directory, HooksDir fails if empty.
+	HooksPath string
+
+	// Branch is the current branch.
+	Branch string
+
+	// Default is the default branch, e.g.: "origin/main", DefaultBranch
+	// fails if empty.
+	Default string
+
+	// Date is the date of new commits, e.g.: "2024-01-01".
+	Date string
+
+	// User is the committer, and the author of new commits, as
+	// "Name <email>", Identity fails if empty.
+	User string
+
+	// Template is the content of the commit template.
+	Template string
+
+	// Worktree are the files of the working tree.
+	Worktree map[string]string
+
+	// Index are the staged files.
+	Index map[string]string
+
+	// Commits of the current branch, oldest first.
+	Commits []FakeCommit
+
+	// Tags, in the order they were created.
+	Tags []FakeTag
+
+	// Pushed are the hashes of the commits on the upstream branch, there's
+	// no upstream branch if it's empty.
+	Pushed []string
+
+	// PushedTags are the names of the pushed tags, in order.
+	PushedTags []string
+
+	// Remotes are the hashes of the commits of the remote branches other
+	// than the upstream one, keyed by name, e.g.: "origin/feature".
+	Remotes map[string][]string
+
+	// Errors returned by the methods, keyed by name.
+	Errors map[string]error
+
+	// seq numbers the commits, so their hashes are unique.
+	seq int
+
+	// trees are the snapshots of the index, keyed by tree.
+	trees map[string]map[string]string
+}
+
+//////
+// Helpers.
+//////
+
+// fail returns the error set for the method, if any.
+func (f *Fake) fail(method string) error {
+	return f.Errors[method]
+}
+
+// head returns the files of HEAD, empty if there are no commits.
+func (f *Fake) head() map[string]string {
+	if len(f.Commits) == 0 {
+		return map[string]string{}
+	}
+
+	return f.Commits[len(f.Commits)-1].Files
+}
+
+// parent returns the files of the parent of HEAD, empty if HEAD is the root
+// commit.
+func (f *Fake) parent() map[string]string {
+	if len(f.Commits) < 2 {
+		return map[string]string{}
+	}
+
+	return f.Commits[len(f.Commits)-2].Files
+}
+
+// files returns the files of the i-th commit, empty if there's none, e.g.:
+// the parent of the root commit.
+func (f *Fake) files(i int) map[string]string {
+	if i < 0 || i >= len(f.Commits) {
+		return map[string]string{}
+	}
+
+	return f.Commits[i].Files
+}
+
+// unknownRef is the error of refs not pointing to any commit.
+func unknownRef(ref string) error {
+	return errorcatalog.MustGet(
+		errorcatalog.ErrFailedToGitLog,
+		customerror.WithError(fmt.Errorf("unknown ref %s", ref)),
+	)
+}
+
+// resolve returns the index of the commit the ref points to: HEAD, the
+// upstream branch, a tag, or a hash, or its 7 characters short form.
+func (f *Fake) resolve(ref string) (int, bool) {
+	switch {
+	case len(f.Commits) == 0:
+		return -1, false
+	case ref == "HEAD":
+		return len(f.Commits) - 1, true
+	case ref != "" && ref == f.Upstream():
+		ref = f.Pushed[len(f.Pushed)-1]
+	}
+
+	for _, tag := range f.Tags {
+		if tag.Name == ref {
+			ref = tag.Commit
+		}
+	}
+
+	for i, c := range f.Commits {
+		if c.Hash == ref || (len(ref) >= 7 && strings.HasPrefix(c.Hash, ref)) {
+			return i, true
+		}
+	}
+
+	return -1, false
+}
+
+// commit commits the index with the message, replacing HEAD if `amend` is
+// set.
+func (f *Fake) commit(message string, amend bool, signing Signing) {
+	f.seq++
+
+	c := FakeCommit{
+		Hash:    fmt.Sprintf("%040x", f.seq),
+		Message: message,
+		Author:  f.User,
+		Signed:  signing.Sign,
+		Date:    f.Date,
+		Files:   maps.Clone(f.Index),
+	}
+
+	// Amending keeps the author.
+	if amend {
+		c.Author = f.Commits[len(f.Commits)-1].Author
+		f.Commits[len(f.Commits)-1] = c
+
+		return
+	}
+
+	f.Commits = append(f.Commits, c)
+}
+
+// changed returns the paths which differ between the files, sorted.
+func changed(from, to map[string]string) []string {
+	paths := []string{}
+
+	for path, content := range to {
+		if old, ok := from[path]; !ok || old != content {
+			paths = append(paths, path)
+		}
+	}
+
+	for path := range from {
+		if _, ok := to[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+// lines splits the content into newline terminated lines.
+func lines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+
+	l := strings.SplitAfter(content, "\n")
+
+	return l[:len(l)-1]
+}
+
+// fakeDiff renders the differences of the files as a unified diff, every
+// file being a single hunk. Only the paths are diffed, if given, and the
+// ones excluded by the filter are left out, and returned.
+func fakeDiff(filter *pathfilter.Filter, from, to map[string]string, paths []string) (string, []string) {
+	var b strings.Builder
+
+	excluded := []string{}
+
+	for _, path := range changed(from, to) {
+		if len(paths) > 0 && !slices.Contains(paths, path) {
+			continue
+		}
+
+		if filter.IsExcluded(path) {
+			excluded = append(excluded, path)
+
+			continue
+		}
+
+		oldContent, existed := from[path]
+		newContent, exists := to[path]
+
+		oldName, newName := "a/"+path, "b/"+path
+
+		if !existed {
+			oldName = "/dev/null"
+		}
+
+		if !exists {
+			newName = "/dev/null"
+		}
+
+		oldLines, newLines := lines(oldContent), lines(newContent)
+
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- %s\n+++ %s\n", path, path, oldName, newName)
+		fmt.Fprintf(&b, "@@ -1,%d +1,%d @@\n", len(oldLines), len(newLines))
+
+		for _, line := range oldLines {
+			b.WriteString("-" + line)
+		}
+
+		for _, line := range newLines {
+			b.WriteString("+" + line)
+		}
+	}
+
+	return b.String(), excluded
+}
+
+// fakeStats renders the statistics of the differences of the files, one line
+// per file, the excluded ones listed afterwards, like getStats does.
+func fakeStats(from, to map[string]string, excluded []string, paths []string) string {
+	var b, rest strings.Builder
+
+	for _, path := range changed(from, to) {
+		if len(paths) > 0 && !slices.Contains(paths, path) {
+			continue
+		}
+
+		line := fmt.Sprintf(" %s | +%d -%d\n", path, len(lines(to[path])), len(lines(from[path])))
+
+		if slices.Contains(excluded, path) {
+			rest.WriteString(line)
+
+			continue
+		}
+
+		b.WriteString(line)
+	}
+
+	if rest.Len() > 0 {
+		b.WriteString("\n" + excludedStatsHeader + "\n" + rest.String())
+	}
+
+	return b.String()
+}
+
+//////
+// Exported methods.
+//////
+
+// IsRepo always returns true.
+func (f *Fake) IsRepo() bool {
+	return true
+}
+
+// Root returns RootDir.
+func (f *Fake) Root() (string, error) {
+	if f.RootDir == "" {
+		return "", errorcatalog.MustGet(errorcatalog.ErrNotGitRepo).NewRequiredError()
+	}
+
+	return f.RootDir, nil
+}
+
+// HooksDir returns HooksPath.
+func (f *Fake) HooksDir() (string, error) {
+	if f.HooksPath == "" {
+		return "", errors.New("no hooks directory")
+	}
+
+	return f.HooksPath, nil
+}
+
+// IsDirty checks if tracked files of the working tree differ from the index.
+func (f *Fake) IsDirty() bool {
+	for path, content := range f.Index {
+		if current, ok := f.Worktree[path]; !ok || current != content {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasStagedChanges checks if the index differs from HEAD.
+func (f *Fake) HasStagedChanges() bool {
+	return len(changed(f.head(), f.Index)) > 0
+}
+
+// HasCommits checks if there's at least one commit.
+func (f *Fake) HasCommits() bool {
+	return len(f.Commits) > 0
+}
+
+// CurrentBranch returns Branch.
+func (f *Fake) CurrentBranch() (string, error) {
+	return f.Branch, f.fail("CurrentBranch")
+}
+
+// UnstagedDiff returns the differences between the tracked files of the
+// working tree, and the index.
+func (f *Fake) UnstagedDiff() (string, error) {
+	if err := f.fail("UnstagedDiff"); err != nil {
+		return "", err
+	}
+
+	tracked := map[string]string{}
+
+	for path := range f.Index {
+		if content, ok := f.Worktree[path]; ok {
+			tracked[path] = content
+		}
+	}
+
+	diff, _ := fakeDiff(nil, f.Index, tracked, nil)
+
+	return diff, nil
+}
+
+// UntrackedFiles returns the paths of the files of the working tree not in
+// the index, sorted.
+func (f *Fake) UntrackedFiles() ([]string, error) {
+	if err := f.fail("UntrackedFiles"); err != nil {
+		return nil, err
+	}
+
+	untracked := []string{}
+
+	for path := range f.Worktree {
+		if _, ok := f.Index[path]; !ok {
+			untracked = append(untracked, path)
+		}
+	}
+
+	sort.Strings(untracked)
+
+	return untracked, nil
+}
+
+// StagedFiles returns the paths which differ between HEAD, and the index,
+// sorted.
+func (f *Fake) StagedFiles() ([]string, error) {
+	if err := f.fail("StagedFiles"); err != nil {
+		return nil, err
+	}
+
+	return changed(f.head(), f.Index), nil
+}
+
+// AddAll stages the working tree.
+func (f *Fake) AddAll() error {
+	if err := f.fail("AddAll"); err != nil {
+		return err
+	}
+
+	f.Index = maps.Clone(f.Worktree)
+
+	return nil
+}
+
+// StageFiles stages the files as they're in the working tree, removing the
+// deleted ones from the index.
+func (f *Fake) StageFiles(paths []string) error {
+	if err := f.fail("StageFiles"); err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		if content, ok := f.Worktree[path]; ok {
+			f.Index[path] = content
+		} else {
+			delete(f.Index, path)
+		}
+	}
+
+	return nil
+}
+
+// ApplyCached stages the whole files changed by the patch, not only its
+// hunks.
+func (f *Fake) ApplyCached(patch string) error {
+	if err := f.fail("ApplyCached"); err != nil {
+		return err
+	}
+
+	paths := []string{}
+
+	for _, file := range gitdiff.Parse(patch) {
+		paths = append(paths, file.Path)
+
+		if file.OldPath != "" && file.OldPath != file.Path {
+			paths = append(paths, file.OldPath)
+		}
+	}
+
+	return f.StageFiles(paths)
+}
+
+// Snapshot saves the index, and HEAD.
+func (f *Fake) Snapshot() (IndexSnapshot, error) {
+	if err := f.fail("Snapshot"); err != nil {
+		return IndexSnapshot{}, err
+	}
+
+	if f.trees == nil {
+		f.trees = map[string]map[string]string{}
+	}
+
+	snapshot := IndexSnapshot{Tree: fmt.Sprintf("tree-%d", len(f.trees)+1)}
+
+	f.trees[snapshot.Tree] = maps.Clone(f.Index)
+
+	if f.HasCommits() {
+		snapshot.Head = f.Commits[len(f.Commits)-1].Hash
+	}
+
+	return snapshot, nil
+}
+
+// RestoreSnapshot removes the commits made since the snapshot, and restores
+// the index.
+func (f *Fake) RestoreSnapshot(snapshot IndexSnapshot) error {
+	if err := f.fail("RestoreSnapshot"); err != nil {
+		return err
+	}
+
+	tree, ok := f.trees[snapshot.Tree]
+	if !ok {
+		return fmt.Errorf("unknown tree %s", snapshot.Tree)
+	}
+
+	head := -1
+
+	if snapshot.Head != "" {
+		if head, ok = f.resolve(snapshot.Head); !ok {
+			return unknownRef(snapshot.Head)
+		}
+	}
+
+	f.Commits = f.Commits[:head+1]
+	f.Index = maps.Clone(tree)
+
+	return nil
+}
+
+// UnstageAll resets the index to HEAD.
+func (f *Fake) UnstageAll() error {
+	if err := f.fail("UnstageAll"); err != nil {
+		return err
+	}
+
+	f.Index = maps.Clone(f.head())
+
+	return nil
+}
+
+// StageFromTree stages the paths as they're in the snapshot of the tree,
+// removing the ones missing from it.
+func (f *Fake) StageFromTree(tree string, paths []string) error {
+	if err := f.fail("StageFromTree"); err != nil {
+		return err
+	}
+
+	files, ok := f.trees[tree]
+	if !ok {
+		return fmt.Errorf("unknown tree %s", tree)
+	}
+
+	for _, path := range paths {
+		if content, ok := files[path]; ok {
+			f.Index[path] = content
+		} else {
+			delete(f.Index, path)
+		}
+	}
+
+	return nil
+}
+
+// Diff returns the differences between HEAD, and the index.
+func (f *Fake) Diff(filter *pathfilter.Filter, paths ...string) (string, []string, error) {
+	if err := f.fail("Diff"); err != nil {
+		return "", nil, err
+	}
+
+	diff, excluded := fakeDiff(filter, f.head(), f.Index, paths)
+
+	return diff, excluded, nil
+}
+
+// AmendDiff returns the differences between the parent of HEAD, and the
+// index.
+func (f *Fake) AmendDiff(filter *pathfilter.Filter) (string, []string, error) {
+	if err := f.fail("AmendDiff"); err != nil {
+		return "", nil, err
+	}
+
+	diff, excluded := fakeDiff(filter, f.parent(), f.Index, nil)
+
+	return diff, excluded, nil
+}
+
+// CommitDiff returns the differences between the commit, and its parent.
+func (f *Fake) CommitDiff(filter *pathfilter.Filter, commit string) (string, []string, error) {
+	if err := f.fail("CommitDiff"); err != nil {
+		return "", nil, err
+	}
+
+	i, ok := f.resolve(commit)
+	if !ok {
+		return "", nil, unknownRef(commit)
+	}
+
+	diff, excluded := fakeDiff(filter, f.files(i-1), f.files(i), nil)
+
+	return diff, excluded, nil
+}
+
+// RangeDiff returns the differences between two commits.
+func (f *Fake) RangeDiff(filter *pathfilter.Filter, from, to string) (string, []string, error) {
+	if err := f.fail("RangeDiff"); err != nil {
+		return "", nil, err
+	}
+
+	i, ok := f.resolve(from)
+	if !ok {
+		return "", nil, unknownRef(from)
+	}
+
+	j, ok := f.resolve(to)
+	if !ok {
+		return "", nil, unknownRef(to)
+	}
+
+	diff, excluded := fakeDiff(filter, f.files(i), f.files(j), nil)
+
+	return diff, excluded, nil
+}
+
+// Stats returns the statistics of the differences between HEAD, and the
+// index.
+func (f *Fake) Stats(excluded []string, paths ...string) (string, error) {
+	if err := f.fail("Stats"); err != nil {
+		return "", err
+	}
+
+	return fakeStats(f.head(), f.Index, excluded, paths), nil
+}
+
+// AmendStats returns the statistics of the differences between the parent
+// of HEAD, and the index.
+func (f *Fake) AmendStats(excluded []string) (string, error) {
+	if err := f.fail("AmendStats"); err != nil {
+		return "", err
+	}
+
+	return fakeStats(f.parent(), f.Index, excluded, nil), nil
+}
+
+// CommitStats returns the statistics of the differences between the commit,
+// and its parent.
+func (f *Fake) CommitStats(excluded []string, commit string) (string, error) {
+	if err := f.fail("CommitStats"); err != nil {
+		return "", err
+	}
+
+	i, ok := f.resolve(commit)
+	if !ok {
+		return "", unknownRef(commit)
+	}
+
+	return fakeStats(f.files(i-1), f.files(i), excluded, nil), nil
+}
+
+// RangeStats returns the statistics of the differences between two commits.
+func (f *Fake) RangeStats(excluded []string, from, to string) (string, error) {
+	if err := f.fail("RangeStats"); err != nil {
+		return "", err
+	}
+
+	i, ok := f.resolve(from)
+	if !ok {
+		return "", unknownRef(from)
+	}
+
+	j, ok := f.resolve(to)
+	if !ok {
+		return "", unknownRef(to)
+	}
+
+	return fakeStats(f.files(i), f.files(j), excluded, nil), nil
+}
+
+// Commit commits the index with the message, signed if Sign is set. It fails
+// if nothing is staged.
+func (f *Fake) Commit(message string, signing Signing) error {
+	if err := f.fail("Commit"); err != nil {
+		return err
+	}
+
+	if !f.HasStagedChanges() {
+		return errors.New("nothing to commit")
+	}
+
+	f.commit(message, false, signing)
+
+	return nil
+}
+
+// CommitAmend replaces HEAD with a commit of the index, with the message,
+// signed if Sign is set.
+func (f *Fake) CommitAmend(message string, signing Signing) error {
+	if err := f.fail("CommitAmend"); err != nil {
+		return err
+	}
+
+	if !f.HasCommits() {
+		return errors.New("nothing to amend")
+	}
+
+	f.commit(message, true, signing)
+
+	return nil
+}
+
+// RewordCommits replaces the messages of the commits after `from`, keyed by
+// hash. The reworded commits, and the ones after them get new hashes, signed
+// if Sign is set, like rewriting history does. It returns the previous HEAD.
+func (f *Fake) RewordCommits(from string, messages map[string]string, signing Signing) (string, error) {
+	if err := f.fail("RewordCommits"); err != nil {
+		return "", err
+	}
+
+	start, ok := f.resolve(from)
+	if !ok || !f.HasCommits() {
+		return "", unknownRef(from)
+	}
+
+	previous := f.Commits[len(f.Commits)-1].Hash
+
+	rewritten := false
+
+	for i := start + 1; i < len(f.Commits); i++ {
+		message, reworded := messages[f.Commits[i].Hash]
+
+		if !reworded && !rewritten {
+			continue
+		}
+
+		if reworded {
+			f.Commits[i].Message = message
+		}
+
+		rewritten = true
+
+		f.seq++
+
+		f.Commits[i].Hash = fmt.Sprintf("%040x", f.seq)
+		f.Commits[i].Signed = signing.Sign
+	}
+
+	return previous, nil
+}
+
+// CommitTemplate returns the Template.
+func (f *Fake) CommitTemplate() (string, error) {
+	return f.Template, f.fail("CommitTemplate")
+}
+
+// AddTrailers adds the trailers to the message, except the ones already in
+// it, after its last paragraph if it only has trailers, otherwise in a new
+// one.
+func (f *Fake) AddTrailers(message string, trailers []string) (string, error) {
+	if err := f.fail("AddTrailers"); err != nil {
+		return "", err
+	}
+
+	message = strings.TrimSpace(message)
+	paragraphs := strings.Split(message, "\n\n")
+	existing := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+
+	isTrailers := len(paragraphs) > 1
+
+	for _, line := range existing {
+		token, _, found := strings.Cut(line, ": ")
+		isTrailers = isTrailers && found && !strings.Contains(token, " ")
+	}
+
+	if !isTrailers {
+		existing = []string{}
+	}
+
+	added := false
+
+	for _, trailer := range trailers {
+		if slices.Contains(existing, trailer) {
+			continue
+		}
+
+		if !isTrailers && !added {
+			message += "\n"
+		}
+
+		message += "\n" + trailer
+		existing = append(existing, trailer)
+		added = true
+	}
+
+	return message, nil
+}
+
+// Identity returns the User.
+func (f *Fake) Identity() (string, error) {
+	if err := f.fail("Identity"); err != nil {
+		return "", err
+	}
+
+	if f.User == "" {
+		return "", errors.New("no identity")
+	}
+
+	return f.User, nil
+}
+
+// ResolveCommit returns the hash of the commit the ref points to: HEAD, the
+// upstream branch, a tag, or a hash.
+func (f *Fake) ResolveCommit(ref string) (string, error) {
+	i, ok := f.resolve(ref)
+	if !ok {
+		return "", errorcatalog.MustGet(
+			errorcatalog.ErrInvalidRevisionRange,
+			customerror.WithField("ref", ref),
+		).NewInvalidError()
+	}
+
+	return f.Commits[i].Hash, nil
+}
+
+// Upstream returns "origin/<branch>" once commits were pushed, otherwise "".
+func (f *Fake) Upstream() string {
+	if len(f.Pushed) == 0 {
+		return ""
+	}
+
+	return "origin/" + f.Branch
+}
+
+// RemoteBranches returns the remote branches containing the commit: the
+// upstream one, if it was pushed, and Remotes, sorted.
+func (f *Fake) RemoteBranches(commit string) ([]string, error) {
+	if err := f.fail("RemoteBranches"); err != nil {
+		return nil, err
+	}
+
+	i, ok := f.resolve(commit)
+	if !ok {
+		return nil, fmt.Errorf("unknown commit %s", commit)
+	}
+
+	branches := []string{}
+
+	if slices.Contains(f.Pushed, f.Commits[i].Hash) {
+		branches = append(branches, f.Upstream())
+	}
+
+	for branch, hashes := range f.Remotes {
+		if slices.Contains(hashes, f.Commits[i].Hash) {
+			branches = append(branches, branch)
+		}
+	}
+
+	sort.Strings(branches)
+
+	return branches, nil
+}
+
+// IsAncestor checks if the commit is, or comes before the one the ref points
+// to. Commits are on the upstream branch only if they were pushed, e.g.: not
+// once amended.
+func (f *Fake) IsAncestor(commit, ref string) bool {
+	i, ok := f.resolve(commit)
+
+	if ref != "" && ref == f.Upstream() {
+		return ok && slices.Contains(f.Pushed, f.Commits[i].Hash)
+	}
+
+	j, refOk := f.resolve(ref)
+
+	return ok && refOk && i <= j
+}
+
+// DefaultBranch returns Default.
+func (f *Fake) DefaultBranch() (string, error) {
+	if f.Default == "" {
+		return "", errors.New("no default branch")
+	}
+
+	return f.Default, nil
+}
+
+// MergeBase returns the commit the ref points to, as the history is linear.
+func (f *Fake) MergeBase(ref string) (string, error) {
+	if err := f.fail("MergeBase"); err != nil {
+		return "", err
+	}
+
+	i, ok := f.resolve(ref)
+	if !ok {
+		return "", unknownRef(ref)
+	}
+
+	return f.Commits[i].Hash, nil
+}
+
+// RecentLog returns the short hashes, and subjects of the latest `count`
+// commits, latest first.
+func (f *Fake) RecentLog(count int) (string, error) {
+	if err := f.fail("RecentLog"); err != nil {
+		return "", err
+	}
+
+	log := []string{}
+
+	for i := len(f.Commits) - 1; i >= 0 && len(log) < count; i-- {
+		subject, _, _ := strings.Cut(f.Commits[i].Message, "\n")
+
+		log = append(log, f.Commits[i].Hash[:7]+" "+subject)
+	}
+
+	return strings.Join(log, "\n"), nil
+}
+
+// CommitsSince returns the commits after the one the ref points to, oldest
+// first, all of them if the ref is empty.
+func (f *Fake) CommitsSince(ref string) ([]Commit, error) {
+	if err := f.fail("CommitsSince"); err != nil {
+		return nil, err
+	}
+
+	start := 0
+
+	if ref != "" {
+		i, ok := f.resolve(ref)
+		if !ok {
+			return nil, unknownRef(ref)
+		}
+
+		start = i + 1
+	}
+
+	commits := []Commit{}
+
+	for _, c := range f.Commits[start:] {
+		commits = append(commits, Commit{Hash: c.Hash, Message: c.Message})
+	}
+
+	return commits, nil
+}
+
+// CommitsBetween returns the commits after the one `from` points to, up to
+// the one `to` points to, oldest first, all of them up to `to` if `from` is
+// empty.
+func (f *Fake) CommitsBetween(from, to string) ([]Commit, error) {
+	if err := f.fail("CommitsBetween"); err != nil {
+		return nil, err
+	}
+
+	end, ok := f.resolve(to)
+	if !ok {
+		return nil, unknownRef(to)
+	}
+
+	start := 0
+
+	if from != "" {
+		i, ok := f.resolve(from)
+		if !ok {
+			return nil, unknownRef(from)
+		}
+
+		start = i + 1
+	}
+
+	commits := []Commit{}
+
+	for _, c := range f.Commits[min(start, end+1) : end+1] {
+		commits = append(commits, Commit{Hash: c.Hash, Message: c.Message})
+	}
+
+	return commits, nil
+}
+
+// CommitDate returns the Date of the commit the ref points to.
+func (f *Fake) CommitDate(ref string) (string, error) {
+	if err := f.fail("CommitDate"); err != nil {
+		return "", err
+	}
+
+	i, ok := f.resolve(ref)
+	if !ok {
+		return "", unknownRef(ref)
+	}
+
+	return f.Commits[i].Date, nil
+}
+
+// RecentCommits returns the latest `count` commits, latest first, only the
+// ones changing the paths, if given.
+func (f *Fake) RecentCommits(count int, paths ...string) ([]Commit, error) {
+	if err := f.fail("RecentCommits"); err != nil {
+		return nil, err
+	}
+
+	commits := []Commit{}
+
+	for i := len(f.Commits) - 1; i >= 0 && len(commits) < count; i-- {
+		parent := map[string]string{}
+		if i > 0 {
+			parent = f.Commits[i-1].Files
+		}
+
+		touched := len(paths) == 0
+
+		for _, path := range changed(parent, f.Commits[i].Files) {
+			touched = touched || slices.Contains(paths, path)
+		}
+
+		if touched {
+			commits = append(commits, Commit{Hash: f.Commits[i].Hash, Message: f.Commits[i].Message})
+		}
+	}
+
+	return commits, nil
+}
+
+// Authors returns the authors of the commits, the most frequent first.
+func (f *Fake) Authors() ([]string, error) {
+	if err := f.fail("Authors"); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	authors := []string{}
+
+	for _, c := range f.Commits {
+		if c.Author == "" {
+			continue
+		}
+
+		if counts[c.Author] == 0 {
+			authors = append(authors, c.Author)
+		}
+
+		counts[c.Author]++
+	}
+
+	sort.SliceStable(authors, func(i, j int) bool {
+		if counts[authors[i]] != counts[authors[j]] {
+			return counts[authors[i]] > counts[authors[j]]
+		}
+
+		return authors[i] < authors[j]
+	})
+
+	return authors, nil
+}
+
+// Push marks every commit as pushed.
+func (f *Fake) Push() error {
+	if err := f.fail("Push"); err != nil {
+		return err
+	}
+
+	f.Pushed = f.Pushed[:0]
+
+	for _, c := range f.Commits {
+		f.Pushed = append(f.Pushed, c.Hash)
+	}
+
+	return nil
+}
+
+// FetchTags does nothing.
+func (f *Fake) FetchTags() error {
+	return f.fail("FetchTags")
+}
+
+// LatestTags returns up to `count` tags, the last created first, all of them
+// if `count` isn't positive.
+func (f *Fake) LatestTags(count int) ([]string, error) {
+	if err := f.fail("LatestTags"); err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+
+	for i := len(f.Tags) - 1; i >= 0 && (count <= 0 || len(tags) < count); i-- {
+		tags = append(tags, f.Tags[i].Name)
+	}
+
+	return tags, nil
+}
+
+// IsTag checks if there's a tag named after the ref.
+func (f *Fake) IsTag(ref string) bool {
+	return slices.ContainsFunc(f.Tags, func(t FakeTag) bool { return t.Name == ref })
+}
+
+// Tag tags HEAD, signed if Sign is set. It fails if there are no commits, or
+// the tag exists.
+func (f *Fake) Tag(tag, message string, signing Signing) error {
+	if err := f.fail("Tag"); err != nil {
+		return err
+	}
+
+	if !f.HasCommits() {
+		return errors.New("no commit to tag")
+	}
+
+	for _, t := range f.Tags {
+		if t.Name == tag {
+			return fmt.Errorf("tag %s already exists", tag)
+		}
+	}
+
+	f.Tags = append(f.Tags, FakeTag{Name: tag, Message: message, Commit: f.Commits[len(f.Commits)-1].Hash, Signed: signing.Sign})
+
+	return nil
+}
+
+// PushTag marks the tag as pushed. It fails if the tag doesn't exist.
+func (f *Fake) PushTag(tag string) error {
+	if err := f.fail("PushTag"); err != nil {
+		return err
+	}
+
+	for _, t := range f.Tags {
+		if t.Name == tag {
+			f.PushedTags = append(f.PushedTags, tag)
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("tag %s doesn't exist", tag)
+}
+
+//////
+// Factory.
+//////
+
+// NewFake returns an empty Fake repository, on the "main" branch, without
+// commits, committing as "Test <test@example.com>", on 2024-01-01.
+func NewFake() *Fake {
+	return &Fake{
+		Branch:   "main",
+		Date:     "2024-01-01",
+		User:     "Test <test@example.com>",
+		Worktree: map[string]string{},
+		Index:    map[string]string{},
+		Errors:   map[string]error{},
+	}
+}
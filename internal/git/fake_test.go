@@ -0,0 +1,260 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/thalesfsp/committer/internal/pathfilter"
+)
+
+// TestFake verifies the fake stages, diffs, commits, amends, and pushes like
+// a repository would.
+func TestFake(t *testing.T) {
+	var repo Repository = NewFake()
+
+	fake := repo.(*Fake)
+	fake.Worktree["main.go"] = "package main\n"
+	fake.Worktree["go.sum"] = "sum\n"
+
+	if repo.IsDirty() || repo.HasStagedChanges() {
+		t.Fatal("untracked files must neither be dirty, nor staged")
+	}
+
+	if untracked, _ := repo.UntrackedFiles(); strings.Join(untracked, ",") != "go.sum,main.go" {
+		t.Errorf("untracked = %v", untracked)
+	}
+
+	if err := repo.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, excluded, err := repo.Diff(pathfilter.New(nil, []string{"*.sum"}, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(diff, "+package main\n") || strings.Contains(diff, "go.sum") {
+		t.Errorf("diff = %q", diff)
+	}
+
+	if len(excluded) != 1 || excluded[0] != "go.sum" {
+		t.Errorf("excluded = %v", excluded)
+	}
+
+	stats, _ := repo.Stats(excluded)
+	if !strings.Contains(stats, " main.go | +1 -0\n\n"+excludedStatsHeader+"\n go.sum | +1 -0\n") {
+		t.Errorf("stats = %q", stats)
+	}
+
+	if err := repo.Commit("feat: add main", Signing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Commit("empty", Signing{}); err == nil {
+		t.Error("committing nothing must fail")
+	}
+
+	fake.Worktree["main.go"] = "package main\n\nfunc main() {}\n"
+
+	if !repo.IsDirty() {
+		t.Fatal("modified files must be dirty")
+	}
+
+	if err := repo.StageFiles([]string{"main.go"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.Commit("feat: add main func", Signing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	head, _ := repo.ResolveCommit("HEAD")
+
+	if err := repo.Push(); err != nil {
+		t.Fatal(err)
+	}
+
+	if upstream := repo.Upstream(); upstream != "origin/main" || !repo.IsAncestor(head, upstream) {
+		t.Errorf("HEAD must be on the upstream branch %q", upstream)
+	}
+
+	// The amended commit has the changes of HEAD, and the staged ones.
+	fake.Worktree["README.md"] = "# Main\n"
+
+	if err := repo.StageFiles([]string{"README.md"}); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, _, _ = repo.AmendDiff(nil)
+	if !strings.Contains(diff, "+func main() {}\n") || !strings.Contains(diff, "+# Main\n") {
+		t.Errorf("amend diff = %q", diff)
+	}
+
+	if err := repo.CommitAmend("feat: add main func, and readme", Signing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	amended, _ := repo.ResolveCommit("HEAD")
+	if amended == head || repo.IsAncestor(amended, repo.Upstream()) {
+		t.Error("the amended commit must be new, and unpushed")
+	}
+
+	log, _ := repo.RecentLog(10)
+	if lines := strings.Split(log, "\n"); len(lines) != 2 || !strings.HasSuffix(lines[0], " feat: add main func, and readme") {
+		t.Errorf("log = %q", log)
+	}
+}
+
+// TestFake_Tags verifies tags point to HEAD, and commits since a tag are the
+// ones after it.
+func TestFake_Tags(t *testing.T) {
+	fake := NewFake()
+
+	if err := fake.Tag("v1.0.0", "Release v1.0.0", Signing{}); err == nil {
+		t.Error("tagging without commits must fail")
+	}
+
+	for _, message := range []string{"feat: a", "fix: b", "feat: c"} {
+		fake.Worktree[message] = message
+		fake.Index[message] = message
+
+		if err := fake.Commit(message, Signing{}); err != nil {
+			t.Fatal(err)
+		}
+
+		if message == "fix: b" {
+			if err := fake.Tag("v1.0.0", "Release v1.0.0", Signing{Sign: true}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := fake.Tag("v1.0.0", "again", Signing{}); err == nil {
+		t.Error("existing tags must not be overwritten")
+	}
+
+	commits, err := fake.CommitsSince("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(commits) != 1 || commits[0].Message != "feat: c" {
+		t.Errorf("commits = %v", commits)
+	}
+
+	if err := fake.Tag("v1.1.0", "Release v1.1.0", Signing{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if tags, _ := fake.LatestTags(1); len(tags) != 1 || tags[0] != "v1.1.0" {
+		t.Errorf("tags = %v", tags)
+	}
+
+	if err := fake.PushTag("v1.1.0"); err != nil || len(fake.PushedTags) != 1 {
+		t.Errorf("pushed tags = %v, err = %v", fake.PushedTags, err)
+	}
+
+	if err := fake.PushTag("v2.0.0"); err == nil {
+		t.Error("pushing a missing tag must fail")
+	}
+}
+
+// TestFake_Reword verifies reworded commits, and the ones after them get new
+// hashes, keeping their changes, and the ones before are kept.
+func TestFake_Reword(t *testing.T) {
+	fake := NewFake()
+
+	for _, message := range []string{"feat: a", "fix: b", "feat: c"} {
+		fake.Worktree[message] = message
+		fake.Index[message] = message
+
+		if err := fake.Commit(message, Signing{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, second, head := fake.Commits[0].Hash, fake.Commits[1].Hash, fake.Commits[2].Hash
+
+	commits, err := fake.CommitsBetween(first, "HEAD")
+	if err != nil || len(commits) != 2 || commits[0].Hash != second {
+		t.Fatalf("commits = %v, err = %v", commits, err)
+	}
+
+	if diff, _, _ := fake.CommitDiff(nil, second); !strings.Contains(diff, "+fix: b") || strings.Contains(diff, "feat: a") {
+		t.Errorf("diff = %q", diff)
+	}
+
+	previous, err := fake.RewordCommits(first, map[string]string{second: "fix: bug b"}, Signing{Sign: true})
+	if err != nil || previous != head {
+		t.Fatalf("previous = %s, err = %v", previous, err)
+	}
+
+	if fake.Commits[0].Hash != first || fake.Commits[1].Hash == second || fake.Commits[2].Hash == head {
+		t.Error("only the reworded commits, and the ones after them must get new hashes")
+	}
+
+	if fake.Commits[1].Message != "fix: bug b" || !fake.Commits[1].Signed || len(fake.Commits[2].Files) != 3 {
+		t.Errorf("commits = %+v", fake.Commits)
+	}
+}
+
+// TestFake_ApplyCached verifies the files of the patch are staged, and errors
+// set for a method are returned.
+func TestFake_ApplyCached(t *testing.T) {
+	fake := NewFake()
+	fake.Worktree["a.txt"] = "a\n"
+	fake.Worktree["b.txt"] = "b\n"
+	fake.Index["a.txt"] = "old\n"
+	fake.Index["b.txt"] = "old\n"
+
+	diff, err := fake.UnstagedDiff()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patch, _, _ := strings.Cut(diff[1:], "diff --git")
+
+	if err := fake.ApplyCached("d" + patch); err != nil {
+		t.Fatal(err)
+	}
+
+	if fake.Index["a.txt"] != "a\n" || fake.Index["b.txt"] != "old\n" {
+		t.Errorf("index = %v", fake.Index)
+	}
+
+	fake.Errors["Push"] = errors.New("rejected")
+
+	if err := fake.Push(); err == nil || err.Error() != "rejected" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+// TestFake_Trailers verifies trailers join the existing ones, without
+// duplicates, and authors are the most frequent first.
+func TestFake_Trailers(t *testing.T) {
+	fake := NewFake()
+
+	message, err := fake.AddTrailers("fix: a\n\nRefs: PAY-1", []string{"Refs: PAY-1", "Signed-off-by: Test <test@example.com>"})
+	if err != nil || message != "fix: a\n\nRefs: PAY-1\nSigned-off-by: Test <test@example.com>" {
+		t.Errorf("message = %q, %v", message, err)
+	}
+
+	if message, _ := fake.AddTrailers("fix: a\n\nFixes the crash: not a trailer.", []string{"Refs: PAY-1"}); message != "fix: a\n\nFixes the crash: not a trailer.\n\nRefs: PAY-1" {
+		t.Errorf("message = %q", message)
+	}
+
+	for i, user := range []string{"Jane <jane@example.com>", "John <john@example.com>", "John <john@example.com>"} {
+		fake.User = user
+		fake.Index[user] = fmt.Sprint(i)
+
+		if err := fake.Commit("feat: "+user, Signing{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if authors, _ := fake.Authors(); strings.Join(authors, ",") != "John <john@example.com>,Jane <jane@example.com>" {
+		t.Errorf("authors = %v", authors)
+	}
+}
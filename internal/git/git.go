@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/pathfilter"
 	"github.com/thalesfsp/customerror"
 )
 
@@ -17,19 +20,52 @@ import (
 func IsCurrentDirectoryGitRepo() bool {
 	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
 
-	var stderr bytes.Buffer
+	// If the command returns an error, the current directory is not a Git repo.
+	return cmd.Run() == nil
+}
 
-	cmd.Stderr = &stderr
+// GetRepoRoot returns the absolute path of the top-level directory of the
+// current Git repository, using 'git rev-parse --show-toplevel'.
+func GetRepoRoot() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrNotGitRepo, customerror.WithError(err)).NewRequiredError()
+	}
 
-	// If the command returns an error, the current directory is not a Git repo.
-	if err := cmd.Run(); err != nil {
-		// Output the standard error to the console and return false.
-		fmt.Fprint(os.Stderr, stderr.String())
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetHooksDir returns the absolute path of the hooks directory: the one set
+// by core.hooksPath, relative to the root of the repository, otherwise the
+// hooks directory of the repository, using 'git rev-parse --git-path hooks'.
+func GetHooksDir() (string, error) {
+	root, err := GetRepoRoot()
+	if err != nil {
+		return "", err
+	}
 
-		return false
+	// Exits with 1 if unset.
+	if out, err := exec.Command("git", "config", "--get", "core.hooksPath").Output(); err == nil {
+		if hooksPath := strings.TrimSpace(string(out)); hooksPath != "" {
+			if home, err := os.UserHomeDir(); err == nil && strings.HasPrefix(hooksPath, "~/") {
+				hooksPath = filepath.Join(home, hooksPath[2:])
+			}
+
+			if !filepath.IsAbs(hooksPath) {
+				hooksPath = filepath.Join(root, hooksPath)
+			}
+
+			return hooksPath, nil
+		}
+	}
+
+	out, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrNotGitRepo, customerror.WithError(err)).NewRequiredError()
 	}
 
-	return true
+	// Relative to the current directory.
+	return filepath.Abs(strings.TrimSpace(string(out)))
 }
 
 // IsDirty checks if there are any uncommitted changes in the working directory.
@@ -73,42 +109,750 @@ func GitAddAll() error {
 	return RunCommand(exec.Command("git", "add", "."))
 }
 
-// GetGitDiff retrieves the staged differences.
-// This function runs 'git diff --staged --unified=0' to show zero lines of
-// context around differences in the output. The diff is returned as a string.
-func GetGitDiff() (string, error) {
-	cmd := exec.Command("git", "diff", "--staged", "--unified=0")
+// GetUnstagedDiff retrieves the differences between the working tree, and the
+// index, with enough context for hunks to be applied separately. Binary files
+// are included, so they can be staged from the diff too. Paths are relative
+// to the root of the repository.
+func GetUnstagedDiff() (string, error) {
+	out, err := exec.Command("git", "diff", "--no-color", "--no-ext-diff", "--binary", "--src-prefix=a/", "--dst-prefix=b/").Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGitDiff, customerror.WithError(err))
+	}
+
+	return string(out), nil
+}
+
+// GetUntrackedFiles returns the paths of the untracked files, not ignored,
+// relative to the root of the repository, using 'git ls-files --others'.
+func GetUntrackedFiles() ([]string, error) {
+	out, err := exec.Command(
+		"git", "ls-files", "--others", "--exclude-standard", "--full-name", "-z", ":/",
+	).Output()
+	if err != nil {
+		return nil, errorcatalog.MustGet(errorcatalog.ErrFailedToGitDiff, customerror.WithError(err))
+	}
+
+	return splitNull(string(out)), nil
+}
+
+// ApplyCached stages the patch, with 'git apply --cached'. Line counts of the
+// hunks are recomputed, so hunks of a diff can be applied separately.
+func ApplyCached(patch string) error {
+	root, err := GetRepoRoot()
+	if err != nil {
+		return err
+	}
+
+	// Paths are relative to the root of the repository.
+	cmd := exec.Command("git", "apply", "--cached", "--recount", "-")
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(patch)
+
+	if err := RunCommand(cmd); err != nil {
+		return errorcatalog.MustGet(errorcatalog.ErrFailedToStageFiles, customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// StageFiles stages the files, relative to the root of the repository, with
+// 'git add'.
+func StageFiles(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	args := append([]string{"add", "--"}, pathspecs(paths)...)
+
+	if err := RunCommand(exec.Command("git", args...)); err != nil {
+		return errorcatalog.MustGet(errorcatalog.ErrFailedToStageFiles, customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// staged selects the staged changes in 'git diff'.
+var staged = []string{"--staged"}
+
+// excludedStatsHeader introduces the excluded files in the stats.
+const excludedStatsHeader = "Changed, but not included in the diff (generated, vendored, or lock files):"
+
+// GetGitDiff retrieves the staged differences, without the files excluded by
+// the filter, or marked as generated with the linguist-generated attribute in
+// .gitattributes, unless explicitly included. It also returns the excluded
+// files, so they can still be listed. If paths are given, relative to the root
+// of the repository, only their differences are retrieved. This function runs
+// 'git diff --staged --unified=0' to show zero lines of context around
+// differences in the output. The diff is returned as a string.
+func GetGitDiff(filter *pathfilter.Filter, paths ...string) (string, []string, error) {
+	return getDiff(filter, staged, paths)
+}
+
+// GetRangeDiff is like GetGitDiff, but retrieves the differences between two
+// commits, using 'git diff --unified=0 <from> <to>'.
+func GetRangeDiff(filter *pathfilter.Filter, from, to string) (string, []string, error) {
+	return getDiff(filter, []string{from, to}, nil)
+}
+
+// GetAmendDiff is like GetGitDiff, but retrieves the differences the amended
+// HEAD would have: the ones of HEAD, and the staged ones, using
+// 'git diff --staged --unified=0 HEAD~1'.
+func GetAmendDiff(filter *pathfilter.Filter) (string, []string, error) {
+	parent, err := parentOf("HEAD")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return getDiff(filter, []string{"--staged", parent}, nil)
+}
+
+// GetCommitDiff is like GetGitDiff, but retrieves the differences introduced
+// by the commit, compared to its first parent.
+func GetCommitDiff(filter *pathfilter.Filter, commit string) (string, []string, error) {
+	parent, err := parentOf(commit)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return getDiff(filter, []string{parent, commit}, nil)
+}
+
+// getDiff retrieves the differences selected by the revisions, see
+// GetGitDiff.
+func getDiff(filter *pathfilter.Filter, revisions []string, paths []string) (string, []string, error) {
+	excluded, err := getExcludedFiles(filter, revisions)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(paths) > 0 {
+		excluded = intersect(excluded, paths)
+	}
+
+	args := append(append([]string{"diff", "--unified=0"}, revisions...), "--")
+	args = append(args, pathspecs(paths)...)
+
+	for _, path := range excluded {
+		args = append(args, ":(top,exclude,literal)"+path)
+	}
+
+	cmd := exec.Command("git", args...)
 
 	out, err := cmd.Output()
 	if err != nil {
-		fmt.Fprint(os.Stderr, string(out))
+		return "", nil, errorcatalog.MustGet(errorcatalog.ErrFailedToGitDiff, customerror.WithError(err))
+	}
 
-		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGitDiff, customerror.WithError(err))
+	return string(out), excluded, nil
+}
+
+// GetStagedFiles returns the paths of the staged files, relative to the root
+// of the repository, using 'git diff --staged --name-only'. Renames are listed
+// as a deletion, and an addition.
+func GetStagedFiles() ([]string, error) {
+	return getChangedFiles(staged)
+}
+
+// getChangedFiles returns the paths of the files changed in the differences
+// selected by the revisions, see GetStagedFiles.
+func getChangedFiles(revisions []string) ([]string, error) {
+	args := append(append([]string{"diff", "--name-only", "--no-renames", "-z"}, revisions...), "--")
+
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, errorcatalog.MustGet(errorcatalog.ErrFailedToGitDiff, customerror.WithError(err))
 	}
 
-	return string(out), nil
+	return splitNull(string(out)), nil
+}
+
+// GetGeneratedFiles returns which of the paths are marked as generated, with
+// the linguist-generated attribute in .gitattributes, using 'git check-attr'.
+func GetGeneratedFiles(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	root, err := GetRepoRoot()
+	if err != nil {
+		return nil, err
+	}
+
+	// Paths are relative to the root of the repository.
+	cmd := exec.Command("git", "check-attr", "-z", "--stdin", "linguist-generated")
+	cmd.Dir = root
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, errorcatalog.MustGet(errorcatalog.ErrFailedToGitDiff, customerror.WithError(err))
+	}
+
+	generated := []string{}
+
+	// Output is a sequence of: path, attribute, value.
+	fields := splitNull(string(out))
+
+	for i := 0; i+2 < len(fields); i += 3 {
+		if value := fields[i+2]; value == "set" || value == "true" {
+			generated = append(generated, fields[i])
+		}
+	}
+
+	return generated, nil
+}
+
+// GetExcludedFiles returns the staged files excluded by the filter, or marked
+// as generated, unless explicitly included by the filter.
+func GetExcludedFiles(filter *pathfilter.Filter) ([]string, error) {
+	return getExcludedFiles(filter, staged)
+}
+
+// getExcludedFiles returns the files changed in the differences selected by
+// the revisions, excluded by the filter, see GetExcludedFiles.
+func getExcludedFiles(filter *pathfilter.Filter, revisions []string) ([]string, error) {
+	changed, err := getChangedFiles(revisions)
+	if err != nil {
+		return nil, err
+	}
+
+	generated, err := GetGeneratedFiles(changed)
+	if err != nil {
+		return nil, err
+	}
+
+	isGenerated := map[string]bool{}
+
+	for _, path := range generated {
+		isGenerated[path] = true
+	}
+
+	excluded := []string{}
+
+	for _, path := range changed {
+		if filter.IsExcluded(path) || (isGenerated[path] && !filter.IsIncluded(path)) {
+			excluded = append(excluded, path)
+		}
+	}
+
+	return excluded, nil
 }
 
 // GetGitStats provides statistics of staged changes. It uses the command
 // 'git diff --cached --stat' to show file statistics (insertions, deletions)
-// for staged changes.
-func GetGitStats() (string, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--stat")
+// for staged changes. Excluded files are listed afterwards, with their line
+// counts, using 'git diff --cached --numstat', so the LLM knows they changed
+// even though they aren't in the diff. If paths are given, only their
+// statistics are provided.
+func GetGitStats(excluded []string, paths ...string) (string, error) {
+	return getStats(staged, excluded, paths)
+}
+
+// GetRangeStats is like GetGitStats, but provides the statistics of the
+// differences between two commits.
+func GetRangeStats(excluded []string, from, to string) (string, error) {
+	return getStats([]string{from, to}, excluded, nil)
+}
+
+// GetAmendStats is like GetGitStats, but provides the statistics of the
+// differences the amended HEAD would have.
+func GetAmendStats(excluded []string) (string, error) {
+	parent, err := parentOf("HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	return getStats([]string{"--staged", parent}, excluded, nil)
+}
+
+// GetCommitStats is like GetGitStats, but provides the statistics of the
+// differences introduced by the commit.
+func GetCommitStats(excluded []string, commit string) (string, error) {
+	parent, err := parentOf(commit)
+	if err != nil {
+		return "", err
+	}
+
+	return getStats([]string{parent, commit}, excluded, nil)
+}
+
+// getStats provides statistics of the differences selected by the
+// revisions, see GetGitStats.
+func getStats(revisions []string, excluded []string, paths []string) (string, error) {
+	args := append(append([]string{"diff", "--stat"}, revisions...), "--")
+
+	cmd := exec.Command("git", append(args, pathspecs(paths)...)...)
 
 	out, err := cmd.Output()
 	if err != nil {
-		fmt.Fprint(os.Stderr, string(out))
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGitStats, customerror.WithError(err))
+	}
 
+	if len(excluded) == 0 {
+		return string(out), nil
+	}
+
+	args = append(append([]string{"diff", "--numstat"}, revisions...), "--")
+
+	numstat, err := exec.Command("git", append(args, pathspecs(excluded)...)...).Output()
+	if err != nil {
 		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGitStats, customerror.WithError(err))
 	}
 
-	return string(out), nil
+	var b strings.Builder
+
+	b.Write(out)
+	b.WriteString("\n" + excludedStatsHeader + "\n")
+
+	for _, line := range strings.Split(strings.TrimSpace(string(numstat)), "\n") {
+		// Format: added, deleted, path. Binary files have "-" counts.
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		fmt.Fprintf(&b, " %s | +%s -%s\n", fields[2], fields[0], fields[1])
+	}
+
+	return b.String(), nil
+}
+
+// IndexSnapshot is the state of the index, and HEAD at some point, so it can
+// be restored.
+type IndexSnapshot struct {
+	// Head is the commit HEAD pointed to, empty if there were no commits.
+	Head string
+
+	// Tree is the tree object written from the index.
+	Tree string
+}
+
+// SnapshotIndex saves the index as a tree object, with 'git write-tree', and
+// records HEAD.
+func SnapshotIndex() (IndexSnapshot, error) {
+	tree, err := exec.Command("git", "write-tree").Output()
+	if err != nil {
+		return IndexSnapshot{}, errorcatalog.MustGet(errorcatalog.ErrFailedToSnapshotIndex, customerror.WithError(err))
+	}
+
+	snapshot := IndexSnapshot{Tree: strings.TrimSpace(string(tree))}
+
+	if HasCommits() {
+		head, err := exec.Command("git", "rev-parse", "HEAD").Output()
+		if err != nil {
+			return IndexSnapshot{}, errorcatalog.MustGet(errorcatalog.ErrFailedToSnapshotIndex, customerror.WithError(err))
+		}
+
+		snapshot.Head = strings.TrimSpace(string(head))
+	}
+
+	return snapshot, nil
+}
+
+// RestoreSnapshot moves HEAD back to the snapshot, undoing commits made since,
+// without touching the working tree, and restores the index.
+func RestoreSnapshot(snapshot IndexSnapshot) error {
+	var err error
+
+	if snapshot.Head != "" {
+		err = RunCommand(exec.Command("git", "reset", "--quiet", "--soft", snapshot.Head))
+	} else if HasCommits() {
+		// There were no commits, so the current branch must be unborn again.
+		err = RunCommand(exec.Command("git", "update-ref", "-d", "HEAD"))
+	}
+
+	if err == nil {
+		err = RunCommand(exec.Command("git", "read-tree", snapshot.Tree))
+	}
+
+	if err != nil {
+		return errorcatalog.MustGet(errorcatalog.ErrFailedToRestoreIndex, customerror.WithError(err))
+	}
+
+	return nil
 }
 
-// GitCommit commits staged changes with a provided commit message.
-// Uses 'git commit -m <message>' to perform a commit.
-func GitCommit(message string) error {
-	return RunCommand(exec.Command("git", "commit", "-m", message))
+// UnstageAll resets the index to HEAD, or empties it if there are no commits,
+// without touching the working tree.
+func UnstageAll() error {
+	if !HasCommits() {
+		return RunCommand(exec.Command("git", "read-tree", "--empty"))
+	}
+
+	return RunCommand(exec.Command("git", "read-tree", "HEAD"))
+}
+
+// StageFromTree stages the paths, relative to the root of the repository, as
+// they're in the tree object, e.g.: one of a snapshot. Paths missing from the
+// tree are removed from the index.
+func StageFromTree(tree string, paths []string) error {
+	args := append([]string{"restore", "--staged", "--source=" + tree, "--"}, pathspecs(paths)...)
+
+	return RunCommand(exec.Command("git", args...))
+}
+
+// GetCurrentBranch returns the name of the current branch, using
+// 'git rev-parse --abbrev-ref HEAD'. It's "HEAD" if detached.
+func GetCurrentBranch() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGitLog, customerror.WithError(err))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetRecentLog returns the subjects of the latest `count` commits, one per
+// line, using 'git log --oneline'. It's empty for repositories without
+// commits.
+func GetRecentLog(count int) (string, error) {
+	if !HasCommits() {
+		return "", nil
+	}
+
+	out, err := exec.Command("git", "log", "--oneline", "--no-decorate", fmt.Sprintf("-n%d", count)).Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGitLog, customerror.WithError(err))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HasCommits checks if the current branch has at least one commit, using
+// 'git rev-parse --verify HEAD'.
+func HasCommits() bool {
+	return exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD").Run() == nil
+}
+
+// GetDefaultBranch returns the default branch of the push remote, e.g.:
+// "origin/main", from its HEAD, otherwise the first of "main", or "master"
+// found locally.
+func GetDefaultBranch() (string, error) {
+	remote := GetPushRemote()
+
+	out, err := exec.Command("git", "symbolic-ref", "--quiet", "--short", "refs/remotes/"+remote+"/HEAD").Output()
+	if err == nil {
+		return strings.TrimSpace(string(out)), nil
+	}
+
+	for _, branch := range []string{"main", "master"} {
+		if exec.Command("git", "rev-parse", "--verify", "--quiet", branch).Run() == nil {
+			return branch, nil
+		}
+	}
+
+	return "", errorcatalog.MustGet(
+		errorcatalog.ErrFailedToGitLog,
+		customerror.WithError(fmt.Errorf("no default branch found, set the base branch")),
+	)
+}
+
+// GetMergeBase returns the best common ancestor of the ref, and HEAD, using
+// 'git merge-base <ref> HEAD'.
+func GetMergeBase(ref string) (string, error) {
+	out, err := exec.Command("git", "merge-base", ref, "HEAD").Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGitLog, customerror.WithError(fmt.Errorf("%s: %w", ref, err)))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GitCommit commits staged changes with a provided commit message, signed
+// as set. Uses 'git commit -m <message>' to perform a commit.
+func GitCommit(message string, signing Signing) error {
+	return commit(signing, "-m", message)
+}
+
+// GitCommitAmend replaces HEAD with a commit of the staged changes, and the
+// changes of HEAD, with the message, signed as set. Uses
+// 'git commit --amend -m <message>'.
+func GitCommitAmend(message string, signing Signing) error {
+	return commit(signing, "--amend", "-m", message)
+}
+
+// commit runs 'git commit' with the arguments, signed as set. Signing
+// failures are reported with a dedicated error.
+func commit(signing Signing, args ...string) error {
+	args = append([]string{"commit"}, args...)
+
+	if signing.Sign {
+		args = append(args, "--gpg-sign")
+	}
+
+	if err := RunCommand(exec.Command("git", signing.args(args...)...)); err != nil {
+		if isSigningFailure(err) && signing.SignsCommits() {
+			return signingError(errorcatalog.ErrFailedToSignCommit, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// GetCommitTemplate returns the content of the commit template, set by
+// commit.template, empty if there's none. Relative paths are relative to the
+// root of the repository, as for 'git commit'.
+func GetCommitTemplate() (string, error) {
+	// Exits with 1 if unset.
+	out, err := exec.Command("git", "config", "--path", "--get", "commit.template").Output()
+	if err != nil {
+		return "", nil
+	}
+
+	path := strings.TrimSpace(string(out))
+	if path == "" {
+		return "", nil
+	}
+
+	if !filepath.IsAbs(path) {
+		root, err := GetRepoRoot()
+		if err != nil {
+			return "", err
+		}
+
+		path = filepath.Join(root, path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToReadCommitTemplate, customerror.WithError(err))
+	}
+
+	return strings.TrimSpace(string(content)), nil
+}
+
+// GetIdentity returns the committer, as "Name <email>", using
+// 'git var GIT_COMMITTER_IDENT', so env vars, e.g.: GIT_COMMITTER_NAME, are
+// honored.
+func GetIdentity() (string, error) {
+	out, err := exec.Command("git", "var", "GIT_COMMITTER_IDENT").Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGetIdentity, customerror.WithError(err))
+	}
+
+	// The identity is followed by the timestamp, and the timezone.
+	identity, _, _ := strings.Cut(strings.TrimSpace(string(out)), "> ")
+
+	return strings.TrimSuffix(identity, ">") + ">", nil
+}
+
+// GetAuthors returns the authors of the current branch, as "Name <email>",
+// the most frequent first, using 'git shortlog -sne HEAD'. It's empty for
+// repositories without commits.
+func GetAuthors() ([]string, error) {
+	if !HasCommits() {
+		return []string{}, nil
+	}
+
+	out, err := exec.Command("git", "shortlog", "-sne", "HEAD").Output()
+	if err != nil {
+		return nil, errorcatalog.MustGet(errorcatalog.ErrFailedToGitLog, customerror.WithError(err))
+	}
+
+	authors := []string{}
+
+	// Lines are the count of commits, a tab, and the author.
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if _, author, found := strings.Cut(line, "\t"); found {
+			authors = append(authors, strings.TrimSpace(author))
+		}
+	}
+
+	return authors, nil
+}
+
+// AddTrailers adds the trailers, e.g.: "Signed-off-by: Name <email>", to the
+// message, using 'git interpret-trailers', so they're formatted like git
+// does, and the ones already in the message aren't added again.
+func AddTrailers(message string, trailers []string) (string, error) {
+	if len(trailers) == 0 {
+		return message, nil
+	}
+
+	// Bodies may contain "---", which isn't the start of a patch here.
+	args := []string{"interpret-trailers", "--no-divider", "--if-exists", "addIfDifferent"}
+
+	for _, trailer := range trailers {
+		args = append(args, "--trailer", trailer)
+	}
+
+	var stdout bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = strings.NewReader(message + "\n")
+	cmd.Stdout = &stdout
+
+	if err := RunCommand(cmd); err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToAddTrailers, customerror.WithError(err))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// ResolveCommit returns the hash of the commit the ref points to, using
+// 'git rev-parse --verify <ref>^{commit}'.
+func ResolveCommit(ref string) (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(
+			errorcatalog.ErrInvalidRevisionRange,
+			customerror.WithField("ref", ref),
+		).NewInvalidError()
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// GetUpstream returns the upstream branch of the current branch, e.g.:
+// "origin/main", or "" if there's none, using
+// 'git rev-parse --abbrev-ref @{upstream}'.
+func GetUpstream() string {
+	out, err := exec.Command("git", "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// GetRemoteBranches returns the remote branches containing the commit, e.g.:
+// "origin/feature", using 'git branch -r --contains'. Symbolic refs, e.g.:
+// "origin/HEAD", are left out.
+func GetRemoteBranches(commit string) ([]string, error) {
+	out, err := exec.Command(
+		"git", "branch", "-r", "--contains", commit,
+		"--format=%(if)%(symref)%(then)%(else)%(refname:short)%(end)",
+	).Output()
+	if err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrFailedToGitLog,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}
+
+	branches := []string{}
+
+	for _, branch := range strings.Split(string(out), "\n") {
+		if branch = strings.TrimSpace(branch); branch != "" {
+			branches = append(branches, branch)
+		}
+	}
+
+	return branches, nil
+}
+
+// IsAncestor checks if the commit is reachable from the ref, e.g.: it's
+// already on the upstream branch, using 'git merge-base --is-ancestor'.
+func IsAncestor(commit, ref string) bool {
+	return exec.Command("git", "merge-base", "--is-ancestor", commit, ref).Run() == nil
+}
+
+// RewordCommits rewrites the commits reachable from HEAD, but not from
+// `from`, replacing the messages of the ones in `messages`, keyed by hash.
+// Trees, authors, and author dates are kept, so the working tree, and the
+// index are left untouched. Commits before the first reworded one keep their
+// hash. Uses 'git commit-tree', and moves the current branch with
+// 'git update-ref'. Rewritten commits are signed as set, or if
+// commit.gpgSign is on, which 'git commit-tree' ignores. It returns the
+// previous HEAD, so it can be restored.
+func RewordCommits(from string, messages map[string]string, signing Signing) (string, error) {
+	failed := func(err error) error {
+		return errorcatalog.MustGet(errorcatalog.ErrFailedToRewordCommits, customerror.WithError(err)).NewFailedToError()
+	}
+
+	head, err := ResolveCommit("HEAD")
+	if err != nil {
+		return "", failed(err)
+	}
+
+	// Parents are rewritten before their children. Fields are NUL separated.
+	out, err := exec.Command(
+		"git", "log", "--reverse", "--topo-order", "--date=raw",
+		"--format=%H%x00%T%x00%P%x00%an%x00%ae%x00%ad", from+"..HEAD", "--",
+	).Output()
+	if err != nil {
+		return "", failed(err)
+	}
+
+	rewritten := map[string]string{}
+	newHead := head
+
+	signArgs := []string{}
+	if signing.SignsCommits() {
+		signArgs = append(signArgs, "--gpg-sign")
+	}
+
+	for _, record := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(record, "\x00")
+		if len(fields) != 6 {
+			continue
+		}
+
+		hash, tree := fields[0], fields[1]
+
+		parents, changed := []string{}, false
+
+		for _, parent := range strings.Fields(fields[2]) {
+			if p, ok := rewritten[parent]; ok {
+				parent, changed = p, true
+			}
+
+			parents = append(parents, "-p", parent)
+		}
+
+		message, reworded := messages[hash]
+
+		if !reworded && !changed {
+			newHead = hash
+
+			continue
+		}
+
+		if !reworded {
+			original, err := exec.Command("git", "log", "-1", "--format=%B", hash, "--").Output()
+			if err != nil {
+				return "", failed(err)
+			}
+
+			message = string(original)
+		}
+
+		var stdout bytes.Buffer
+
+		args := slices.Concat([]string{"commit-tree", tree}, parents, signArgs, []string{"-F", "-"})
+
+		cmd := exec.Command("git", signing.args(args...)...)
+		cmd.Stdin = strings.NewReader(message)
+		cmd.Stdout = &stdout
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME="+fields[3],
+			"GIT_AUTHOR_EMAIL="+fields[4],
+			"GIT_AUTHOR_DATE="+fields[5],
+		)
+
+		if err := RunCommand(cmd); err != nil {
+			if len(signArgs) > 0 && isSigningFailure(err) {
+				return "", signingError(errorcatalog.ErrFailedToSignCommit, err)
+			}
+
+			return "", failed(err)
+		}
+
+		rewritten[hash] = strings.TrimSpace(stdout.String())
+		newHead = rewritten[hash]
+	}
+
+	if newHead == head {
+		return head, nil
+	}
+
+	if err := RunCommand(exec.Command("git", "update-ref", "-m", "committer: reword", "HEAD", newHead, head)); err != nil {
+		return "", failed(err)
+	}
+
+	return head, nil
 }
 
 // GitPush pushes commits to the remote repository.
@@ -117,16 +861,56 @@ func GitPush() error {
 	return RunCommand(exec.Command("git", "push"))
 }
 
-// GitTag creates a new tag on the latest commit with the specified tag name.
-// Uses 'git tag <tag>' to attach a tag to the current commit.
-func GitTag(tag string) error {
-	return RunCommand(exec.Command("git", "tag", tag))
+// GitTag creates an annotated tag on the latest commit, with the message,
+// signed as set, or if tag.gpgSign is on. Uses 'git tag -a|-s <tag> -F -'.
+// Whitespace is cleaned up, but lines starting with "#" are kept, e.g.:
+// Markdown headings. Signing failures are reported with a dedicated error.
+func GitTag(tag, message string, signing Signing) error {
+	mode := "-a"
+	if signing.Sign {
+		mode = "-s"
+	}
+
+	cmd := exec.Command("git", signing.args("tag", mode, "--cleanup=whitespace", "-F", "-", tag)...)
+	cmd.Stdin = strings.NewReader(message)
+
+	if err := RunCommand(cmd); err != nil {
+		if isSigningFailure(err) && signing.SignsTags() {
+			return signingError(errorcatalog.ErrFailedToSignTag, err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// GetPushRemote returns the remote commits of the current branch are pushed
+// to: branch.<name>.pushRemote, remote.pushDefault, branch.<name>.remote, or
+// "origin", as git does.
+func GetPushRemote() string {
+	branch, _ := GetCurrentBranch()
+
+	for _, key := range []string{
+		"branch." + branch + ".pushRemote",
+		"remote.pushDefault",
+		"branch." + branch + ".remote",
+	} {
+		// Exits with 1 if unset.
+		if out, err := exec.Command("git", "config", "--get", key).Output(); err == nil {
+			if remote := strings.TrimSpace(string(out)); remote != "" {
+				return remote
+			}
+		}
+	}
+
+	return "origin"
 }
 
-// GitPushTags pushes tags to the remote repository.
-// Executes 'git push --tags' to push all tags to the remote.
-func GitPushTags() error {
-	return RunCommand(exec.Command("git", "push", "--tags"))
+// GitPushTag pushes only the tag to the push remote, using
+// 'git push <remote> refs/tags/<tag>', so other local tags don't leak.
+func GitPushTag(tag string) error {
+	return RunCommand(exec.Command("git", "push", GetPushRemote(), "refs/tags/"+tag))
 }
 
 // GitFetchTags fetches tags from the remote repository.
@@ -136,7 +920,8 @@ func GitFetchTags() error {
 }
 
 // GitGetLatestTags retrieves the latest tags sorted by version (descending).
-// Uses 'git tag --sort=-version:refname' and returns up to `count` tags.
+// Uses 'git tag --sort=-version:refname' and returns up to `count` tags, all
+// of them if `count` isn't positive.
 func GitGetLatestTags(count int) ([]string, error) {
 	cmd := exec.Command("git", "tag", "--sort=-version:refname")
 
@@ -152,25 +937,163 @@ func GitGetLatestTags(count int) ([]string, error) {
 
 	allTags := strings.Split(raw, "\n")
 
-	if len(allTags) > count {
+	if count > 0 && len(allTags) > count {
 		allTags = allTags[:count]
 	}
 
 	return allTags, nil
 }
 
-// RunCommand executes a given command and outputs its standard error content to
-// os.Stderr if the command fails. This is a helper function to reduce repetition
-// of error handling logic.
+// Commit is a commit of the history.
+type Commit struct {
+	// Hash of the commit.
+	Hash string
+
+	// Message of the commit.
+	Message string
+}
+
+// GetCommitsSince returns the commits reachable from HEAD, but not from the
+// ref, oldest first. All commits are returned if the ref is empty.
+func GetCommitsSince(ref string) ([]Commit, error) {
+	if !HasCommits() {
+		return nil, nil
+	}
+
+	return GetCommits(ref, "HEAD")
+}
+
+// GetCommits returns the commits reachable from `to`, but not from `from`,
+// oldest first, using 'git log --reverse <from>..<to>'. All commits up to
+// `to` are returned if `from` is empty.
+func GetCommits(from, to string) ([]Commit, error) {
+	rangeSpec := to
+	if from != "" {
+		rangeSpec = from + ".." + to
+	}
+
+	return logCommits("--reverse", rangeSpec, "--")
+}
+
+// GetRecentCommits returns the latest `count` commits, latest first, without
+// merges. If paths are given, relative to the root of the repository, only
+// the commits changing them are returned, using 'git log -- <paths>'. It's
+// empty for repositories without commits.
+func GetRecentCommits(count int, paths ...string) ([]Commit, error) {
+	if count <= 0 || !HasCommits() {
+		return nil, nil
+	}
+
+	return logCommits(append([]string{fmt.Sprintf("-n%d", count), "--no-merges", "HEAD", "--"}, pathspecs(paths)...)...)
+}
+
+// logCommits returns the commits listed by 'git log' with the arguments.
+func logCommits(args ...string) ([]Commit, error) {
+	// Records are NUL terminated, the hash is separated by a newline.
+	out, err := exec.Command("git", append([]string{"log", "--format=%H%n%B%x00"}, args...)...).Output()
+	if err != nil {
+		return nil, errorcatalog.MustGet(errorcatalog.ErrFailedToGitLog, customerror.WithError(err))
+	}
+
+	commits := []Commit{}
+
+	for _, record := range splitNull(strings.TrimSpace(string(out))) {
+		hash, message, _ := strings.Cut(strings.TrimLeft(record, "\n"), "\n")
+
+		commits = append(commits, Commit{Hash: hash, Message: strings.TrimSpace(message)})
+	}
+
+	return commits, nil
+}
+
+// GetCommitDate returns the date of the commit the ref points to, e.g.:
+// "2024-01-01", using 'git log -1 --format=%cd --date=short'.
+func GetCommitDate(ref string) (string, error) {
+	out, err := exec.Command("git", "log", "-1", "--format=%cd", "--date=short", ref, "--").Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGitLog, customerror.WithError(err))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// IsTag checks if the ref is a tag, using 'git show-ref --verify'.
+func IsTag(ref string) bool {
+	return exec.Command("git", "show-ref", "--verify", "--quiet", "refs/tags/"+ref).Run() == nil
+}
+
+// parentOf returns the first parent of the commit, e.g.: "HEAD~1", or the
+// empty tree if it's a root commit, so its diff has all of its files.
+func parentOf(commit string) (string, error) {
+	if exec.Command("git", "rev-parse", "--verify", "--quiet", commit+"~1").Run() == nil {
+		return commit + "~1", nil
+	}
+
+	cmd := exec.Command("git", "hash-object", "-t", "tree", "--stdin")
+	cmd.Stdin = strings.NewReader("")
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errorcatalog.MustGet(errorcatalog.ErrFailedToGitDiff, customerror.WithError(err))
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// pathspecs converts paths, relative to the root of the repository, to
+// literal pathspecs, so they work from any directory.
+func pathspecs(paths []string) []string {
+	specs := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		specs = append(specs, ":(top,literal)"+path)
+	}
+
+	return specs
+}
+
+// intersect returns the values of a also in b, in order.
+func intersect(a, b []string) []string {
+	inB := map[string]bool{}
+
+	for _, v := range b {
+		inB[v] = true
+	}
+
+	result := []string{}
+
+	for _, v := range a {
+		if inB[v] {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
+
+// splitNull splits NUL separated output, dropping the trailing terminator.
+func splitNull(out string) []string {
+	out = strings.TrimSuffix(out, "\x00")
+	if out == "" {
+		return nil
+	}
+
+	return strings.Split(out, "\x00")
+}
+
+// RunCommand executes a given command. If it fails, its standard error is
+// part of the returned error, instead of being printed, so callers decide how
+// to report it. This is a helper function to reduce repetition of error
+// handling logic.
 func RunCommand(cmd *exec.Cmd) error {
 	var stderr bytes.Buffer
 
 	cmd.Stderr = &stderr
 
-	// If the command fails, print the standard error to the console and return
-	// the error.
 	if err := cmd.Run(); err != nil {
-		fmt.Fprint(os.Stderr, stderr.String())
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			return fmt.Errorf("%w: %s", err, output)
+		}
 
 		return err
 	}
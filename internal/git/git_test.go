@@ -1,9 +1,15 @@
 package git
 
 import (
+	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/thalesfsp/committer/internal/gitdiff"
+	"github.com/thalesfsp/committer/internal/pathfilter"
 )
 
 // createTestCommand is a helper to create exec.Cmd for tests.
@@ -56,3 +62,614 @@ func TestRunCommand_FailingCommand(t *testing.T) {
 		t.Error("expected error from invalid git command, got nil")
 	}
 }
+
+// TestGetGitDiff_Filter verifies excluded, and generated files are left out
+// of the diff, but still listed in the stats.
+func TestGetGitDiff_Filter(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Chdir(dir)
+
+	files := map[string]string{
+		".gitattributes": "gen/** linguist-generated\n",
+		"main.go":        "package main\n",
+		"go.sum":         "example.com/x v1.0.0 h1:abc=\n",
+		"gen/api.go":     "package gen\n",
+		"sub/keep.lock":  "kept\n",
+	}
+
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{{"init", "-q"}, {"add", "."}} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	// Runs from a subdirectory, paths are still relative to the root.
+	t.Chdir(filepath.Join(dir, "sub"))
+
+	diff, excluded, err := GetGitDiff(pathfilter.New([]string{"keep.lock"}, nil, true))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(excluded, ",") != "gen/api.go,go.sum" {
+		t.Errorf("unexpected excluded files: %v", excluded)
+	}
+
+	if !strings.Contains(diff, "main.go") || !strings.Contains(diff, "sub/keep.lock") ||
+		strings.Contains(diff, "go.sum") || strings.Contains(diff, "gen/api.go") {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+
+	stats, err := GetGitStats(excluded)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(stats, " go.sum | +1 -0") || !strings.Contains(stats, " gen/api.go | +1 -0") {
+		t.Errorf("expected excluded files in stats:\n%s", stats)
+	}
+}
+
+// TestSnapshotIndex verifies files are staged from a snapshot, and commits
+// made since are undone on restore.
+func TestSnapshotIndex(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	run := func(args ...string) string {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+
+		return strings.TrimSpace(string(out))
+	}
+
+	run("init", "-q")
+	run("config", "commit.gpgsign", "false")
+
+	for _, f := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(f, []byte(f), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("add", ".")
+
+	snapshot, err := SnapshotIndex()
+	if err != nil || snapshot.Head != "" {
+		t.Fatalf("unexpected snapshot: %+v, %v", snapshot, err)
+	}
+
+	if err := UnstageAll(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := StageFromTree(snapshot.Tree, []string{"a.txt"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if staged := run("diff", "--staged", "--name-only"); staged != "a.txt" {
+		t.Errorf("expected only a.txt to be staged, got %q", staged)
+	}
+
+	if err := GitCommit("first", Signing{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := RestoreSnapshot(snapshot); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if HasCommits() {
+		t.Error("expected the commit to be undone")
+	}
+
+	if staged := run("diff", "--staged", "--name-only"); staged != "a.txt\nb.txt" {
+		t.Errorf("expected the index to be restored, got %q", staged)
+	}
+}
+
+// TestApplyCached verifies a single hunk of the unstaged changes is staged.
+func TestApplyCached(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	run := func(args ...string) string {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+
+		return string(out)
+	}
+
+	lines := []string{}
+
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+
+	if err := os.WriteFile("file.txt", []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run("init", "-q")
+	run("add", ".")
+
+	// Change the first, and the last lines: two hunks.
+	lines[0], lines[19] = "first", "last"
+
+	if err := os.WriteFile("file.txt", []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile("new.txt", []byte("new\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := GetUnstagedDiff()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files := gitdiff.Parse(diff)
+	if len(files) != 1 || len(files[0].Hunks) != 2 {
+		t.Fatalf("unexpected diff:\n%s", diff)
+	}
+
+	// Stage the second hunk only.
+	files[0].Hunks = files[0].Hunks[1:]
+
+	if err := ApplyCached(files[0].String()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	untracked, err := GetUntrackedFiles()
+	if err != nil || len(untracked) != 1 || untracked[0] != "new.txt" {
+		t.Fatalf("unexpected untracked files: %v, %v", untracked, err)
+	}
+
+	if err := StageFiles(untracked); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	staged := run("diff", "--staged")
+
+	if !strings.Contains(staged, "+last") || strings.Contains(staged, "+first") || !strings.Contains(staged, "+new") {
+		t.Errorf("unexpected staged changes:\n%s", staged)
+	}
+}
+
+// TestGetHooksDir verifies core.hooksPath is respected, relative to the root
+// of the repository.
+func TestGetHooksDir(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Chdir(dir)
+
+	if out, err := exec.Command("git", "init", "-q").CombinedOutput(); err != nil {
+		t.Skipf("git init: %v: %s", err, out)
+	}
+
+	// Symlinks, e.g.: /tmp on macOS.
+	root, err := GetRepoRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir("sub", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Chdir(filepath.Join(dir, "sub"))
+
+	hooksDir, err := GetHooksDir()
+	if err != nil || hooksDir != filepath.Join(root, ".git", "hooks") {
+		t.Errorf("unexpected hooks directory: %q, %v", hooksDir, err)
+	}
+
+	if out, err := exec.Command("git", "config", "core.hooksPath", ".githooks").CombinedOutput(); err != nil {
+		t.Fatalf("git config: %v: %s", err, out)
+	}
+
+	hooksDir, err = GetHooksDir()
+	if err != nil || hooksDir != filepath.Join(root, ".githooks") {
+		t.Errorf("unexpected hooks directory: %q, %v", hooksDir, err)
+	}
+}
+
+// TestGetCommitsSince verifies commits since a tag are returned, oldest
+// first, with their full message.
+func TestGetCommitsSince(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	run := func(args ...string) {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	run("init", "-q")
+	run("config", "commit.gpgsign", "false")
+	run("commit", "-q", "--allow-empty", "-m", "chore: init")
+	run("tag", "v1.0.0")
+	run("commit", "-q", "--allow-empty", "-m", "fix: handle nil\n\nDetails.")
+	run("commit", "-q", "--allow-empty", "-m", "feat: add export")
+
+	commits, err := GetCommitsSince("v1.0.0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(commits) != 2 || commits[0].Message != "fix: handle nil\n\nDetails." || commits[1].Message != "feat: add export" || len(commits[0].Hash) < 40 {
+		t.Errorf("unexpected commits: %+v", commits)
+	}
+
+	if commits, err = GetCommitsSince(""); err != nil || len(commits) != 3 {
+		t.Errorf("unexpected commits: %+v, %v", commits, err)
+	}
+
+	if commits, err = GetCommits("", "v1.0.0"); err != nil || len(commits) != 1 || commits[0].Message != "chore: init" {
+		t.Errorf("unexpected commits: %+v, %v", commits, err)
+	}
+
+	if !IsTag("v1.0.0") || IsTag("HEAD") {
+		t.Error("unexpected tag detection")
+	}
+
+	if date, err := GetCommitDate("v1.0.0"); err != nil || len(date) != len("2024-01-01") {
+		t.Errorf("unexpected date: %q, %v", date, err)
+	}
+}
+
+// TestGetRecentCommits verifies the latest commits are returned, latest
+// first, only the ones changing the paths, if given.
+func TestGetRecentCommits(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	run := func(args ...string) {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	if commits, err := GetRecentCommits(5); err != nil || len(commits) != 0 {
+		t.Errorf("outside of a repository, there must be no commits: %+v, %v", commits, err)
+	}
+
+	run("init", "-q")
+	run("config", "commit.gpgsign", "false")
+
+	for _, path := range []string{"api/a.go", "docs/b.md", "api/c.go"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(path), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		run("add", path)
+		run("commit", "-q", "-m", "feat: add "+path+"\n\nBody.")
+	}
+
+	commits, err := GetRecentCommits(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(commits) != 2 || commits[0].Message != "feat: add api/c.go\n\nBody." || commits[1].Message != "feat: add docs/b.md\n\nBody." {
+		t.Errorf("unexpected commits: %+v", commits)
+	}
+
+	if commits, err = GetRecentCommits(5, "api/a.go", "api/c.go"); err != nil || len(commits) != 2 || commits[1].Message != "feat: add api/a.go\n\nBody." {
+		t.Errorf("unexpected commits: %+v, %v", commits, err)
+	}
+}
+
+// TestAddTrailers verifies trailers join the existing ones, without
+// duplicates, and the committer, and authors are returned as "Name <email>".
+func TestAddTrailers(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	run := func(args ...string) {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	run("init", "-q")
+	run("config", "commit.gpgsign", "false")
+	run("commit", "-q", "--allow-empty", "-m", "feat: init")
+
+	identity, err := GetIdentity()
+	if err != nil || identity != "Test <test@example.com>" {
+		t.Errorf("identity = %q, %v", identity, err)
+	}
+
+	if authors, err := GetAuthors(); err != nil || strings.Join(authors, ",") != "Test <test@example.com>" {
+		t.Errorf("authors = %v, %v", authors, err)
+	}
+
+	message, err := AddTrailers(
+		"feat: add a\n\nBody.\n---\nMore body.\n\nRefs: PAY-1",
+		[]string{"Refs: PAY-1", "Co-authored-by: Jane <jane@example.com>", "Signed-off-by: " + identity},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "feat: add a\n\nBody.\n---\nMore body.\n\nRefs: PAY-1\nCo-authored-by: Jane <jane@example.com>\nSigned-off-by: Test <test@example.com>"
+	if message != want {
+		t.Errorf("message = %q, want %q", message, want)
+	}
+
+	if message, _ := AddTrailers("fix: b", []string{"Signed-off-by: " + identity}); message != "fix: b\n\nSigned-off-by: Test <test@example.com>" {
+		t.Errorf("message = %q", message)
+	}
+}
+
+// TestGitTag verifies annotated tags keep Markdown headings, and only the new
+// tag is pushed.
+func TestGitTag(t *testing.T) {
+	dir := t.TempDir()
+	remote := filepath.Join(dir, "remote.git")
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	run := func(args ...string) string {
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+
+		return strings.TrimSpace(string(out))
+	}
+
+	run("init", "-q", "--bare", remote)
+
+	t.Chdir(dir)
+
+	run("init", "-q", "local")
+
+	t.Chdir(filepath.Join(dir, "local"))
+
+	run("config", "commit.gpgsign", "false")
+	run("config", "tag.gpgsign", "false")
+	run("remote", "add", "upstream", remote)
+	run("config", "remote.pushDefault", "upstream")
+	run("commit", "-q", "--allow-empty", "-m", "chore: init")
+	run("tag", "stale")
+
+	if remote := GetPushRemote(); remote != "upstream" {
+		t.Errorf("expected upstream, got %q", remote)
+	}
+
+	if err := GitTag("v1.0.0", "Release v1.0.0\n\n## Features\n\n- add export\n", Signing{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if message := run("tag", "-l", "--format=%(contents)", "v1.0.0"); message != "Release v1.0.0\n\n## Features\n\n- add export" {
+		t.Errorf("unexpected message: %q", message)
+	}
+
+	if err := GitPushTag("v1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tags := run("--git-dir", remote, "tag", "-l"); tags != "v1.0.0" {
+		t.Errorf("expected only the new tag to be pushed, got %q", tags)
+	}
+}
+
+// TestGetRangeDiff verifies the differences of a branch since its merge base
+// with the default branch are retrieved, without excluded files.
+func TestGetRangeDiff(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	run := func(args ...string) {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	run("init", "-q", "-b", "main")
+	run("config", "commit.gpgsign", "false")
+	run("commit", "-q", "--allow-empty", "-m", "chore: init")
+	run("checkout", "-q", "-b", "feature")
+
+	for _, f := range []string{"main.go", "go.sum"} {
+		if err := os.WriteFile(f, []byte(f+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("add", ".")
+	run("commit", "-q", "-m", "feat: add main")
+
+	base, err := GetDefaultBranch()
+	if err != nil || base != "main" {
+		t.Fatalf("unexpected default branch: %q, %v", base, err)
+	}
+
+	mergeBase, err := GetMergeBase(base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	diff, excluded, err := GetRangeDiff(pathfilter.New(nil, nil, true), mergeBase, "HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(diff, "+main.go") || strings.Contains(diff, "go.sum") || len(excluded) != 1 || excluded[0] != "go.sum" {
+		t.Errorf("unexpected diff: %q, excluded: %v", diff, excluded)
+	}
+
+	stats, err := GetRangeStats(excluded, mergeBase, "HEAD")
+	if err != nil || !strings.Contains(stats, "main.go") || !strings.Contains(stats, " go.sum | +1 -0") {
+		t.Errorf("unexpected stats: %q, %v", stats, err)
+	}
+}
+
+func TestRewordCommits(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	t.Setenv("GIT_AUTHOR_NAME", "Author")
+	t.Setenv("GIT_AUTHOR_EMAIL", "author@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	run := func(args ...string) {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	run("init", "-q", "-b", "main")
+	run("config", "commit.gpgsign", "false")
+
+	for _, f := range []string{"a", "b", "c"} {
+		if err := os.WriteFile(f, []byte(f+"\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		run("add", f)
+		run("commit", "-q", "-m", "wip "+f)
+	}
+
+	// The root commit has all of its files.
+	diff, _, err := GetCommitDiff(pathfilter.New(nil, nil, true), "HEAD~2")
+	if err != nil || !strings.Contains(diff, "+a") {
+		t.Fatalf("unexpected diff of the root commit: %q, %v", diff, err)
+	}
+
+	if err := os.WriteFile("d", []byte("d\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run("add", "d")
+
+	// The amended commit has the changes of HEAD, and the staged ones.
+	diff, _, err = GetAmendDiff(pathfilter.New(nil, nil, true))
+	if err != nil || !strings.Contains(diff, "+c") || !strings.Contains(diff, "+d") || strings.Contains(diff, "+b") {
+		t.Fatalf("unexpected amend diff: %q, %v", diff, err)
+	}
+
+	commits, err := GetCommits("HEAD~2", "HEAD")
+	if err != nil || len(commits) != 2 {
+		t.Fatalf("unexpected commits: %v, %v", commits, err)
+	}
+
+	first, _ := ResolveCommit("HEAD~2")
+
+	t.Setenv("GIT_AUTHOR_NAME", "Someone Else")
+
+	previous, err := RewordCommits("HEAD~2", map[string]string{commits[0].Hash: "feat: add b\n"}, Signing{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if previous != commits[1].Hash || IsAncestor(previous, "HEAD") {
+		t.Errorf("unexpected previous HEAD: %q", previous)
+	}
+
+	reworded, err := GetCommits("", "HEAD")
+	if err != nil || len(reworded) != 3 {
+		t.Fatalf("unexpected commits: %v, %v", reworded, err)
+	}
+
+	if reworded[0].Hash != first {
+		t.Errorf("commits before the reworded one must keep their hash")
+	}
+
+	if reworded[1].Message != "feat: add b" || reworded[2].Message != "wip c" {
+		t.Errorf("unexpected messages: %q, %q", reworded[1].Message, reworded[2].Message)
+	}
+
+	author, err := exec.Command("git", "log", "-1", "--format=%an").Output()
+	if err != nil || strings.TrimSpace(string(author)) != "Author" {
+		t.Errorf("the author must be kept, got %q, %v", author, err)
+	}
+
+	// The staged changes are left untouched.
+	if files, err := GetStagedFiles(); err != nil || len(files) != 1 || files[0] != "d" {
+		t.Errorf("unexpected staged files: %v, %v", files, err)
+	}
+}
+
+// TestGetRemoteBranches verifies commits on remote branches are found without
+// upstream branch, leaving symbolic refs out.
+func TestGetRemoteBranches(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	run := func(args ...string) {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	run("init", "-q", "-b", "main")
+	run("config", "commit.gpgsign", "false")
+	run("commit", "-q", "--allow-empty", "-m", "feat: a")
+	run("commit", "-q", "--allow-empty", "-m", "feat: b")
+
+	// As if HEAD~1 was pushed to another branch, without -u.
+	run("update-ref", "refs/remotes/origin/feature", "HEAD~1")
+	run("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/feature")
+
+	if upstream := GetUpstream(); upstream != "" {
+		t.Fatalf("unexpected upstream branch: %q", upstream)
+	}
+
+	if branches, err := GetRemoteBranches("HEAD~1"); err != nil || len(branches) != 1 || branches[0] != "origin/feature" {
+		t.Errorf("unexpected remote branches: %v, %v", branches, err)
+	}
+
+	if branches, err := GetRemoteBranches("HEAD"); err != nil || len(branches) != 0 {
+		t.Errorf("unexpected remote branches: %v, %v", branches, err)
+	}
+}
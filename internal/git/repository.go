@@ -0,0 +1,453 @@
+package git
+
+import "github.com/thalesfsp/committer/internal/pathfilter"
+
+//////
+// Const, vars, types.
+//////
+
+// Repository is a Git repository: its status, the staged diff, and stats,
+// staging, committing, history, rewording, tags, hooks, and pushing. Exec
+// runs git, Fake keeps everything in memory, for unit tests. Paths are
+// relative to the root of the repository.
+type Repository interface {
+	// IsRepo checks if the repository exists.
+	IsRepo() bool
+
+	// Root returns the absolute path of the top-level directory.
+	Root() (string, error)
+
+	// HooksDir returns the absolute path of the hooks directory, see
+	// GetHooksDir.
+	HooksDir() (string, error)
+
+	// IsDirty checks if there are unstaged changes.
+	IsDirty() bool
+
+	// HasStagedChanges checks if there are staged, but not committed changes.
+	HasStagedChanges() bool
+
+	// HasCommits checks if the current branch has at least one commit.
+	HasCommits() bool
+
+	// CurrentBranch returns the name of the current branch.
+	CurrentBranch() (string, error)
+
+	// UnstagedDiff returns the differences between the working tree, and the
+	// index, see GetUnstagedDiff.
+	UnstagedDiff() (string, error)
+
+	// UntrackedFiles returns the paths of the untracked, not ignored files.
+	UntrackedFiles() ([]string, error)
+
+	// StagedFiles returns the paths of the staged files.
+	StagedFiles() ([]string, error)
+
+	// AddAll stages all changes.
+	AddAll() error
+
+	// StageFiles stages the files.
+	StageFiles(paths []string) error
+
+	// ApplyCached stages the patch.
+	ApplyCached(patch string) error
+
+	// Snapshot saves the index, and HEAD, so they can be restored.
+	Snapshot() (IndexSnapshot, error)
+
+	// RestoreSnapshot moves HEAD back to the snapshot, undoing commits made
+	// since, and restores the index, without touching the working tree.
+	RestoreSnapshot(snapshot IndexSnapshot) error
+
+	// UnstageAll resets the index to HEAD, without touching the working
+	// tree.
+	UnstageAll() error
+
+	// StageFromTree stages the paths as they're in the tree, e.g.: the one
+	// of a snapshot, removing the ones missing from it.
+	StageFromTree(tree string, paths []string) error
+
+	// Diff returns the staged differences, without the excluded files, and
+	// the excluded files, see GetGitDiff.
+	Diff(filter *pathfilter.Filter, paths ...string) (string, []string, error)
+
+	// AmendDiff is like Diff, but returns the differences the amended HEAD
+	// would have.
+	AmendDiff(filter *pathfilter.Filter) (string, []string, error)
+
+	// CommitDiff is like Diff, but returns the differences introduced by the
+	// commit.
+	CommitDiff(filter *pathfilter.Filter, commit string) (string, []string, error)
+
+	// RangeDiff is like Diff, but returns the differences between two
+	// commits.
+	RangeDiff(filter *pathfilter.Filter, from, to string) (string, []string, error)
+
+	// Stats returns the statistics of the staged changes, see GetGitStats.
+	Stats(excluded []string, paths ...string) (string, error)
+
+	// AmendStats is like Stats, but returns the statistics of the differences
+	// the amended HEAD would have.
+	AmendStats(excluded []string) (string, error)
+
+	// CommitStats is like Stats, but returns the statistics of the
+	// differences introduced by the commit.
+	CommitStats(excluded []string, commit string) (string, error)
+
+	// RangeStats is like Stats, but returns the statistics of the
+	// differences between two commits.
+	RangeStats(excluded []string, from, to string) (string, error)
+
+	// Commit commits the staged changes with the message, signed as set.
+	Commit(message string, signing Signing) error
+
+	// CommitAmend replaces HEAD with a commit of its changes, and the staged
+	// ones, with the message, signed as set.
+	CommitAmend(message string, signing Signing) error
+
+	// RewordCommits replaces the messages of the commits after `from`, keyed
+	// by hash, keeping their changes, signed as set. It returns the previous
+	// HEAD, see RewordCommits.
+	RewordCommits(from string, messages map[string]string, signing Signing) (string, error)
+
+	// CommitTemplate returns the content of the commit template, set by
+	// commit.template, empty if there's none.
+	CommitTemplate() (string, error)
+
+	// AddTrailers adds the trailers, e.g.: "Signed-off-by: Name <email>", to
+	// the message, except the ones already in it.
+	AddTrailers(message string, trailers []string) (string, error)
+
+	// Identity returns the committer, as "Name <email>".
+	Identity() (string, error)
+
+	// ResolveCommit returns the hash of the commit the ref points to.
+	ResolveCommit(ref string) (string, error)
+
+	// Upstream returns the upstream branch of the current branch, or "" if
+	// there's none.
+	Upstream() string
+
+	// RemoteBranches returns the remote branches containing the commit, e.g.:
+	// "origin/feature".
+	RemoteBranches(commit string) ([]string, error)
+
+	// IsAncestor checks if the commit is reachable from the ref.
+	IsAncestor(commit, ref string) bool
+
+	// DefaultBranch returns the default branch of the push remote, e.g.:
+	// "origin/main", see GetDefaultBranch.
+	DefaultBranch() (string, error)
+
+	// MergeBase returns the best common ancestor of the ref, and HEAD.
+	MergeBase(ref string) (string, error)
+
+	// RecentLog returns the subjects of the latest `count` commits, one per
+	// line.
+	RecentLog(count int) (string, error)
+
+	// CommitsSince returns the commits reachable from HEAD, but not from the
+	// ref, oldest first.
+	CommitsSince(ref string) ([]Commit, error)
+
+	// CommitsBetween returns the commits reachable from `to`, but not from
+	// `from`, oldest first, all of them up to `to` if `from` is empty.
+	CommitsBetween(from, to string) ([]Commit, error)
+
+	// CommitDate returns the date of the commit the ref points to, e.g.:
+	// "2024-01-01".
+	CommitDate(ref string) (string, error)
+
+	// RecentCommits returns the latest `count` commits, latest first, without
+	// merges, only the ones changing the paths, if given.
+	RecentCommits(count int, paths ...string) ([]Commit, error)
+
+	// Authors returns the authors of the current branch, as "Name <email>",
+	// the most frequent first.
+	Authors() ([]string, error)
+
+	// Push pushes the commits of the current branch.
+	Push() error
+
+	// FetchTags fetches the tags of the remote.
+	FetchTags() error
+
+	// LatestTags returns up to `count` tags, latest version first, all of
+	// them if `count` isn't positive.
+	LatestTags(count int) ([]string, error)
+
+	// IsTag checks if the ref is a tag.
+	IsTag(ref string) bool
+
+	// Tag creates an annotated tag on HEAD, signed as set.
+	Tag(tag, message string, signing Signing) error
+
+	// PushTag pushes only the tag.
+	PushTag(tag string) error
+}
+
+// Exec is the Repository of the current directory, running git.
+type Exec struct{}
+
+//////
+// Exported methods.
+//////
+
+// IsRepo checks if the current directory is in a Git repository.
+func (e *Exec) IsRepo() bool {
+	return IsCurrentDirectoryGitRepo()
+}
+
+// Root returns the absolute path of the top-level directory.
+func (e *Exec) Root() (string, error) {
+	return GetRepoRoot()
+}
+
+// HooksDir returns the absolute path of the hooks directory.
+func (e *Exec) HooksDir() (string, error) {
+	return GetHooksDir()
+}
+
+// IsDirty checks if there are unstaged changes.
+func (e *Exec) IsDirty() bool {
+	return IsDirty()
+}
+
+// HasStagedChanges checks if there are staged, but not committed changes.
+func (e *Exec) HasStagedChanges() bool {
+	return HasStagedChanges()
+}
+
+// HasCommits checks if the current branch has at least one commit.
+func (e *Exec) HasCommits() bool {
+	return HasCommits()
+}
+
+// CurrentBranch returns the name of the current branch.
+func (e *Exec) CurrentBranch() (string, error) {
+	return GetCurrentBranch()
+}
+
+// UnstagedDiff returns the differences between the working tree, and the
+// index.
+func (e *Exec) UnstagedDiff() (string, error) {
+	return GetUnstagedDiff()
+}
+
+// UntrackedFiles returns the paths of the untracked, not ignored files.
+func (e *Exec) UntrackedFiles() ([]string, error) {
+	return GetUntrackedFiles()
+}
+
+// StagedFiles returns the paths of the staged files.
+func (e *Exec) StagedFiles() ([]string, error) {
+	return GetStagedFiles()
+}
+
+// AddAll stages all changes.
+func (e *Exec) AddAll() error {
+	return GitAddAll()
+}
+
+// StageFiles stages the files.
+func (e *Exec) StageFiles(paths []string) error {
+	return StageFiles(paths)
+}
+
+// ApplyCached stages the patch.
+func (e *Exec) ApplyCached(patch string) error {
+	return ApplyCached(patch)
+}
+
+// Snapshot saves the index, and HEAD, so they can be restored.
+func (e *Exec) Snapshot() (IndexSnapshot, error) {
+	return SnapshotIndex()
+}
+
+// RestoreSnapshot moves HEAD back to the snapshot, and restores the index.
+func (e *Exec) RestoreSnapshot(snapshot IndexSnapshot) error {
+	return RestoreSnapshot(snapshot)
+}
+
+// UnstageAll resets the index to HEAD.
+func (e *Exec) UnstageAll() error {
+	return UnstageAll()
+}
+
+// StageFromTree stages the paths as they're in the tree.
+func (e *Exec) StageFromTree(tree string, paths []string) error {
+	return StageFromTree(tree, paths)
+}
+
+// Diff returns the staged differences, without the excluded files, and the
+// excluded files.
+func (e *Exec) Diff(filter *pathfilter.Filter, paths ...string) (string, []string, error) {
+	return GetGitDiff(filter, paths...)
+}
+
+// AmendDiff returns the differences the amended HEAD would have.
+func (e *Exec) AmendDiff(filter *pathfilter.Filter) (string, []string, error) {
+	return GetAmendDiff(filter)
+}
+
+// CommitDiff returns the differences introduced by the commit.
+func (e *Exec) CommitDiff(filter *pathfilter.Filter, commit string) (string, []string, error) {
+	return GetCommitDiff(filter, commit)
+}
+
+// RangeDiff returns the differences between two commits.
+func (e *Exec) RangeDiff(filter *pathfilter.Filter, from, to string) (string, []string, error) {
+	return GetRangeDiff(filter, from, to)
+}
+
+// Stats returns the statistics of the staged changes.
+func (e *Exec) Stats(excluded []string, paths ...string) (string, error) {
+	return GetGitStats(excluded, paths...)
+}
+
+// AmendStats returns the statistics of the differences the amended HEAD would
+// have.
+func (e *Exec) AmendStats(excluded []string) (string, error) {
+	return GetAmendStats(excluded)
+}
+
+// CommitStats returns the statistics of the differences introduced by the
+// commit.
+func (e *Exec) CommitStats(excluded []string, commit string) (string, error) {
+	return GetCommitStats(excluded, commit)
+}
+
+// RangeStats returns the statistics of the differences between two commits.
+func (e *Exec) RangeStats(excluded []string, from, to string) (string, error) {
+	return GetRangeStats(excluded, from, to)
+}
+
+// Commit commits the staged changes with the message, signed as set.
+func (e *Exec) Commit(message string, signing Signing) error {
+	return GitCommit(message, signing)
+}
+
+// CommitAmend replaces HEAD with a commit of its changes, and the staged ones.
+func (e *Exec) CommitAmend(message string, signing Signing) error {
+	return GitCommitAmend(message, signing)
+}
+
+// RewordCommits replaces the messages of the commits after `from`.
+func (e *Exec) RewordCommits(from string, messages map[string]string, signing Signing) (string, error) {
+	return RewordCommits(from, messages, signing)
+}
+
+// CommitTemplate returns the content of the commit template, if any.
+func (e *Exec) CommitTemplate() (string, error) {
+	return GetCommitTemplate()
+}
+
+// AddTrailers adds the trailers to the message, see AddTrailers.
+func (e *Exec) AddTrailers(message string, trailers []string) (string, error) {
+	return AddTrailers(message, trailers)
+}
+
+// Identity returns the committer, as "Name <email>".
+func (e *Exec) Identity() (string, error) {
+	return GetIdentity()
+}
+
+// ResolveCommit returns the hash of the commit the ref points to.
+func (e *Exec) ResolveCommit(ref string) (string, error) {
+	return ResolveCommit(ref)
+}
+
+// Upstream returns the upstream branch of the current branch, or "".
+func (e *Exec) Upstream() string {
+	return GetUpstream()
+}
+
+// RemoteBranches returns the remote branches containing the commit.
+func (e *Exec) RemoteBranches(commit string) ([]string, error) {
+	return GetRemoteBranches(commit)
+}
+
+// IsAncestor checks if the commit is reachable from the ref.
+func (e *Exec) IsAncestor(commit, ref string) bool {
+	return IsAncestor(commit, ref)
+}
+
+// DefaultBranch returns the default branch of the push remote.
+func (e *Exec) DefaultBranch() (string, error) {
+	return GetDefaultBranch()
+}
+
+// MergeBase returns the best common ancestor of the ref, and HEAD.
+func (e *Exec) MergeBase(ref string) (string, error) {
+	return GetMergeBase(ref)
+}
+
+// RecentLog returns the subjects of the latest `count` commits.
+func (e *Exec) RecentLog(count int) (string, error) {
+	return GetRecentLog(count)
+}
+
+// CommitsSince returns the commits reachable from HEAD, but not from the ref.
+func (e *Exec) CommitsSince(ref string) ([]Commit, error) {
+	return GetCommitsSince(ref)
+}
+
+// CommitsBetween returns the commits reachable from `to`, but not from
+// `from`.
+func (e *Exec) CommitsBetween(from, to string) ([]Commit, error) {
+	return GetCommits(from, to)
+}
+
+// CommitDate returns the date of the commit the ref points to.
+func (e *Exec) CommitDate(ref string) (string, error) {
+	return GetCommitDate(ref)
+}
+
+// RecentCommits returns the latest `count` commits, latest first.
+func (e *Exec) RecentCommits(count int, paths ...string) ([]Commit, error) {
+	return GetRecentCommits(count, paths...)
+}
+
+// Authors returns the authors of the current branch, the most frequent first.
+func (e *Exec) Authors() ([]string, error) {
+	return GetAuthors()
+}
+
+// Push pushes the commits of the current branch.
+func (e *Exec) Push() error {
+	return GitPush()
+}
+
+// FetchTags fetches the tags of the remote.
+func (e *Exec) FetchTags() error {
+	return GitFetchTags()
+}
+
+// LatestTags returns up to `count` tags, latest version first.
+func (e *Exec) LatestTags(count int) ([]string, error) {
+	return GitGetLatestTags(count)
+}
+
+// IsTag checks if the ref is a tag.
+func (e *Exec) IsTag(ref string) bool {
+	return IsTag(ref)
+}
+
+// Tag creates an annotated tag on HEAD, signed as set.
+func (e *Exec) Tag(tag, message string, signing Signing) error {
+	return GitTag(tag, message, signing)
+}
+
+// PushTag pushes only the tag to the push remote.
+func (e *Exec) PushTag(tag string) error {
+	return GitPushTag(tag)
+}
+
+//////
+// Factory.
+//////
+
+// NewExec returns the Repository of the current directory, running git.
+func NewExec() *Exec {
+	return &Exec{}
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// SigningFormats are the formats of signatures git supports, see gpg.format.
+var SigningFormats = []string{"openpgp", "ssh", "x509"}
+
+// signingFailures are parts of the errors of git, lowercased, when it fails
+// to sign, e.g.: "gpg failed to sign the data", or the SSH key is missing.
+var signingFailures = []string{
+	"failed to sign",
+	"unable to sign",
+	"couldn't load public key",
+	"failed to write commit object",
+}
+
+// Signing tells how commits, and tags are signed, on top of the git
+// configuration, e.g.: commit.gpgSign, user.signingKey, and gpg.format. The
+// zero value leaves it to git.
+type Signing struct {
+	// Sign signs, even if commit.gpgSign, or tag.gpgSign is off.
+	Sign bool
+
+	// Key signs with the key instead of user.signingKey, e.g.: a GPG key ID,
+	// or the path of an SSH key. It doesn't enable signing by itself.
+	Key string
+
+	// Format of the signatures instead of gpg.format, see SigningFormats.
+	Format string
+}
+
+//////
+// Helpers.
+//////
+
+// args returns the arguments of git running the subcommand, with the key,
+// and format of the signatures, if set.
+func (s Signing) args(args ...string) []string {
+	config := []string{}
+
+	if s.Key != "" {
+		config = append(config, "-c", "user.signingKey="+s.Key)
+	}
+
+	if s.Format != "" {
+		config = append(config, "-c", "gpg.format="+s.Format)
+	}
+
+	return append(config, args...)
+}
+
+// configEnabled checks if the boolean git setting is on, e.g.:
+// commit.gpgSign.
+func configEnabled(key string) bool {
+	out, err := exec.Command("git", "config", "--type=bool", "--get", key).Output()
+
+	return err == nil && strings.TrimSpace(string(out)) == "true"
+}
+
+// isSigningFailure checks if git failed because it couldn't sign, from its
+// standard error, part of the error.
+func isSigningFailure(err error) bool {
+	output := strings.ToLower(err.Error())
+
+	for _, failure := range signingFailures {
+		if strings.Contains(output, failure) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signingError returns the dedicated error of the signing failure, e.g.:
+// ErrFailedToSignCommit.
+func signingError(code string, err error) error {
+	return errorcatalog.MustGet(
+		code,
+		customerror.WithField("hint", "check user.signingKey, gpg.format, and that the GPG, or SSH agent can prompt"),
+		customerror.WithError(err),
+	).NewFailedToError()
+}
+
+//////
+// Exported methods.
+//////
+
+// SignsCommits checks if commits are signed: Sign is set, or commit.gpgSign
+// is on.
+func (s Signing) SignsCommits() bool {
+	return s.Sign || configEnabled("commit.gpgSign")
+}
+
+// SignsTags checks if annotated tags are signed: Sign is set, or tag.gpgSign
+// is on.
+func (s Signing) SignsTags() bool {
+	return s.Sign || configEnabled("tag.gpgSign")
+}
+
+//////
+// Factory.
+//////
+
+// NewSigning returns the signing, validating the format, if set.
+func NewSigning(sign bool, key, format string) (Signing, error) {
+	if format != "" && !slices.Contains(SigningFormats, format) {
+		return Signing{}, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidSigningFormat,
+			customerror.WithField("format", format),
+		).NewInvalidError()
+	}
+
+	return Signing{Sign: sign, Key: key, Format: format}, nil
+}
@@ -0,0 +1,83 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestSigning verifies signing failures of commits, rewritten commits, and
+// tags are reported with dedicated errors, and the commit template is read.
+func TestSigning(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	run := func(args ...string) {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Skipf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	run("init", "-q")
+	run("config", "commit.gpgsign", "false")
+	run("config", "gpg.program", "false")
+
+	if err := os.WriteFile("a.txt", []byte("a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run("add", ".")
+
+	if _, err := NewSigning(true, "", "pgp"); err == nil {
+		t.Error("expected an error for an invalid format")
+	}
+
+	sign, err := NewSigning(true, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, signing := range []Signing{sign, {Sign: true, Key: "/missing/key", Format: "ssh"}} {
+		if err := GitCommit("feat: add a", signing); err == nil || !strings.Contains(err.Error(), "sign commit") {
+			t.Errorf("expected a signing error with %+v, got %v", signing, err)
+		}
+	}
+
+	if err := GitCommit("feat: add a", Signing{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := GitTag("v1.0.0", "Release v1.0.0", sign); err == nil || !strings.Contains(err.Error(), "sign tag") {
+		t.Errorf("expected a signing error, got %v", err)
+	}
+
+	run("commit", "-q", "--allow-empty", "-m", "chore: b")
+
+	// Rewritten commits are signed if commit.gpgSign is on.
+	run("config", "commit.gpgsign", "true")
+
+	head, _ := ResolveCommit("HEAD")
+
+	if _, err := RewordCommits("HEAD~1", map[string]string{head: "chore: add b"}, Signing{}); err == nil || !strings.Contains(err.Error(), "sign commit") {
+		t.Errorf("expected a signing error, got %v", err)
+	}
+
+	if template, err := GetCommitTemplate(); err != nil || template != "" {
+		t.Errorf("without commit.template, the template must be empty: %q, %v", template, err)
+	}
+
+	if err := os.WriteFile(".gitmessage", []byte("feat: \n\n# Why?\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	run("config", "commit.template", ".gitmessage")
+
+	if template, err := GetCommitTemplate(); err != nil || template != "feat: \n\n# Why?" {
+		t.Errorf("template = %q, %v", template, err)
+	}
+}
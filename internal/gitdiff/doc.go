@@ -0,0 +1,3 @@
+// Package gitdiff parses unified diffs, as produced by `git diff`, into files
+// and hunks.
+package gitdiff
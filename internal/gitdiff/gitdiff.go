@@ -0,0 +1,228 @@
+package gitdiff
+
+import (
+	"strconv"
+	"strings"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Prefixes of the lines delimiting files, and hunks in a unified diff.
+const (
+	FileHeaderPrefix = "diff --git "
+	HunkHeaderPrefix = "@@ "
+)
+
+// Hunk is a contiguous block of changes of a file.
+type Hunk struct {
+	// Header is the raw "@@ -a,b +c,d @@" line, newline terminated.
+	Header string
+
+	// Lines are the raw lines of the hunk body, newline terminated.
+	Lines []string
+
+	// OldStart is the first line of the hunk in the old file.
+	OldStart int
+
+	// NewStart is the first line of the hunk in the new file.
+	NewStart int
+}
+
+// File is the section of a diff about a single file.
+type File struct {
+	// Header is the raw file header, from the "diff --git" line up to, but not
+	// including, the first hunk. Always newline terminated.
+	Header string
+
+	// OldPath is the path of the file before the change, empty if the file was
+	// added.
+	OldPath string
+
+	// Path is the path of the file after the change, or the old path if the
+	// file was deleted.
+	Path string
+
+	// Hunks of the file. Binary files, renames, and mode changes have none.
+	Hunks []Hunk
+}
+
+//////
+// Exported methods.
+//////
+
+// String returns the raw hunk, as found in the diff.
+func (h Hunk) String() string {
+	return h.Header + strings.Join(h.Lines, "")
+}
+
+// String returns the raw file section, as found in the diff.
+func (f File) String() string {
+	var b strings.Builder
+
+	b.WriteString(f.Header)
+
+	for _, h := range f.Hunks {
+		b.WriteString(h.String())
+	}
+
+	return b.String()
+}
+
+//////
+// Helpers.
+//////
+
+// splitLines splits text into newline terminated lines. The last line is
+// terminated if it isn't.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+
+	// SplitAfter yields an empty trailing element for terminated text.
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	} else {
+		lines[len(lines)-1] += "\n"
+	}
+
+	return lines
+}
+
+// parseRange parses the start of a "-a,b" or "+c,d" hunk range.
+func parseRange(r string) int {
+	start, _, _ := strings.Cut(r[1:], ",")
+
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
+
+// parseHunkHeader parses the old, and new start lines of a hunk header.
+func parseHunkHeader(line string) (int, int) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return 0, 0
+	}
+
+	return parseRange(fields[1]), parseRange(fields[2])
+}
+
+// parsePaths extracts the old, and new paths from the "---", and "+++" lines
+// of a file header, falling back to the "diff --git" line, which is all that
+// binary files, and pure renames have.
+func parsePaths(header []string) (string, string) {
+	oldPath, newPath := "", ""
+
+	for _, line := range header {
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			oldPath = trimPathPrefix(strings.TrimPrefix(line, "--- "), "a/")
+		case strings.HasPrefix(line, "+++ "):
+			newPath = trimPathPrefix(strings.TrimPrefix(line, "+++ "), "b/")
+		case strings.HasPrefix(line, "rename from "):
+			oldPath = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			newPath = strings.TrimPrefix(line, "rename to ")
+		}
+	}
+
+	if oldPath == "" && newPath == "" {
+		// "diff --git a/<old> b/<new>", where both paths are the same.
+		names := strings.TrimPrefix(strings.TrimSuffix(header[0], "\n"), FileHeaderPrefix)
+
+		if _, after, found := strings.Cut(names, " b/"); found {
+			oldPath, newPath = after, after
+		}
+	}
+
+	if newPath == "" {
+		newPath = oldPath
+	}
+
+	return oldPath, newPath
+}
+
+// trimPathPrefix removes the "a/" or "b/" prefix git adds to paths. The
+// "/dev/null" path means the file doesn't exist on that side of the diff.
+func trimPathPrefix(path, prefix string) string {
+	path = strings.TrimSuffix(path, "\t")
+
+	if path == "/dev/null" {
+		return ""
+	}
+
+	return strings.TrimPrefix(path, prefix)
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Parse parses a unified diff into files, and hunks. Anything before the first
+// file header is ignored. Joining the String of all files reproduces the diff.
+func Parse(diff string) []File {
+	if diff == "" {
+		return nil
+	}
+
+	files := []File{}
+
+	var (
+		current *File
+		header  []string
+		hunk    *Hunk
+	)
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+
+		if hunk != nil {
+			current.Hunks = append(current.Hunks, *hunk)
+		}
+
+		if current.Header == "" {
+			current.Header = strings.Join(header, "")
+		}
+
+		current.OldPath, current.Path = parsePaths(header)
+
+		files = append(files, *current)
+	}
+
+	for _, line := range splitLines(diff) {
+		switch {
+		case strings.HasPrefix(line, FileHeaderPrefix):
+			flush()
+
+			current, header, hunk = &File{}, []string{line}, nil
+		case current == nil:
+			// Preamble before the first file, ignored.
+		case strings.HasPrefix(line, HunkHeaderPrefix):
+			if hunk == nil {
+				current.Header = strings.Join(header, "")
+			} else {
+				current.Hunks = append(current.Hunks, *hunk)
+			}
+
+			oldStart, newStart := parseHunkHeader(line)
+
+			hunk = &Hunk{Header: line, OldStart: oldStart, NewStart: newStart}
+		case hunk != nil:
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			header = append(header, line)
+		}
+	}
+
+	flush()
+
+	return files
+}
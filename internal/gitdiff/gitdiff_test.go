@@ -0,0 +1,98 @@
+package gitdiff
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,2 +1,2 @@ package main
+-import "fmt"
++import "os"
+@@ -10 +10,2 @@ func main() {
++	os.Exit(0)
++	// Done.
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 3333333..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..4444444
+Binary files /dev/null and b/logo.png differ
+`
+
+// TestParse verifies diffs are parsed into files, and hunks.
+func TestParse(t *testing.T) {
+	files := Parse(testDiff)
+
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %d", len(files))
+	}
+
+	t.Run("modified file", func(t *testing.T) {
+		f := files[0]
+
+		if f.Path != "main.go" || f.OldPath != "main.go" {
+			t.Errorf("unexpected paths: %q -> %q", f.OldPath, f.Path)
+		}
+
+		if len(f.Hunks) != 2 {
+			t.Fatalf("expected 2 hunks, got %d", len(f.Hunks))
+		}
+
+		if f.Hunks[1].OldStart != 10 || f.Hunks[1].NewStart != 10 {
+			t.Errorf("unexpected hunk range: %d %d", f.Hunks[1].OldStart, f.Hunks[1].NewStart)
+		}
+
+		if len(f.Hunks[1].Lines) != 2 {
+			t.Errorf("expected 2 lines in second hunk, got %d", len(f.Hunks[1].Lines))
+		}
+
+		if !strings.HasPrefix(f.Header, "diff --git a/main.go b/main.go\n") ||
+			!strings.HasSuffix(f.Header, "+++ b/main.go\n") {
+			t.Errorf("unexpected header: %q", f.Header)
+		}
+	})
+
+	t.Run("deleted file", func(t *testing.T) {
+		if files[1].Path != "old.txt" || files[1].OldPath != "old.txt" {
+			t.Errorf("unexpected paths: %q -> %q", files[1].OldPath, files[1].Path)
+		}
+	})
+
+	t.Run("binary file", func(t *testing.T) {
+		if files[2].Path != "logo.png" {
+			t.Errorf("unexpected path: %q", files[2].Path)
+		}
+
+		if len(files[2].Hunks) != 0 {
+			t.Errorf("expected no hunks, got %d", len(files[2].Hunks))
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		var b strings.Builder
+
+		for _, f := range files {
+			b.WriteString(f.String())
+		}
+
+		if b.String() != testDiff {
+			t.Errorf("round trip mismatch:\n%s", b.String())
+		}
+	})
+}
+
+// TestParse_Empty verifies an empty diff has no files.
+func TestParse_Empty(t *testing.T) {
+	if files := Parse(""); files != nil {
+		t.Errorf("expected nil, got %v", files)
+	}
+}
@@ -0,0 +1,3 @@
+// Package hook installs committer as a prepare-commit-msg git hook, so
+// commit messages are generated for plain `git commit`, or commits from IDEs.
+package hook
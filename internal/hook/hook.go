@@ -0,0 +1,214 @@
+package hook
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Name is the name of the hook.
+const Name = "prepare-commit-msg"
+
+// ChainedSuffix is appended to the name of a hook found while installing.
+// It's renamed, and called by the installed hook, then restored when
+// uninstalling.
+const ChainedSuffix = ".committer-chained"
+
+// Marker identifies hooks installed by committer.
+const Marker = "# Installed by committer"
+
+// SourceTemplate is the source of the commit message when git filled the
+// message file with commit.template.
+const SourceTemplate = "template"
+
+// Sources of the commit message, passed by git as the second argument of the
+// hook, for which no message is generated: the user, or git already provided
+// one. See githooks(5).
+var skippedSources = map[string]bool{
+	"commit":  true, // --amend, -c, or -C.
+	"merge":   true,
+	"message": true, // -m, or -F.
+	"squash":  true,
+}
+
+// Status is the installation status of the hook.
+type Status struct {
+	// Path of the hook.
+	Path string `json:"path"`
+
+	// Installed is whether committer's hook is installed.
+	Installed bool `json:"installed"`
+
+	// Foreign is whether there's a hook not installed by committer.
+	Foreign bool `json:"foreign"`
+
+	// Chained is the path of the hook called by committer's hook, if any.
+	Chained string `json:"chained,omitempty"`
+}
+
+//////
+// Helpers.
+//////
+
+// quote quotes the string for a POSIX shell.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// isInstalled checks if the file is a hook installed by committer.
+func isInstalled(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.Contains(string(content), Marker), nil
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Script returns the hook script, calling the executable, if it still exists,
+// otherwise committer from the PATH. Failures never abort the commit, the
+// user can still write the message. The chained hook, if any, is called
+// afterwards, so it can amend the generated message.
+func Script(executable string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s. Remove with: committer hook uninstall
+COMMITTER=%s
+[ -x "$COMMITTER" ] || COMMITTER=committer
+
+"$COMMITTER" hook run "$@" </dev/null || echo "committer: failed to generate the commit message" >&2
+
+CHAINED="$(dirname "$0")/%s%s"
+if [ -x "$CHAINED" ]; then
+	exec "$CHAINED" "$@"
+fi
+`, Marker, quote(executable), Name, ChainedSuffix)
+}
+
+// ShouldGenerate checks if a message should be generated, given the source of
+// the message passed by git to the hook.
+func ShouldGenerate(source string) bool {
+	return !skippedSources[source]
+}
+
+// Prepend adds the generated message before the content of the message file,
+// e.g.: the comments git adds. For the SourceTemplate source, the content is
+// the commit template, which the message already follows, so only its
+// comments are kept, otherwise its empty fields would be committed too.
+func Prepend(content, message, source string) string {
+	if source == SourceTemplate {
+		comments := []string{}
+
+		for _, line := range strings.SplitAfter(content, "\n") {
+			if strings.HasPrefix(line, "#") {
+				comments = append(comments, line)
+			}
+		}
+
+		content = "\n" + strings.Join(comments, "")
+	}
+
+	return strings.TrimRight(message, "\n") + "\n" + content
+}
+
+// GetStatus returns the installation status of the hook in the hooks
+// directory.
+func GetStatus(dir string) (Status, error) {
+	status := Status{Path: filepath.Join(dir, Name)}
+
+	installed, err := isInstalled(status.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return status, err
+	}
+
+	status.Installed = installed
+	status.Foreign = err == nil && !installed
+
+	if chained := status.Path + ChainedSuffix; installed {
+		if _, err := os.Stat(chained); err == nil {
+			status.Chained = chained
+		}
+	}
+
+	return status, nil
+}
+
+// Install installs the hook in the hooks directory, calling the executable.
+// An existing hook is kept, and chained. Installing again updates the hook.
+func Install(dir, executable string) (Status, error) {
+	status, err := GetStatus(dir)
+	if err != nil {
+		return status, errorcatalog.MustGet(errorcatalog.ErrFailedToInstallHook, customerror.WithError(err)).NewFailedToError()
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return status, errorcatalog.MustGet(errorcatalog.ErrFailedToInstallHook, customerror.WithError(err)).NewFailedToError()
+	}
+
+	if status.Foreign {
+		chained := status.Path + ChainedSuffix
+
+		if _, err := os.Stat(chained); err == nil {
+			return status, errorcatalog.MustGet(
+				errorcatalog.ErrFailedToInstallHook,
+				customerror.WithError(fmt.Errorf("%s already exists", chained)),
+			).NewFailedToError()
+		}
+
+		if err := os.Rename(status.Path, chained); err != nil {
+			return status, errorcatalog.MustGet(errorcatalog.ErrFailedToInstallHook, customerror.WithError(err)).NewFailedToError()
+		}
+	}
+
+	//nolint:gosec // Hooks must be executable.
+	if err := os.WriteFile(status.Path, []byte(Script(executable)), 0o755); err != nil {
+		return status, errorcatalog.MustGet(errorcatalog.ErrFailedToInstallHook, customerror.WithError(err)).NewFailedToError()
+	}
+
+	// WriteFile doesn't change the mode of existing files.
+	if err := os.Chmod(status.Path, 0o755); err != nil {
+		return status, errorcatalog.MustGet(errorcatalog.ErrFailedToInstallHook, customerror.WithError(err)).NewFailedToError()
+	}
+
+	return GetStatus(dir)
+}
+
+// Uninstall removes the hook from the hooks directory, restoring the chained
+// hook, if any. Hooks not installed by committer are left untouched.
+func Uninstall(dir string) error {
+	status, err := GetStatus(dir)
+	if err != nil {
+		return errorcatalog.MustGet(errorcatalog.ErrFailedToUninstallHook, customerror.WithError(err)).NewFailedToError()
+	}
+
+	if !status.Installed {
+		return errorcatalog.MustGet(
+			errorcatalog.ErrFailedToUninstallHook,
+			customerror.WithError(fmt.Errorf("%s wasn't installed by committer", status.Path)),
+		).NewFailedToError()
+	}
+
+	if err := os.Remove(status.Path); err != nil {
+		return errorcatalog.MustGet(errorcatalog.ErrFailedToUninstallHook, customerror.WithError(err)).NewFailedToError()
+	}
+
+	if status.Chained != "" {
+		if err := os.Rename(status.Chained, status.Path); err != nil {
+			return errorcatalog.MustGet(errorcatalog.ErrFailedToUninstallHook, customerror.WithError(err)).NewFailedToError()
+		}
+	}
+
+	return nil
+}
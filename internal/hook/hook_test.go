@@ -0,0 +1,134 @@
+package hook
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestInstall verifies the hook is installed, chaining, and restoring an
+// existing one.
+func TestInstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hooks")
+	path := filepath.Join(dir, Name)
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := "#!/bin/sh\necho existing\n"
+
+	if err := os.WriteFile(path, []byte(existing), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := GetStatus(dir)
+	if err != nil || !status.Foreign || status.Installed {
+		t.Fatalf("unexpected status: %+v, %v", status, err)
+	}
+
+	status, err = Install(dir, "/usr/local/bin/committer")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !status.Installed || status.Foreign || status.Chained != path+ChainedSuffix {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	// Installing again keeps the chained hook.
+	if status, err = Install(dir, "/usr/local/bin/committer"); err != nil || status.Chained == "" {
+		t.Errorf("unexpected status: %+v, %v", status, err)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode()&0o111 == 0 {
+		t.Errorf("expected executable hook: %v", err)
+	}
+
+	if err := Uninstall(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if content, err := os.ReadFile(path); err != nil || string(content) != existing {
+		t.Errorf("expected existing hook to be restored, got %q, %v", content, err)
+	}
+
+	if err := Uninstall(dir); err == nil {
+		t.Error("expected error uninstalling a foreign hook")
+	}
+}
+
+// TestScript verifies the script calls committer, and the chained hook.
+func TestScript(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+
+	// Stand-in for committer, and the chained hook, logging their arguments.
+	fake := filepath.Join(dir, "it's committer")
+	script := "#!/bin/sh\necho \"$(basename \"$0\") $*\" >> " + quote(log) + "\n"
+
+	for _, path := range []string{fake, filepath.Join(dir, Name+ChainedSuffix)} {
+		if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hookPath := filepath.Join(dir, Name)
+
+	if err := os.WriteFile(hookPath, []byte(Script(fake)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := exec.Command(sh, hookPath, "MSG", "template").CombinedOutput(); err != nil {
+		t.Fatalf("unexpected error: %v: %s", err, out)
+	}
+
+	content, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "it's committer hook run MSG template\n" + Name + ChainedSuffix + " MSG template\n"
+
+	if string(content) != expected {
+		t.Errorf("unexpected calls: %q", content)
+	}
+}
+
+// TestShouldGenerate verifies messages given by the user, or git are kept.
+func TestShouldGenerate(t *testing.T) {
+	for source, expected := range map[string]bool{
+		"":         true,
+		"template": true,
+		"message":  false,
+		"merge":    false,
+		"squash":   false,
+		"commit":   false,
+	} {
+		if got := ShouldGenerate(source); got != expected {
+			t.Errorf("%q: expected %v, got %v", source, expected, got)
+		}
+	}
+
+}
+
+// TestPrepend verifies the message goes before the comments, and replaces the
+// fields of the commit template.
+func TestPrepend(t *testing.T) {
+	if got := Prepend("\n# Comment\n", "feat: add it\n\n", ""); !strings.HasPrefix(got, "feat: add it\n\n# Comment") {
+		t.Errorf("unexpected message: %q", got)
+	}
+
+	template := "Why:\nRefs:\n\n# Fill in the fields.\n"
+
+	if got := Prepend(template, "feat: add it\n\nWhy: because\n", SourceTemplate); got != "feat: add it\n\nWhy: because\n\n# Fill in the fields.\n" {
+		t.Errorf("unexpected message: %q", got)
+	}
+}
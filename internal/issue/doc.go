@@ -0,0 +1,4 @@
+// Package issue extracts issue references from branch names, e.g.: the Jira
+// key of "feature/PAY-1234-retry-webhooks", and adds them to commit messages,
+// after they're generated, so the LLM can't drop them.
+package issue
@@ -0,0 +1,240 @@
+package issue
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/lint"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Placements of the references in the commit message.
+const (
+	// PlacementFooter adds a footer, e.g.: "Refs: PAY-1234".
+	PlacementFooter = "footer"
+
+	// PlacementOff leaves the message untouched.
+	PlacementOff = "off"
+
+	// PlacementPrefix prefixes the subject, e.g.: "fix: PAY-1234 retry".
+	PlacementPrefix = "prefix"
+
+	// PlacementScope adds the references to the scope, e.g.:
+	// "fix(api,PAY-1234): retry".
+	PlacementScope = "scope"
+)
+
+// Placements lists the available placements.
+var Placements = []string{PlacementFooter, PlacementOff, PlacementPrefix, PlacementScope}
+
+// DefaultFooter is the token of the footer listing the references.
+const DefaultFooter = "Refs"
+
+// DefaultPatterns match Jira keys, e.g.: "feature/PAY-1234-retry", and GitHub
+// issue numbers leading a segment of the branch, e.g.: "fix/123-typo", or
+// "gh-123".
+var DefaultPatterns = []string{
+	`[A-Z][A-Z0-9]+-[0-9]+`,
+	`(?:^|/)(?:gh-|issue-)?([0-9]+)(?:[-_/]|$)`,
+}
+
+// githubNumber matches GitHub issue numbers, referenced as "#123".
+var githubNumber = regexp.MustCompile(`^[0-9]+$`)
+
+// Rules tell how issue references are extracted from branch names, and where
+// they're added to commit messages.
+type Rules struct {
+	// Patterns extract the references. The first capture group is the
+	// reference, or the whole match if there's none.
+	Patterns []*regexp.Regexp
+
+	// Placement of the references in the message.
+	Placement string
+
+	// Footer is the token of the footer listing the references, e.g.:
+	// "Refs".
+	Footer string
+
+	// ClosingKeyword closes GitHub issues once merged, e.g.: "Closes" adds a
+	// "Closes #123" footer. Empty to only reference them.
+	ClosingKeyword string
+}
+
+//////
+// Helpers.
+//////
+
+// contains checks if the reference is already in the message, as a whole
+// word, e.g.: "#12" isn't in "#123".
+func contains(message, ref string) bool {
+	return regexp.MustCompile(`(?:^|[^\w#-])` + regexp.QuoteMeta(ref) + `(?:$|[^\w-])`).MatchString(message)
+}
+
+// addFooter adds the lines to the footer of the message, starting one if it
+// has none.
+func addFooter(message string, lines ...string) string {
+	if len(lint.Parse(message).Footer) == 0 {
+		message += "\n"
+	}
+
+	return message + "\n" + strings.Join(lines, "\n")
+}
+
+// addToHeader adds the references to the header of the message, prefixing the
+// subject, or in the scope. Headers which aren't conventional are prefixed.
+func addToHeader(message, placement string, refs []string) string {
+	header, rest, found := strings.Cut(message, "\n")
+
+	m := lint.Parse(header)
+
+	if m.Type == "" {
+		header = strings.Join(refs, " ") + " " + header
+	} else {
+		scope, subject := m.Scope, m.Subject
+
+		if placement == PlacementScope {
+			scope = strings.Join(refs, ",")
+
+			if m.Scope != "" {
+				scope = m.Scope + "," + scope
+			}
+		} else {
+			subject = strings.Join(refs, " ") + " " + subject
+		}
+
+		breaking := ""
+		if m.Breaking {
+			breaking = "!"
+		}
+
+		if scope != "" {
+			scope = "(" + scope + ")"
+		}
+
+		header = fmt.Sprintf("%s%s%s: %s", m.Type, scope, breaking, subject)
+	}
+
+	if !found {
+		return header
+	}
+
+	return header + "\n" + rest
+}
+
+//////
+// Exported methods.
+//////
+
+// Extract returns the unique references in the branch name, in order. GitHub
+// issue numbers are referenced as "#123".
+func (r *Rules) Extract(branch string) []string {
+	refs := []string{}
+
+	for _, pattern := range r.Patterns {
+		for _, match := range pattern.FindAllStringSubmatch(branch, -1) {
+			ref := match[0]
+			if len(match) > 1 {
+				ref = match[1]
+			}
+
+			if githubNumber.MatchString(ref) {
+				ref = "#" + ref
+			}
+
+			if ref != "" && !slices.Contains(refs, ref) {
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	return refs
+}
+
+// Apply adds the references of the branch name to the message, where the
+// placement says, except the ones already in it. With a closing keyword,
+// GitHub issues are closed from the footer, wherever the others go.
+func (r *Rules) Apply(message, branch string) string {
+	if r == nil || r.Placement == PlacementOff {
+		return message
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return message
+	}
+
+	refs, closing := []string{}, []string{}
+
+	for _, ref := range r.Extract(branch) {
+		switch {
+		case r.ClosingKeyword != "" && strings.HasPrefix(ref, "#"):
+			if !contains(message, r.ClosingKeyword+" "+ref) {
+				closing = append(closing, r.ClosingKeyword+" "+ref)
+			}
+		case !contains(message, ref):
+			refs = append(refs, ref)
+		}
+	}
+
+	if len(refs) > 0 {
+		if r.Placement == PlacementFooter {
+			message = addFooter(message, r.Footer+": "+strings.Join(refs, ", "))
+		} else {
+			message = addToHeader(message, r.Placement, refs)
+		}
+	}
+
+	if len(closing) > 0 {
+		message = addFooter(message, closing...)
+	}
+
+	return message
+}
+
+//////
+// Factory.
+//////
+
+// New returns the rules extracting references with the patterns, or the
+// default ones if there are none, and adding them with the placement. The
+// footer defaults to DefaultFooter.
+func New(patterns []string, placement, footer, closingKeyword string) (*Rules, error) {
+	if !slices.Contains(Placements, placement) {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidIssuePlacement,
+			customerror.WithField("placement", placement),
+		).NewInvalidError()
+	}
+
+	if len(patterns) == 0 {
+		patterns = DefaultPatterns
+	}
+
+	if footer == "" {
+		footer = DefaultFooter
+	}
+
+	r := &Rules{Placement: placement, Footer: footer, ClosingKeyword: closingKeyword}
+
+	for _, p := range patterns {
+		regex, err := regexp.Compile(p)
+		if err != nil {
+			return nil, errorcatalog.MustGet(
+				errorcatalog.ErrInvalidIssuePattern,
+				customerror.WithField("pattern", p),
+				customerror.WithError(err),
+			).NewInvalidError()
+		}
+
+		r.Patterns = append(r.Patterns, regex)
+	}
+
+	return r, nil
+}
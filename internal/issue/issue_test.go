@@ -0,0 +1,150 @@
+package issue
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestExtract verifies Jira keys, and GitHub issue numbers are extracted from
+// branch names.
+func TestExtract(t *testing.T) {
+	r, err := New(nil, PlacementFooter, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		branch string
+		want   []string
+	}{
+		{"feature/PAY-1234-retry-webhooks", []string{"PAY-1234"}},
+		{"PAY-1234_PAY-99-retry", []string{"PAY-1234", "PAY-99"}},
+		{"fix/123-typo", []string{"#123"}},
+		{"gh-45", []string{"#45"}},
+		{"main", []string{}},
+		{"release/v1.2.0", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := r.Extract(tt.branch); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Extract(%q) = %v, want %v", tt.branch, got, tt.want)
+		}
+	}
+}
+
+// TestApply verifies references are placed as configured, only once.
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		placement string
+		closing   string
+		message   string
+		branch    string
+		want      string
+	}{
+		{
+			name:      "footer",
+			placement: PlacementFooter,
+			message:   "feat(api): retry webhooks\n\nRetry failed deliveries.",
+			branch:    "feature/PAY-1234-retry-webhooks",
+			want:      "feat(api): retry webhooks\n\nRetry failed deliveries.\n\nRefs: PAY-1234",
+		},
+		{
+			name:      "existing footer",
+			placement: PlacementFooter,
+			message:   "feat!: drop v1\n\nBREAKING CHANGE: v1 is gone",
+			branch:    "PAY-1",
+			want:      "feat!: drop v1\n\nBREAKING CHANGE: v1 is gone\nRefs: PAY-1",
+		},
+		{
+			name:      "prefix",
+			placement: PlacementPrefix,
+			message:   "fix(api)!: retry webhooks\n\nBody.",
+			branch:    "feature/PAY-1234-retry-webhooks",
+			want:      "fix(api)!: PAY-1234 retry webhooks\n\nBody.",
+		},
+		{
+			name:      "prefix of non conventional header",
+			placement: PlacementPrefix,
+			message:   "Retry webhooks",
+			branch:    "feature/PAY-1234-retry-webhooks",
+			want:      "PAY-1234 Retry webhooks",
+		},
+		{
+			name:      "scope",
+			placement: PlacementScope,
+			message:   "fix(api): retry webhooks",
+			branch:    "feature/PAY-1234-retry-webhooks",
+			want:      "fix(api,PAY-1234): retry webhooks",
+		},
+		{
+			name:      "scope without scope",
+			placement: PlacementScope,
+			message:   "fix: retry webhooks",
+			branch:    "feature/PAY-1234-retry-webhooks",
+			want:      "fix(PAY-1234): retry webhooks",
+		},
+		{
+			name:      "already referenced",
+			placement: PlacementPrefix,
+			message:   "fix: retry webhooks\n\nRefs: PAY-1234",
+			branch:    "feature/PAY-1234-retry-webhooks",
+			want:      "fix: retry webhooks\n\nRefs: PAY-1234",
+		},
+		{
+			name:      "github closing keyword",
+			placement: PlacementPrefix,
+			closing:   "Closes",
+			message:   "fix: handle typo",
+			branch:    "fix/123-typo",
+			want:      "fix: handle typo\n\nCloses #123",
+		},
+		{
+			name:      "github reference",
+			placement: PlacementFooter,
+			message:   "fix: handle typo\n\nFixes the typo of #1234.",
+			branch:    "fix/123-typo",
+			want:      "fix: handle typo\n\nFixes the typo of #1234.\n\nRefs: #123",
+		},
+		{
+			name:      "off",
+			placement: PlacementOff,
+			message:   "fix: handle typo",
+			branch:    "fix/123-typo",
+			want:      "fix: handle typo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(nil, tt.placement, "", tt.closing)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := r.Apply(tt.message, tt.branch); got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNew verifies invalid placements, and patterns are rejected.
+func TestNew(t *testing.T) {
+	if _, err := New(nil, "suffix", "", ""); err == nil {
+		t.Error("expected an error for an invalid placement")
+	}
+
+	if _, err := New([]string{"[A-Z"}, PlacementFooter, "", ""); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+
+	r, err := New([]string{`(?i)ticket-([0-9]+)`}, PlacementFooter, "Ticket", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := r.Apply("chore: bump deps", "chore/TICKET-77-bump"); got != "chore: bump deps\n\nTicket: #77" {
+		t.Errorf("unexpected message: %q", got)
+	}
+}
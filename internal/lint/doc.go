@@ -0,0 +1,3 @@
+// Package lint validates commit messages against a set of rules, using the
+// same rule format as commitlint, e.g.: "header-max-length": [2, "always", 72].
+package lint
@@ -0,0 +1,714 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/customerror"
+	"gopkg.in/yaml.v3"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Level is the severity of a rule, as in commitlint.
+type Level int
+
+// Available levels.
+const (
+	Disabled Level = iota
+	Warning
+	Error
+)
+
+// Applicability of a rule, as in commitlint.
+const (
+	Always = "always"
+	Never  = "never"
+)
+
+// Rule names. All but RuleSubjectImperative are commitlint rules.
+const (
+	RuleBodyLeadingBlank  = "body-leading-blank"
+	RuleBodyMaxLineLength = "body-max-line-length"
+	RuleHeaderMaxLength   = "header-max-length"
+	RuleSubjectCase       = "subject-case"
+	RuleSubjectEmpty      = "subject-empty"
+	RuleSubjectFullStop   = "subject-full-stop"
+	RuleSubjectImperative = "subject-imperative"
+	RuleSubjectMaxLength  = "subject-max-length"
+	RuleTypeEmpty         = "type-empty"
+	RuleTypeEnum          = "type-enum"
+)
+
+// ConfigFiles are the commitlint configuration files looked up, in order, at
+// the root of the repository.
+var ConfigFiles = []string{
+	".commitlintrc",
+	".commitlintrc.json",
+	".commitlintrc.yaml",
+	".commitlintrc.yml",
+}
+
+// scissors is the line of `git commit -v` above the diff, everything from it
+// on is removed from the message, as git does.
+const scissors = "# ------------------------ >8 ------------------------"
+
+// headerRegex parses a conventional commit header: type(scope)!: subject.
+var headerRegex = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: ?(.*)$`)
+
+// Rule is the configuration of a rule: [level, applicable, value].
+type Rule struct {
+	// Level is the severity of the rule.
+	Level Level
+
+	// Applicable is either Always, or Never.
+	Applicable string
+
+	// Value is the rule specific value, e.g.: the maximum length.
+	Value any
+}
+
+// Rules maps rule names to their configuration.
+type Rules map[string]Rule
+
+// HeaderRules are the rules about the type, and the subject of a conventional
+// header. They don't apply to headers of other formats, e.g.: the one of a
+// commit template, whose subject, and type can't be told apart.
+var HeaderRules = []string{
+	RuleSubjectCase,
+	RuleSubjectEmpty,
+	RuleSubjectFullStop,
+	RuleSubjectImperative,
+	RuleSubjectMaxLength,
+	RuleTypeEmpty,
+	RuleTypeEnum,
+}
+
+// Violation is a rule a message doesn't comply with.
+type Violation struct {
+	// Rule is the name of the violated rule.
+	Rule string
+
+	// Level is the severity of the violated rule.
+	Level Level
+
+	// Message describes the violation.
+	Message string
+}
+
+// Message is a commit message split into its parts.
+type Message struct {
+	Header  string
+	Type    string
+	Scope   string
+	Subject string
+	Body    []string
+	Footer  []string
+
+	// Breaking tells if the change is breaking: "!" after the type, or scope,
+	// or a "BREAKING CHANGE:" footer.
+	Breaking bool
+
+	// HasBlankAfterHeader tells if the header is followed by a blank line.
+	HasBlankAfterHeader bool
+}
+
+// ruleFunc checks a message against a rule, returning a violation message, or
+// "" if the message complies.
+type ruleFunc func(m Message, r Rule) string
+
+// checks are the implemented rules.
+var checks = map[string]ruleFunc{
+	RuleBodyLeadingBlank:  checkBodyLeadingBlank,
+	RuleBodyMaxLineLength: checkBodyMaxLineLength,
+	RuleHeaderMaxLength:   checkHeaderMaxLength,
+	RuleSubjectCase:       checkSubjectCase,
+	RuleSubjectEmpty:      checkSubjectEmpty,
+	RuleSubjectFullStop:   checkSubjectFullStop,
+	RuleSubjectImperative: checkSubjectImperative,
+	RuleSubjectMaxLength:  checkSubjectMaxLength,
+	RuleTypeEmpty:         checkTypeEmpty,
+	RuleTypeEnum:          checkTypeEnum,
+}
+
+// nonImperative are common words looking like past tense, gerund, or third
+// person, but which are fine in imperative mood.
+var nonImperative = map[string]bool{
+	"bring": true, "embed": true, "exceed": true, "feed": true, "need": true,
+	"ping": true, "proceed": true, "seed": true, "shed": true, "speed": true,
+	"string": true, "succeed": true, "swing": true, "bed": true, "wing": true,
+}
+
+//////
+// Helpers.
+//////
+
+// toInt converts a rule value to an int.
+func toInt(v any) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
+}
+
+// toStrings converts a rule value to a list of strings.
+func toStrings(v any) []string {
+	switch s := v.(type) {
+	case string:
+		return []string{s}
+	case []string:
+		return s
+	case []any:
+		items := make([]string, 0, len(s))
+
+		for _, item := range s {
+			items = append(items, fmt.Sprint(item))
+		}
+
+		return items
+	default:
+		return nil
+	}
+}
+
+// contains checks if the item is in the list.
+func contains(list []string, item string) bool {
+	for _, i := range list {
+		if i == item {
+			return true
+		}
+	}
+
+	return false
+}
+
+// holds applies the rule applicability to a condition.
+func holds(r Rule, condition bool) bool {
+	if r.Applicable == Never {
+		return !condition
+	}
+
+	return condition
+}
+
+// isFooterLine checks if the line is a footer, e.g.: "Refs: #123", or
+// "BREAKING CHANGE: ...".
+func isFooterLine(line string) bool {
+	if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+		return true
+	}
+
+	token, _, found := strings.Cut(line, ": ")
+	if !found {
+		token, _, found = strings.Cut(line, " #")
+	}
+
+	return found && token != "" && !strings.ContainsAny(token, " \t")
+}
+
+// matchesCase checks if the text is in the given case, as in commitlint.
+func matchesCase(text, c string) bool {
+	if text == "" {
+		return true
+	}
+
+	first := []rune(text)[0]
+
+	switch c {
+	case "lower-case", "lowercase":
+		return text == strings.ToLower(text)
+	case "upper-case", "uppercase":
+		return text == strings.ToUpper(text)
+	case "sentence-case", "sentencecase":
+		return unicode.IsUpper(first) && string([]rune(text)[1:]) == strings.ToLower(string([]rune(text)[1:]))
+	case "start-case", "startcase":
+		for _, word := range strings.Fields(text) {
+			if !unicode.IsUpper([]rune(word)[0]) {
+				return false
+			}
+		}
+
+		return true
+	case "pascal-case", "pascalcase":
+		return unicode.IsUpper(first) && !strings.ContainsAny(text, " -_")
+	case "camel-case", "camelcase":
+		return unicode.IsLower(first) && !strings.ContainsAny(text, " -_")
+	case "kebab-case", "kebabcase":
+		return text == strings.ToLower(text) && !strings.ContainsAny(text, " _")
+	case "snake-case", "snakecase":
+		return text == strings.ToLower(text) && !strings.ContainsAny(text, " -")
+	default:
+		return false
+	}
+}
+
+// checkBodyLeadingBlank checks the body is separated from the header by a blank
+// line.
+func checkBodyLeadingBlank(m Message, r Rule) string {
+	if len(m.Body) == 0 && len(m.Footer) == 0 {
+		return ""
+	}
+
+	if !holds(r, m.HasBlankAfterHeader) {
+		return fmt.Sprintf("body must %shave leading blank line", neverPrefix(r))
+	}
+
+	return ""
+}
+
+// checkBodyMaxLineLength checks no body line is longer than the value.
+func checkBodyMaxLineLength(m Message, r Rule) string {
+	limit := toInt(r.Value)
+
+	for _, line := range m.Body {
+		if len([]rune(line)) > limit {
+			return fmt.Sprintf("body's lines must not be longer than %d characters", limit)
+		}
+	}
+
+	return ""
+}
+
+// checkHeaderMaxLength checks the header isn't longer than the value.
+func checkHeaderMaxLength(m Message, r Rule) string {
+	if limit := toInt(r.Value); len([]rune(m.Header)) > limit {
+		return fmt.Sprintf("header must not be longer than %d characters, current length is %d", limit, len([]rune(m.Header)))
+	}
+
+	return ""
+}
+
+// checkSubjectCase checks the case of the subject against the value, a list of
+// cases.
+func checkSubjectCase(m Message, r Rule) string {
+	if m.Subject == "" {
+		return ""
+	}
+
+	cases := toStrings(r.Value)
+
+	matches := false
+
+	for _, c := range cases {
+		if matchesCase(m.Subject, c) {
+			matches = true
+
+			break
+		}
+	}
+
+	if !holds(r, matches) {
+		return fmt.Sprintf("subject must %sbe %s", neverPrefix(r), strings.Join(cases, ", "))
+	}
+
+	return ""
+}
+
+// checkSubjectEmpty checks the subject is set.
+func checkSubjectEmpty(m Message, r Rule) string {
+	if !holds(r, m.Subject == "") {
+		return fmt.Sprintf("subject may %sbe empty", neverPrefix(r))
+	}
+
+	return ""
+}
+
+// checkSubjectFullStop checks the subject ending with the value, "." by
+// default.
+func checkSubjectFullStop(m Message, r Rule) string {
+	stop := "."
+	if s := toStrings(r.Value); len(s) > 0 {
+		stop = s[0]
+	}
+
+	if !holds(r, strings.HasSuffix(m.Subject, stop)) {
+		return fmt.Sprintf("subject may %send with full stop", neverPrefix(r))
+	}
+
+	return ""
+}
+
+// checkSubjectImperative checks the subject starts with an imperative verb.
+// It's a heuristic on the first word: past tense ("added"), gerund
+// ("adding"), and third person ("adds") are rejected.
+func checkSubjectImperative(m Message, r Rule) string {
+	fields := strings.Fields(m.Subject)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	word := strings.ToLower(strings.Trim(fields[0], ".,:;!?"))
+
+	imperative := nonImperative[word] ||
+		!(strings.HasSuffix(word, "ed") ||
+			strings.HasSuffix(word, "ing") ||
+			(strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") &&
+				!strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is")))
+
+	if !holds(r, imperative) {
+		return fmt.Sprintf("subject must %suse imperative mood, e.g.: \"add\", not %q", neverPrefix(r), word)
+	}
+
+	return ""
+}
+
+// checkSubjectMaxLength checks the subject isn't longer than the value.
+func checkSubjectMaxLength(m Message, r Rule) string {
+	if limit := toInt(r.Value); len([]rune(m.Subject)) > limit {
+		return fmt.Sprintf("subject must not be longer than %d characters, current length is %d", limit, len([]rune(m.Subject)))
+	}
+
+	return ""
+}
+
+// checkTypeEmpty checks the type is set.
+func checkTypeEmpty(m Message, r Rule) string {
+	if !holds(r, m.Type == "") {
+		return fmt.Sprintf("type may %sbe empty", neverPrefix(r))
+	}
+
+	return ""
+}
+
+// checkTypeEnum checks the type is one of the value, a list of types.
+func checkTypeEnum(m Message, r Rule) string {
+	if m.Type == "" {
+		return ""
+	}
+
+	types := toStrings(r.Value)
+
+	if !holds(r, contains(types, m.Type)) {
+		return fmt.Sprintf("type must %sbe one of [%s]", neverPrefix(r), strings.Join(types, ", "))
+	}
+
+	return ""
+}
+
+// neverPrefix returns "not " for rules which are never applicable.
+func neverPrefix(r Rule) string {
+	if r.Applicable == Never {
+		return "not "
+	}
+
+	return ""
+}
+
+// sortedNames returns the names of the rules, sorted.
+func sortedNames(rules Rules) []string {
+	names := make([]string, 0, len(rules))
+
+	for name := range rules {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// parseRule parses a commitlint rule: [level, applicable, value].
+func parseRule(name string, raw any) (Rule, error) {
+	items, ok := raw.([]any)
+	if !ok || len(items) == 0 {
+		return Rule{}, fmt.Errorf("%s: expected [level, applicable, value]", name)
+	}
+
+	r := Rule{Level: Level(toInt(items[0])), Applicable: Always}
+
+	if len(items) > 1 {
+		r.Applicable = fmt.Sprint(items[1])
+	}
+
+	if len(items) > 2 {
+		r.Value = items[2]
+	}
+
+	if r.Level < Disabled || r.Level > Error {
+		return Rule{}, fmt.Errorf("%s: invalid level %d", name, r.Level)
+	}
+
+	if r.Applicable != Always && r.Applicable != Never {
+		return Rule{}, fmt.Errorf("%s: invalid applicable %q", name, r.Applicable)
+	}
+
+	return r, nil
+}
+
+//////
+// Exported methods.
+//////
+
+// String returns the level as shown by commitlint.
+func (l Level) String() string {
+	switch l {
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return "disabled"
+	}
+}
+
+// String returns the violation as shown by commitlint.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s [%s]", v.Message, v.Rule)
+}
+
+// Without returns a copy of the rules, without the named ones.
+func (r Rules) Without(names ...string) Rules {
+	rules := Rules{}
+
+	for name, rule := range r {
+		if !contains(names, name) {
+			rules[name] = rule
+		}
+	}
+
+	return rules
+}
+
+//////
+// Exported functionalities.
+//////
+
+// DefaultRules are the rules of the commit.prompt: conventional commits with
+// lowercase, imperative subjects under 50 characters, and bodies wrapped at 72
+// characters. They're stricter than commitlint's config-conventional, which
+// allows headers, and body lines up to 100 characters, and has no mood rule,
+// so messages generated from the prompt pass both.
+func DefaultRules() Rules {
+	return Rules{
+		RuleBodyLeadingBlank:  {Error, Always, nil},
+		RuleBodyMaxLineLength: {Error, Always, 72},
+		RuleSubjectCase:       {Error, Never, []string{"sentence-case", "start-case", "pascal-case", "upper-case"}},
+		RuleSubjectEmpty:      {Error, Never, nil},
+		RuleSubjectFullStop:   {Error, Never, "."},
+		RuleSubjectImperative: {Error, Always, nil},
+		RuleSubjectMaxLength:  {Error, Always, 50},
+		RuleTypeEmpty:         {Error, Never, nil},
+		RuleTypeEnum: {Error, Always, []string{
+			"feat", "fix", "docs", "style", "refactor", "perf", "test", "chore",
+		}},
+	}
+}
+
+// Parse splits a commit message into its parts. Comment lines, starting with
+// "#", and everything from the scissors line of `git commit -v` on, are
+// ignored, as git does.
+func Parse(message string) Message {
+	lines := []string{}
+
+	for _, line := range strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n") {
+		if line == scissors {
+			break
+		}
+
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+
+	// Drop leading, and trailing blank lines.
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	m := Message{}
+
+	if len(lines) == 0 {
+		return m
+	}
+
+	m.Header = lines[0]
+
+	if match := headerRegex.FindStringSubmatch(m.Header); match != nil {
+		m.Type, m.Scope, m.Subject = match[1], match[2], match[4]
+		m.Breaking = match[3] == "!"
+	}
+
+	rest := lines[1:]
+
+	if len(rest) > 0 && rest[0] == "" {
+		m.HasBlankAfterHeader = true
+		rest = rest[1:]
+	}
+
+	// The footer is the trailing paragraph made only of footer lines.
+	start := len(rest)
+
+	for start > 0 && rest[start-1] != "" && isFooterLine(rest[start-1]) {
+		start--
+	}
+
+	if start < len(rest) && (start == 0 || rest[start-1] == "") {
+		m.Footer = rest[start:]
+		rest = rest[:start]
+	}
+
+	for len(rest) > 0 && rest[len(rest)-1] == "" {
+		rest = rest[:len(rest)-1]
+	}
+
+	m.Body = rest
+
+	for _, line := range m.Footer {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			m.Breaking = true
+		}
+	}
+
+	return m
+}
+
+// Lint checks the message against the rules, returning the violations, sorted
+// by rule name. Unknown, and disabled rules are ignored.
+func Lint(message string, rules Rules) []Violation {
+	m := Parse(message)
+
+	violations := []Violation{}
+
+	for _, name := range sortedNames(rules) {
+		r := rules[name]
+
+		check, ok := checks[name]
+		if !ok || r.Level == Disabled {
+			continue
+		}
+
+		if msg := check(m, r); msg != "" {
+			violations = append(violations, Violation{Rule: name, Level: r.Level, Message: msg})
+		}
+	}
+
+	return violations
+}
+
+// HasErrors checks if any of the violations is an error.
+func HasErrors(violations []Violation) bool {
+	for _, v := range violations {
+		if v.Level == Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Disagreeing returns the rules most of the messages, e.g.: the recent commits
+// of a repository, violate, sorted by name, so its style can be told from
+// mistakes.
+func Disagreeing(rules Rules, messages []string) []string {
+	counts := map[string]int{}
+
+	for _, message := range messages {
+		violated := map[string]bool{}
+
+		for _, v := range Lint(message, rules) {
+			violated[v.Rule] = true
+		}
+
+		for name := range violated {
+			counts[name]++
+		}
+	}
+
+	names := []string{}
+
+	for _, name := range sortedNames(rules) {
+		if counts[name]*2 > len(messages) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// LoadRules loads the rules from a commitlint configuration file (JSON, or
+// YAML), on top of the default rules. Rules of `extends` are not resolved, as
+// the default rules already follow the conventional config.
+func LoadRules(path string) (Rules, error) {
+	rules := DefaultRules()
+
+	if path == "" {
+		return rules, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrFailedToLoadLintRules,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}
+
+	raw := struct {
+		Rules map[string]any `yaml:"rules"`
+	}{}
+
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrFailedToLoadLintRules,
+			customerror.WithError(fmt.Errorf("%s: %w", path, err)),
+		).NewFailedToError()
+	}
+
+	for name, value := range raw.Rules {
+		r, err := parseRule(name, value)
+		if err != nil {
+			return nil, errorcatalog.MustGet(
+				errorcatalog.ErrFailedToLoadLintRules,
+				customerror.WithError(fmt.Errorf("%s: %w", path, err)),
+			).NewFailedToError()
+		}
+
+		rules[name] = r
+	}
+
+	return rules, nil
+}
+
+// FindConfigFile returns the path of the first commitlint configuration file
+// found in dir, or "" if there's none.
+func FindConfigFile(dir string) string {
+	for _, name := range ConfigFiles {
+		path := filepath.Join(dir, name)
+
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// FormatInstructions renders the violations as instructions for the LLM to
+// fix the message.
+func FormatInstructions(violations []Violation) string {
+	var b strings.Builder
+
+	b.WriteString("The previous commit message violated the following rules, fix all of them:\n")
+
+	for _, v := range violations {
+		fmt.Fprintf(&b, "\n- %s", v)
+	}
+
+	return b.String()
+}
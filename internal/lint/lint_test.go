@@ -0,0 +1,166 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// ruleNames returns the names of the violated rules.
+func ruleNames(violations []Violation) []string {
+	names := []string{}
+
+	for _, v := range violations {
+		names = append(names, v.Rule)
+	}
+
+	return names
+}
+
+// TestLint verifies messages are checked against the default rules.
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		expected []string
+	}{
+		{"valid", "feat(auth): add OAuth2 login\n\nAllow users to sign in with Google.\n\nRefs: #12\n", nil},
+		{"valid header only", "fix: prevent double charge", nil},
+		{"comments are ignored", "fix: prevent double charge\n# Please enter the commit message", nil},
+		{"verbose commit", "fix: prevent double charge\n\n# ------------------------ >8 ------------------------\n# Do not modify or remove the line above.\ndiff --git a/a.go b/a.go\n+\tif charged { return }", nil},
+		{"subject too long", "feat: add a very long subject line that goes on and on forever", []string{RuleSubjectMaxLength}},
+		{"past tense", "fix: fixed login issue", []string{RuleSubjectImperative}},
+		{"third person", "fix: fixes login issue", []string{RuleSubjectImperative}},
+		{"imperative exceptions", "fix: process payments once", nil},
+		{"uppercase start", "fix: Prevent double charge", []string{RuleSubjectCase}},
+		{"trailing period", "fix: prevent double charge.", []string{RuleSubjectFullStop}},
+		{"unknown type", "feature: add login", []string{RuleTypeEnum}},
+		{"not conventional", "Add login", []string{RuleSubjectEmpty, RuleTypeEmpty}},
+		{"no blank after header", "fix: prevent double charge\nBody right away.", []string{RuleBodyLeadingBlank}},
+		{"long body line", "fix: prevent double charge\n\nThis body line is way too long for the commit message conventions we follow here.", []string{RuleBodyMaxLineLength}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ruleNames(Lint(tt.message, DefaultRules()))
+
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("expected %v, got %v", tt.expected, got)
+				}
+			}
+		})
+	}
+}
+
+// TestParse verifies messages are split into their parts.
+func TestParse(t *testing.T) {
+	m := Parse("feat(api)!: drop v1\n\nRemove the v1 endpoints.\n\nBREAKING CHANGE: v1 is gone\nRefs: #1\n")
+
+	if m.Type != "feat" || m.Scope != "api" || m.Subject != "drop v1" {
+		t.Errorf("unexpected header: %+v", m)
+	}
+
+	if len(m.Body) != 1 || m.Body[0] != "Remove the v1 endpoints." {
+		t.Errorf("unexpected body: %q", m.Body)
+	}
+
+	if len(m.Footer) != 2 {
+		t.Errorf("unexpected footer: %q", m.Footer)
+	}
+
+	if !m.Breaking {
+		t.Error("expected breaking change")
+	}
+
+	if !Parse("fix: typo\n\nBREAKING CHANGE: kidding").Breaking || Parse("fix: typo").Breaking {
+		t.Error("unexpected breaking change detection")
+	}
+}
+
+// TestLoadRules verifies commitlint configuration files override the default
+// rules.
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".commitlintrc.json")
+
+	content := `{
+  "extends": ["@commitlint/config-conventional"],
+  "rules": {
+    "subject-max-length": [2, "always", 100],
+    "type-enum": [2, "always", ["feat", "fix", "build"]],
+    "subject-imperative": [0]
+  }
+}`
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if found := FindConfigFile(dir); found != path {
+		t.Errorf("expected %q, got %q", path, found)
+	}
+
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	message := "build: added a subject longer than fifty characters, but allowed"
+
+	if violations := Lint(message, rules); len(violations) != 0 {
+		t.Errorf("expected no violations, got %v", violations)
+	}
+
+	t.Run("invalid rule", func(t *testing.T) {
+		if err := os.WriteFile(path, []byte(`{"rules": {"type-enum": [2, "sometimes"]}}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := LoadRules(path); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+// TestRules_Without verifies the named rules are dropped from a copy.
+func TestRules_Without(t *testing.T) {
+	rules := DefaultRules()
+	header := rules.Without(HeaderRules...)
+
+	if _, ok := rules[RuleTypeEnum]; !ok {
+		t.Error("expected the original rules to be kept")
+	}
+
+	if violations := Lint("PAY-123: Fix thing.", header); len(violations) != 0 {
+		t.Errorf("expected no violations, got %v", violations)
+	}
+
+	if violations := Lint("PAY-123: Fix thing\nBody right away.", header); len(violations) != 1 {
+		t.Errorf("expected the body rules to be kept, got %v", violations)
+	}
+}
+
+// TestDisagreeing verifies only the rules most messages violate are returned.
+func TestDisagreeing(t *testing.T) {
+	messages := []string{
+		"PAY-123: Fix double charge",
+		"PAY-124: Add refunds",
+		"fix: prevent double charge.",
+	}
+
+	expected := []string{RuleSubjectEmpty, RuleTypeEmpty}
+
+	if names := Disagreeing(DefaultRules(), messages); !slices.Equal(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	if names := Disagreeing(DefaultRules(), nil); len(names) != 0 {
+		t.Errorf("expected none without messages, got %v", names)
+	}
+}
@@ -0,0 +1,4 @@
+// Package model is the registry of the models known to committer: their
+// context window, and the tokenizer counting their tokens, so prompts are
+// sized to fit.
+package model
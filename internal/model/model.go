@@ -0,0 +1,127 @@
+package model
+
+import (
+	"strings"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Encodings of the tokenizers of the models.
+const (
+	EncodingCL100K = "cl100k_base"
+	EncodingO200K  = "o200k_base"
+)
+
+// Defaults of models missing from the registry. The context window is a
+// conservative guess, most models have a bigger one.
+const (
+	DefaultContextWindow = 8192
+	DefaultEncoding      = EncodingCL100K
+)
+
+// ReservedOutput is the number of tokens reserved for the completion, e.g.:
+// the commit message, or the summary of a chunk.
+const ReservedOutput = 1024
+
+// MinBudget is the least number of tokens left for the diff, even if the
+// prompt alone overflows the context window, so chunking always progresses.
+const MinBudget = 256
+
+// Model is a model, and what's needed to fit prompts in its context window.
+type Model struct {
+	// Name of the model, or the prefix of the names of its versions, e.g.:
+	// "gpt-4o" matches "gpt-4o-2024-08-06".
+	Name string
+
+	// ContextWindow is the number of tokens of the prompt, and the completion.
+	ContextWindow int
+
+	// Encoding of the tokenizer, e.g.: "o200k_base". Models with tokenizers
+	// not available to tiktoken use the closest one, cl100k_base.
+	Encoding string
+}
+
+// registry of the known models. Names are lower case.
+var registry = []Model{
+	// OpenAI.
+	{Name: "gpt-3.5-turbo", ContextWindow: 16385, Encoding: EncodingCL100K},
+	{Name: "gpt-4", ContextWindow: 8192, Encoding: EncodingCL100K},
+	{Name: "gpt-4-32k", ContextWindow: 32768, Encoding: EncodingCL100K},
+	{Name: "gpt-4-turbo", ContextWindow: 128000, Encoding: EncodingCL100K},
+	{Name: "gpt-4.1", ContextWindow: 1047576, Encoding: EncodingO200K},
+	{Name: "gpt-4o", ContextWindow: 128000, Encoding: EncodingO200K},
+	{Name: "o1", ContextWindow: 200000, Encoding: EncodingO200K},
+	{Name: "o1-mini", ContextWindow: 128000, Encoding: EncodingO200K},
+	{Name: "o3", ContextWindow: 200000, Encoding: EncodingO200K},
+	{Name: "o4-mini", ContextWindow: 200000, Encoding: EncodingO200K},
+
+	// Anthropic.
+	{Name: "claude", ContextWindow: 200000, Encoding: EncodingCL100K},
+
+	// Ollama, and Hugging Face.
+	{Name: "codellama", ContextWindow: 16384, Encoding: EncodingCL100K},
+	{Name: "gemma2", ContextWindow: 8192, Encoding: EncodingCL100K},
+	{Name: "llama-3.1", ContextWindow: 131072, Encoding: EncodingCL100K},
+	{Name: "llama-3.3", ContextWindow: 131072, Encoding: EncodingCL100K},
+	{Name: "llama3", ContextWindow: 8192, Encoding: EncodingCL100K},
+	{Name: "llama3.1", ContextWindow: 131072, Encoding: EncodingCL100K},
+	{Name: "llama3.2", ContextWindow: 131072, Encoding: EncodingCL100K},
+	{Name: "llama3.3", ContextWindow: 131072, Encoding: EncodingCL100K},
+	{Name: "meta-llama-3.1", ContextWindow: 131072, Encoding: EncodingCL100K},
+	{Name: "mistral", ContextWindow: 32768, Encoding: EncodingCL100K},
+	{Name: "mixtral", ContextWindow: 32768, Encoding: EncodingCL100K},
+	{Name: "qwen2.5", ContextWindow: 32768, Encoding: EncodingCL100K},
+}
+
+//////
+// Helpers.
+//////
+
+// normalize strips what doesn't identify the model from its name: the
+// organization of Hugging Face models, e.g.: "Qwen/", and the tag of Ollama
+// models, e.g.: ":8b".
+func normalize(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	name, _, _ = strings.Cut(name, ":")
+
+	return name
+}
+
+//////
+// Exported methods.
+//////
+
+// Budget returns the number of tokens left for the diff, once the prompt
+// around it, and the completion are accounted for. It's never below
+// MinBudget.
+func (m Model) Budget(promptTokens int) int {
+	return max(m.ContextWindow-promptTokens-ReservedOutput, MinBudget)
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Lookup returns the model with the longest name prefixing the name, e.g.:
+// "gpt-4o-mini" is a "gpt-4o", and "llama3.1:8b" a "llama3.1". Unknown
+// models get the defaults. It's false if the model is unknown.
+func Lookup(name string) (Model, bool) {
+	normalized := normalize(name)
+
+	found, ok := Model{Name: name, ContextWindow: DefaultContextWindow, Encoding: DefaultEncoding}, false
+
+	for _, m := range registry {
+		if strings.HasPrefix(normalized, m.Name) && (!ok || len(m.Name) > len(found.Name)) {
+			found, ok = m, true
+		}
+	}
+
+	return found, ok
+}
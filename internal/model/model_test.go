@@ -0,0 +1,48 @@
+package model
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	tests := []struct {
+		name          string
+		expected      string
+		contextWindow int
+		encoding      string
+		known         bool
+	}{
+		{"gpt-4o", "gpt-4o", 128000, EncodingO200K, true},
+		{"gpt-4o-2024-08-06", "gpt-4o", 128000, EncodingO200K, true},
+		{"gpt-4", "gpt-4", 8192, EncodingCL100K, true},
+		{"gpt-4-turbo-preview", "gpt-4-turbo", 128000, EncodingCL100K, true},
+		{"o1-mini", "o1-mini", 128000, EncodingO200K, true},
+		{"claude-3-5-sonnet-20240620", "claude", 200000, EncodingCL100K, true},
+		{"llama3.1:8b", "llama3.1", 131072, EncodingCL100K, true},
+		{"llama3:8b", "llama3", 8192, EncodingCL100K, true},
+		{"Qwen/Qwen2.5-Coder-32B-Instruct", "qwen2.5", 32768, EncodingCL100K, true},
+		{"meta-llama/Llama-3.1-8B-Instruct", "llama-3.1", 131072, EncodingCL100K, true},
+		{"my-fine-tune", "my-fine-tune", DefaultContextWindow, DefaultEncoding, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, known := Lookup(tt.name)
+
+			if known != tt.known || m.Name != tt.expected || m.ContextWindow != tt.contextWindow || m.Encoding != tt.encoding {
+				t.Errorf("unexpected model: %+v, known: %v", m, known)
+			}
+		})
+	}
+}
+
+func TestModel_Budget(t *testing.T) {
+	m := Model{ContextWindow: 8192}
+
+	if budget := m.Budget(1000); budget != 8192-1000-ReservedOutput {
+		t.Errorf("unexpected budget: %d", budget)
+	}
+
+	// The prompt alone overflows the context window.
+	if budget := m.Budget(10000); budget != MinBudget {
+		t.Errorf("expected the minimum budget, got %d", budget)
+	}
+}
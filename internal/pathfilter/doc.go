@@ -0,0 +1,4 @@
+// Package pathfilter decides which files of a change are sent to the LLM,
+// using include, and exclude globs, e.g.: lock files, vendored, or generated
+// code add nothing to a commit message, while burning tokens.
+package pathfilter
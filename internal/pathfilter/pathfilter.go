@@ -0,0 +1,176 @@
+package pathfilter
+
+import (
+	"regexp"
+	"strings"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// DefaultExcludes are the globs excluded by default: lock files, vendored
+// code, generated code, minified assets, and snapshots.
+var DefaultExcludes = []string{
+	// Lock files.
+	"*.lock",
+	"go.sum",
+	"go.work.sum",
+	"package-lock.json",
+	"pnpm-lock.yaml",
+	"npm-shrinkwrap.json",
+
+	// Vendored code.
+	"vendor/",
+	"node_modules/",
+	"third_party/",
+
+	// Generated code.
+	"*.pb.go",
+	"*.pb.gw.go",
+	"*.pb.cc",
+	"*.pb.h",
+	"*_pb2.py",
+	"*_pb2_grpc.py",
+	"*_pb.js",
+	"*_pb.d.ts",
+	"zz_generated*",
+
+	// Minified assets, and source maps.
+	"*.min.js",
+	"*.min.css",
+	"*.map",
+
+	// Snapshots.
+	"*.snap",
+	"__snapshots__/",
+}
+
+// Filter decides which files are excluded. A file is excluded if it matches
+// any exclude glob, and no include glob: includes take precedence, so they
+// can bring back files excluded by default.
+//
+// Globs follow .gitignore conventions: globs without "/" match the base name
+// at any depth, globs ending with "/" match everything below a directory,
+// "*" doesn't cross directories, and "**" does.
+type Filter struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+//////
+// Helpers.
+//////
+
+// compile converts the glob to a regular expression matching slash
+// separated paths, relative to the root of the repository.
+func compile(glob string) *regexp.Regexp {
+	glob = strings.TrimPrefix(strings.TrimSpace(glob), "./")
+
+	var b strings.Builder
+
+	b.WriteString("^")
+
+	// Globs without a slash, other than a trailing one, match at any depth.
+	if !strings.Contains(strings.TrimSuffix(glob, "/"), "/") {
+		b.WriteString("(?:.*/)?")
+	}
+
+	glob = strings.TrimPrefix(glob, "/")
+
+	// Directories match everything below them.
+	if strings.HasSuffix(glob, "/") {
+		glob += "**"
+	}
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	b.WriteString("$")
+
+	return regexp.MustCompile(b.String())
+}
+
+// compileAll compiles the non-empty globs.
+func compileAll(globs []string) []*regexp.Regexp {
+	regexes := []*regexp.Regexp{}
+
+	for _, glob := range globs {
+		if strings.TrimSpace(glob) != "" {
+			regexes = append(regexes, compile(glob))
+		}
+	}
+
+	return regexes
+}
+
+// matchesAny checks if the path matches any of the regexes.
+func matchesAny(regexes []*regexp.Regexp, path string) bool {
+	for _, regex := range regexes {
+		if regex.MatchString(path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//////
+// Exported methods.
+//////
+
+// IsIncluded checks if the path matches any include glob.
+func (f *Filter) IsIncluded(path string) bool {
+	return f != nil && matchesAny(f.include, path)
+}
+
+// IsExcluded checks if the file is excluded.
+func (f *Filter) IsExcluded(path string) bool {
+	if f == nil {
+		return false
+	}
+
+	return matchesAny(f.exclude, path) && !f.IsIncluded(path)
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Match checks if the path matches the glob.
+func Match(glob, path string) bool {
+	return compile(glob).MatchString(path)
+}
+
+//////
+// Factory.
+//////
+
+// New creates a filter. DefaultExcludes are added to the exclude globs if
+// `defaults` is set.
+func New(include, exclude []string, defaults bool) *Filter {
+	if defaults {
+		exclude = append(append([]string{}, DefaultExcludes...), exclude...)
+	}
+
+	return &Filter{
+		include: compileAll(include),
+		exclude: compileAll(exclude),
+	}
+}
@@ -0,0 +1,64 @@
+package pathfilter
+
+import "testing"
+
+// TestMatch verifies globs follow .gitignore conventions.
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"go.sum", "go.sum", true},
+		{"go.sum", "tools/go.sum", true},
+		{"*.pb.go", "api/v1/user.pb.go", true},
+		{"*.pb.go", "api/v1/user.go", false},
+		{"vendor/", "vendor/github.com/x/y.go", true},
+		{"vendor/", "internal/vendor/y.go", true},
+		{"vendor/", "vendors.go", false},
+		{"docs/*.md", "docs/index.md", true},
+		{"docs/*.md", "docs/guides/index.md", false},
+		{"docs/**/*.md", "docs/guides/index.md", true},
+		{"docs/**", "docs/a/b/c.png", true},
+		{"/build/", "build/out.js", true},
+		{"/build/", "web/build/out.js", false},
+		{"file?.txt", "file1.txt", true},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.glob, tt.path); got != tt.match {
+			t.Errorf("%q, %q: expected %v, got %v", tt.glob, tt.path, tt.match, got)
+		}
+	}
+}
+
+// TestFilter verifies defaults, excludes, and includes taking precedence.
+func TestFilter(t *testing.T) {
+	f := New([]string{"web/package-lock.json"}, []string{"*.svg"}, true)
+
+	tests := map[string]bool{
+		"go.sum":                  true,
+		"package-lock.json":       true,
+		"web/package-lock.json":   false,
+		"assets/logo.svg":         true,
+		"web/app.min.js":          true,
+		"ui/__snapshots__/a.snap": true,
+		"cmd/root.go":             false,
+	}
+
+	for path, excluded := range tests {
+		if got := f.IsExcluded(path); got != excluded {
+			t.Errorf("%s: expected %v, got %v", path, excluded, got)
+		}
+	}
+
+	if New(nil, nil, false).IsExcluded("go.sum") {
+		t.Error("expected defaults to be disabled")
+	}
+
+	var nilFilter *Filter
+
+	if nilFilter.IsExcluded("go.sum") {
+		t.Error("expected nil filter to exclude nothing")
+	}
+}
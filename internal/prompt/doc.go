@@ -0,0 +1,3 @@
+// Package prompt renders the prompts sent to the LLM from text/template
+// templates, either the embedded defaults, or user provided ones.
+package prompt
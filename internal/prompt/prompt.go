@@ -0,0 +1,339 @@
+package prompt
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Names of the templates.
+const (
+	// ChangelogName is the name of the template polishing changelog entries.
+	ChangelogName = "changelog"
+
+	// CommitName is the name of the template generating the commit message.
+	CommitName = "commit"
+
+	// PullRequestName is the name of the template generating the title, and
+	// description of a pull request.
+	PullRequestName = "pr"
+
+	// ReleaseNotesName is the name of the template generating the release
+	// notes of a tag.
+	ReleaseNotesName = "release-notes"
+
+	// SplitName is the name of the template grouping staged files into
+	// commits.
+	SplitName = "split"
+
+	// SummarizeName is the name of the template summarizing a diff chunk.
+	SummarizeName = "summarize"
+)
+
+//go:embed changelog.prompt
+var defaultChangelog string
+
+//go:embed commit.prompt
+var defaultCommit string
+
+//go:embed pr.prompt
+var defaultPullRequest string
+
+//go:embed release-notes.prompt
+var defaultReleaseNotes string
+
+//go:embed split.prompt
+var defaultSplit string
+
+//go:embed summarize.prompt
+var defaultSummarize string
+
+// funcs are the functions available to templates, besides the builtins.
+var funcs = template.FuncMap{
+	// inc returns i+1, useful to print 1-based indexes in ranges.
+	"inc": func(i int) int { return i + 1 },
+}
+
+// Data is the data available to templates, as named fields, e.g.:
+// {{.Stats}}.
+type Data struct {
+	// Base is the branch a pull request is merged into.
+	Base string
+
+	// Branch is the name of the current branch.
+	Branch string
+
+	// ChunkIndex is the 1-based index of the chunk being summarized.
+	ChunkIndex int
+
+	// ChunkTotal is the number of chunks the diff was split into.
+	ChunkTotal int
+
+	// Commits are the messages of the commits of a release, oldest first.
+	Commits []string
+
+	// CommitTemplate is the commit template of the repository, set by
+	// commit.template, if any.
+	CommitTemplate string
+
+	// DescriptionTemplate is the pull request template of the repository,
+	// if any, e.g.: .github/pull_request_template.md.
+	DescriptionTemplate string
+
+	// Diff is the staged diff, or the chunk being summarized.
+	Diff string
+
+	// Examples are the messages of recent commits of the repository, latest
+	// first, as examples of its style, e.g.: scopes, or ticket prefixes.
+	Examples []string
+
+	// Files are the paths of the staged files, only set when splitting them
+	// into several commits.
+	Files []string
+
+	// Instructions are additional instructions, e.g.: "Make more succinct".
+	Instructions string
+
+	// PreviousTag is the tag of the previous release, if any.
+	PreviousTag string
+
+	// RecentLog is the recent commit history of the repository.
+	RecentLog string
+
+	// Stats is the statistics of the staged changes.
+	Stats string
+
+	// Summaries are the summaries of every chunk, if the diff was chunked.
+	Summaries []string
+
+	// Tag is the tag of the release.
+	Tag string
+}
+
+// Template is a parsed, and validated prompt template.
+type Template struct {
+	tmpl *template.Template
+}
+
+// Templates are the templates used to generate a commit message.
+type Templates struct {
+	// Commit generates the commit message.
+	Commit *Template
+
+	// Summarize summarizes a diff chunk.
+	Summarize *Template
+}
+
+//////
+// Helpers.
+//////
+
+// fieldNames returns the names of the fields of Data.
+func fieldNames() map[string]bool {
+	names := map[string]bool{}
+
+	t := reflect.TypeOf(Data{})
+
+	for i := 0; i < t.NumField(); i++ {
+		names[t.Field(i).Name] = true
+	}
+
+	return names
+}
+
+// unknownFields walks the template tree collecting references to fields
+// Data doesn't have. Bodies of range, and with change the dot, so only
+// explicit references to the root ($.Field) are checked there.
+func unknownFields(node parse.Node, atRoot bool, known, unknown map[string]bool) {
+	check := func(ident []string) {
+		if len(ident) > 0 && !known[ident[0]] {
+			unknown[ident[0]] = true
+		}
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+
+		for _, child := range n.Nodes {
+			unknownFields(child, atRoot, known, unknown)
+		}
+	case *parse.ActionNode:
+		unknownFields(n.Pipe, atRoot, known, unknown)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				unknownFields(arg, atRoot, known, unknown)
+			}
+		}
+	case *parse.FieldNode:
+		if atRoot {
+			check(n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			check(n.Ident[1:])
+		}
+	case *parse.IfNode:
+		unknownFields(n.Pipe, atRoot, known, unknown)
+		unknownFields(n.List, atRoot, known, unknown)
+		unknownFields(n.ElseList, atRoot, known, unknown)
+	case *parse.RangeNode:
+		unknownFields(n.Pipe, atRoot, known, unknown)
+		unknownFields(n.List, false, known, unknown)
+		unknownFields(n.ElseList, atRoot, known, unknown)
+	case *parse.WithNode:
+		unknownFields(n.Pipe, atRoot, known, unknown)
+		unknownFields(n.List, false, known, unknown)
+		unknownFields(n.ElseList, atRoot, known, unknown)
+	case *parse.TemplateNode:
+		unknownFields(n.Pipe, atRoot, known, unknown)
+	}
+}
+
+//////
+// Exported methods.
+//////
+
+// Render renders the template with the data.
+func (t *Template) Render(data Data) (string, error) {
+	var b strings.Builder
+
+	if err := t.tmpl.Execute(&b, data); err != nil {
+		return "", errorcatalog.MustGet(
+			errorcatalog.ErrFailedToRenderPrompt,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}
+
+	return b.String(), nil
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Parse parses the template text, and validates it only references fields of
+// Data, so mistakes are reported before any LLM call.
+func Parse(name, text string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidPromptTemplate,
+			customerror.WithError(err),
+		).NewInvalidError()
+	}
+
+	known, unknown := fieldNames(), map[string]bool{}
+
+	for _, t := range tmpl.Templates() {
+		unknownFields(t.Tree.Root, true, known, unknown)
+	}
+
+	if len(unknown) > 0 {
+		names := make([]string, 0, len(unknown))
+
+		for name := range unknown {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidPromptTemplate,
+			customerror.WithError(fmt.Errorf("%s: unknown fields: %s", name, strings.Join(names, ", "))),
+		).NewInvalidError()
+	}
+
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Load parses the template file at path. An empty path loads the embedded
+// default template of the name.
+func Load(name, path string) (*Template, error) {
+	if path == "" {
+		return MustDefault(name), nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrFailedToLoadPromptTemplate,
+			customerror.WithError(err),
+		).NewFailedToError()
+	}
+
+	return Parse(name, string(content))
+}
+
+// MustDefault returns the embedded default template of the name. It panics if
+// there's no such template.
+func MustDefault(name string) *Template {
+	var text string
+
+	switch name {
+	case ChangelogName:
+		text = defaultChangelog
+	case CommitName:
+		text = defaultCommit
+	case PullRequestName:
+		text = defaultPullRequest
+	case ReleaseNotesName:
+		text = defaultReleaseNotes
+	case SplitName:
+		text = defaultSplit
+	case SummarizeName:
+		text = defaultSummarize
+	default:
+		panic(fmt.Sprintf("no default prompt template named %q", name))
+	}
+
+	t, err := Parse(name, text)
+	if err != nil {
+		panic(err)
+	}
+
+	return t
+}
+
+// Default returns the embedded default templates.
+func Default() *Templates {
+	return &Templates{
+		Commit:    MustDefault(CommitName),
+		Summarize: MustDefault(SummarizeName),
+	}
+}
+
+// LoadTemplates loads the commit, and summarize templates from the files at
+// the paths, falling back to the embedded defaults for empty paths.
+func LoadTemplates(commitPath, summarizePath string) (*Templates, error) {
+	commit, err := Load(CommitName, commitPath)
+	if err != nil {
+		return nil, err
+	}
+
+	summarize, err := Load(SummarizeName, summarizePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Templates{Commit: commit, Summarize: summarize}, nil
+}
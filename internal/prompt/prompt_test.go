@@ -0,0 +1,207 @@
+package prompt
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCommitPrompt_GrammarFix verifies the commit prompt does not contain the
+// old grammar error "No more change 240 characters" and instead contains the
+// corrected text "No more than 240 characters".
+func TestCommitPrompt_GrammarFix(t *testing.T) {
+	if strings.Contains(defaultCommit, "No more change 240 characters") {
+		t.Error("commit prompt still contains grammar error: 'No more change 240 characters'")
+	}
+
+	if !strings.Contains(defaultCommit, "No more than 240 characters") {
+		t.Error("commit prompt missing corrected text: 'No more than 240 characters'")
+	}
+}
+
+// TestCommitPrompt_HasRequiredSections verifies the prompt contains expected
+// structural sections.
+func TestCommitPrompt_HasRequiredSections(t *testing.T) {
+	required := []string{
+		"## Task",
+		"## Commit Message Template",
+		"### Template Fields",
+		"### Examples",
+		"### Best Practices",
+		"Change Statistics:",
+	}
+
+	for _, section := range required {
+		if !strings.Contains(defaultCommit, section) {
+			t.Errorf("commit prompt missing required section: %q", section)
+		}
+	}
+}
+
+// TestParse_UnknownFields verifies templates referencing fields Data doesn't
+// have are rejected when parsed.
+func TestParse_UnknownFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		valid bool
+	}{
+		{"known fields", "{{.Stats}} {{.Diff}} {{.Branch}} {{.RecentLog}}", true},
+		{"range body is not the root", "{{range .Summaries}}{{.}}{{end}}", true},
+		{"root variable in range", "{{range .Summaries}}{{$.Stats}}{{end}}", true},
+		{"unknown field", "{{.Stats}} {{.Ticket}}", false},
+		{"unknown field in else branch", "{{if .Summaries}}{{.Diff}}{{else}}{{.Nope}}{{end}}", false},
+		{"unknown root variable in range", "{{range .Summaries}}{{$.Nope}}{{end}}", false},
+		{"syntax error", "{{.Stats", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("test", tt.text)
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !tt.valid && err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// TestDefault_Render verifies the default templates render the data.
+func TestDefault_Render(t *testing.T) {
+	templates := Default()
+
+	t.Run("commit with diff", func(t *testing.T) {
+		out, err := templates.Commit.Render(Data{Stats: "1 file changed", Diff: "+added", Instructions: "Be brief"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, s := range []string{"1 file changed", "+added", "**Be brief**"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected prompt to contain %q", s)
+			}
+		}
+
+		if strings.Contains(out, "Chunk Summaries:") {
+			t.Error("expected no summaries section")
+		}
+
+		if strings.Contains(out, "Style of This Repository") {
+			t.Error("expected no examples section")
+		}
+	})
+
+	t.Run("commit with examples", func(t *testing.T) {
+		out, err := templates.Commit.Render(Data{Stats: "stats", Diff: "+added", Examples: []string{"PROJ-1 feat(api): add export", "fix: handle nil\n\nBody."}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, s := range []string{"#### Commit 1\n\nPROJ-1 feat(api): add export\n\n#### Commit 2\n\nfix: handle nil\n\nBody.\n\nChange Statistics:"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected prompt to contain %q", s)
+			}
+		}
+	})
+
+	t.Run("commit with commit template", func(t *testing.T) {
+		out, err := templates.Commit.Render(Data{Stats: "stats", Diff: "+added", CommitTemplate: "[PROJ-] <subject>\n\n# Why?"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !strings.Contains(out, "### Commit Template of This Repository") || !strings.Contains(out, "[PROJ-] <subject>\n\n# Why?\n\nChange Statistics:") {
+			t.Errorf("expected the commit template before the stats:\n%s", out)
+		}
+	})
+
+	t.Run("commit with summaries", func(t *testing.T) {
+		out, err := templates.Commit.Render(Data{Stats: "stats", Diff: "+hidden", Summaries: []string{"first", "second"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, s := range []string{"chunked it into 2 parts", "### Chunk 1 of 2\n\nfirst", "### Chunk 2 of 2\n\nsecond"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected prompt to contain %q", s)
+			}
+		}
+
+		if strings.Contains(out, "+hidden") {
+			t.Error("expected the diff to be replaced by the summaries")
+		}
+	})
+
+	t.Run("summarize", func(t *testing.T) {
+		out, err := templates.Summarize.Render(Data{Stats: "stats", Diff: "chunk", ChunkIndex: 2, ChunkTotal: 3})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !strings.Contains(out, "Code Changes (chunk 2 of 3):\n\nchunk") {
+			t.Errorf("unexpected prompt: %s", out)
+		}
+	})
+
+	t.Run("split", func(t *testing.T) {
+		out, err := MustDefault(SplitName).Render(Data{Stats: "stats", Files: []string{"a.go", "b.md"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !strings.Contains(out, "- a.go\n- b.md\n") || strings.Contains(out, "Code Changes:") {
+			t.Errorf("unexpected prompt: %s", out)
+		}
+	})
+
+	t.Run("changelog", func(t *testing.T) {
+		out, err := MustDefault(ChangelogName).Render(Data{Commits: []string{"feat: add export", "fix: handle nil"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !strings.Contains(out, "1. ---\nfeat: add export\n2. ---\nfix: handle nil\n") {
+			t.Errorf("unexpected prompt: %s", out)
+		}
+	})
+
+	t.Run("pull request", func(t *testing.T) {
+		data := Data{Base: "main", Branch: "feature", Commits: []string{"feat: add export"}, Stats: "stats", Diff: "+added"}
+
+		out, err := MustDefault(PullRequestName).Render(data)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, s := range []string{`merging the branch "feature" into "main"`, "## Summary", "---\nfeat: add export\n", "Code Changes:\n\n+added"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected prompt to contain %q", s)
+			}
+		}
+
+		data.DescriptionTemplate = "## Why\n\n## Checklist\n"
+
+		if out, err = MustDefault(PullRequestName).Render(data); err != nil || strings.Contains(out, "## Summary") || !strings.Contains(out, "## Why") {
+			t.Errorf("expected the repository template to be used: %s, %v", out, err)
+		}
+	})
+
+	t.Run("release notes", func(t *testing.T) {
+		out, err := MustDefault(ReleaseNotesName).Render(Data{
+			Tag:         "v1.3.0",
+			PreviousTag: "v1.2.0",
+			Commits:     []string{"feat: add export", "fix: handle nil"},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		for _, s := range []string{"release notes of v1.3.0, covering the changes since v1.2.0", "---\nfeat: add export\n---\nfix: handle nil\n"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected prompt to contain %q", s)
+			}
+		}
+	})
+}
@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/textsplitter"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// MaxBackoff caps the wait between retries.
+const MaxBackoff = 30 * time.Second
+
+// transientMarkers are found in errors of providers which don't expose status
+// codes, for transient failures.
+var transientMarkers = []string{
+	"429", "500", "502", "503", "504",
+	"rate limit", "overloaded", "temporarily unavailable", "timeout",
+}
+
+// Usage is the usage of the LLM by the completions of a chain. Providers don't
+// report it, so tokens are estimated from the length of the text.
+type Usage struct {
+	// Calls is the number of successful completions.
+	Calls int `json:"calls"`
+
+	// PromptTokens is the estimated number of tokens sent.
+	PromptTokens int `json:"promptTokens"`
+
+	// CompletionTokens is the estimated number of tokens received.
+	CompletionTokens int `json:"completionTokens"`
+}
+
+// ChainLink is a provider of a chain.
+type ChainLink struct {
+	// Label identifies the provider to the user, e.g.: "anthropic/claude".
+	Label string
+
+	// LLM is the provider.
+	LLM LLM
+}
+
+// Chain is an ordered fallback chain of providers. Each provider is retried
+// with exponential backoff on transient errors, e.g.: timeouts, 429, or 5xx.
+// The next provider is used once retries are exhausted, or on permanent
+// errors.
+type Chain struct {
+	// Backoff is the wait before the first retry, doubled on each retry.
+	Backoff time.Duration
+
+	// Retries is the number of retries per provider.
+	Retries int
+
+	links []ChainLink
+	used  string
+	usage Usage
+
+	// sleep waits for the duration, unless the context is done.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+//////
+// Helpers.
+//////
+
+// sleepContext waits for the duration, unless the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// backoff returns the wait before the retry, 1-based.
+func (c *Chain) backoff(retry int) time.Duration {
+	d := c.Backoff << (retry - 1)
+
+	if d <= 0 || d > MaxBackoff {
+		return MaxBackoff
+	}
+
+	return d
+}
+
+// complete tries every provider, in order, applying the timeout to each
+// attempt, if set.
+func (c *Chain) complete(ctx context.Context, timeout time.Duration, prompt string) (string, error) {
+	response, err := c.try(ctx, timeout, func(ctx context.Context, llm LLM) (string, error) {
+		return llm.Complete(ctx, prompt)
+	})
+	if err == nil {
+		c.record(prompt, response)
+	}
+
+	return response, err
+}
+
+// stream is like complete, but streams the completion. The partial
+// completion of a failed attempt is discarded by calling onUpdate with "".
+func (c *Chain) stream(
+	ctx context.Context,
+	timeout time.Duration,
+	prompt string,
+	onUpdate func(partial string),
+) (string, error) {
+	response, err := c.try(ctx, timeout, func(ctx context.Context, llm LLM) (string, error) {
+		response, err := streamLLM(ctx, llm, prompt, onUpdate)
+		if err != nil {
+			onUpdate("")
+		}
+
+		return response, err
+	})
+	if err == nil {
+		c.record(prompt, response)
+	}
+
+	return response, err
+}
+
+// record adds the completion to the usage.
+func (c *Chain) record(prompt, response string) {
+	c.usage.Calls++
+	c.usage.PromptTokens += textsplitter.EstimateTokens(prompt)
+	c.usage.CompletionTokens += textsplitter.EstimateTokens(response)
+}
+
+// try calls every provider, in order, until one succeeds, retrying transient
+// errors with backoff.
+func (c *Chain) try(
+	ctx context.Context,
+	timeout time.Duration,
+	call func(ctx context.Context, llm LLM) (string, error),
+) (string, error) {
+	errs := []error{}
+
+	for _, link := range c.links {
+		for attempt := 0; attempt <= c.Retries; attempt++ {
+			if attempt > 0 {
+				if err := c.sleep(ctx, c.backoff(attempt)); err != nil {
+					return "", err
+				}
+			}
+
+			attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+			if timeout > 0 {
+				attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+			}
+
+			response, err := call(attemptCtx, link.LLM)
+
+			cancel()
+
+			if err == nil {
+				c.used = link.Label
+
+				return response, nil
+			}
+
+			errs = append(errs, fmt.Errorf("%s: %w", link.Label, err))
+
+			// The caller gave up, no point in trying anything else.
+			if ctx.Err() != nil {
+				return "", err
+			}
+
+			if !IsTransient(err) {
+				break
+			}
+		}
+	}
+
+	return "", errorcatalog.MustGet(
+		errorcatalog.ErrFailedToCallLLM,
+		customerror.WithError(errors.Join(errs...)),
+	)
+}
+
+//////
+// Exported methods.
+//////
+
+// Complete tries every provider, in order, until one succeeds.
+func (c *Chain) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.complete(ctx, 0, prompt)
+}
+
+// Stream tries every provider, in order, until one succeeds, streaming the
+// completion of providers able to.
+func (c *Chain) Stream(ctx context.Context, prompt string, onUpdate func(partial string)) (string, error) {
+	return c.stream(ctx, 0, prompt, onUpdate)
+}
+
+// Name returns the labels of the providers, in order.
+func (c *Chain) Name() string {
+	labels := make([]string, 0, len(c.links))
+
+	for _, link := range c.links {
+		labels = append(labels, link.Label)
+	}
+
+	return strings.Join(labels, " → ")
+}
+
+// Used returns the label of the provider which produced the last completion,
+// or "" if none did.
+func (c *Chain) Used() string {
+	return c.used
+}
+
+// Usage returns the usage of the LLM by the completions so far.
+func (c *Chain) Usage() Usage {
+	return c.usage
+}
+
+// IsFallback checks if the last completion wasn't produced by the first
+// provider.
+func (c *Chain) IsFallback() bool {
+	return c.used != "" && len(c.links) > 0 && c.used != c.links[0].Label
+}
+
+//////
+// Exported functionalities.
+//////
+
+// IsTransient checks if the error is worth retrying: timeouts, rate limits,
+// and server errors.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+
+	for _, marker := range transientMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//////
+// Factory.
+//////
+
+// NewChain creates a fallback chain of the providers, in order.
+func NewChain(retries int, backoff time.Duration, links ...ChainLink) *Chain {
+	return &Chain{
+		Backoff: backoff,
+		Retries: retries,
+		links:   links,
+		sleep:   sleepContext,
+	}
+}
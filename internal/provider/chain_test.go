@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// fakeLLM is an LLM returning scripted results.
+type fakeLLM struct {
+	name    string
+	calls   int
+	results []error
+}
+
+func (f *fakeLLM) Complete(ctx context.Context, _ string) (string, error) {
+	err := f.results[min(f.calls, len(f.results)-1)]
+
+	f.calls++
+
+	if err != nil {
+		return "", err
+	}
+
+	return "message from " + f.name, nil
+}
+
+func (f *fakeLLM) Name() string { return f.name }
+
+// newTestChain creates a chain which doesn't wait between retries.
+func newTestChain(retries int, llms ...*fakeLLM) (*Chain, *[]time.Duration) {
+	links := []ChainLink{}
+
+	for _, l := range llms {
+		links = append(links, ChainLink{Label: l.name, LLM: l})
+	}
+
+	waits := &[]time.Duration{}
+
+	chain := NewChain(retries, time.Second, links...)
+	chain.sleep = func(_ context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+
+		return nil
+	}
+
+	return chain, waits
+}
+
+// TestChain verifies retries, backoff, and fallback.
+func TestChain(t *testing.T) {
+	transient := &StatusError{StatusCode: http.StatusServiceUnavailable}
+	permanent := &StatusError{StatusCode: http.StatusUnauthorized}
+
+	t.Run("transient errors are retried with backoff", func(t *testing.T) {
+		primary := &fakeLLM{name: "primary", results: []error{transient, transient, nil}}
+
+		chain, waits := newTestChain(2, primary)
+
+		result, err := CallLLM(context.Background(), chain, time.Second, "prompt")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if result != "message from primary" || chain.IsFallback() {
+			t.Errorf("unexpected result: %q", result)
+		}
+
+		if len(*waits) != 2 || (*waits)[0] != time.Second || (*waits)[1] != 2*time.Second {
+			t.Errorf("unexpected backoff: %v", *waits)
+		}
+	})
+
+	t.Run("permanent errors fall back immediately", func(t *testing.T) {
+		primary := &fakeLLM{name: "primary", results: []error{permanent}}
+		secondary := &fakeLLM{name: "secondary", results: []error{nil}}
+
+		chain, _ := newTestChain(3, primary, secondary)
+
+		result, err := CallLLM(context.Background(), chain, time.Second, "prompt")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if primary.calls != 1 || result != "message from secondary" {
+			t.Errorf("unexpected result: %d calls, %q", primary.calls, result)
+		}
+
+		if chain.Used() != "secondary" || !chain.IsFallback() {
+			t.Errorf("expected secondary to be used as fallback, got %q", chain.Used())
+		}
+
+		// Only successful completions are counted.
+		if usage := chain.Usage(); usage.Calls != 1 || usage.PromptTokens != 2 || usage.CompletionTokens != 6 {
+			t.Errorf("unexpected usage: %+v", usage)
+		}
+	})
+
+	t.Run("all providers failing fails", func(t *testing.T) {
+		primary := &fakeLLM{name: "primary", results: []error{transient}}
+		secondary := &fakeLLM{name: "secondary", results: []error{permanent}}
+
+		chain, _ := newTestChain(1, primary, secondary)
+
+		if _, err := CallLLM(context.Background(), chain, time.Second, "prompt"); err == nil {
+			t.Error("expected error")
+		}
+
+		if primary.calls != 2 || secondary.calls != 1 {
+			t.Errorf("unexpected calls: %d %d", primary.calls, secondary.calls)
+		}
+	})
+
+	t.Run("timeout is applied to each attempt", func(t *testing.T) {
+		chain := NewChain(0, time.Second, ChainLink{Label: "slow", LLM: llmFunc(func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+
+			return "", ctx.Err()
+		})})
+
+		_, err := CallLLM(context.Background(), chain, 10*time.Millisecond, "prompt")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	})
+}
+
+// TestChain_Stream verifies partial completions of failed attempts are
+// discarded, and providers unable to stream report their completion at once.
+func TestChain_Stream(t *testing.T) {
+	primary := &fakeLLM{name: "primary", results: []error{&StatusError{StatusCode: http.StatusUnauthorized}}}
+	secondary := &fakeLLM{name: "secondary", results: []error{nil}}
+
+	chain, _ := newTestChain(0, primary, secondary)
+
+	updates := []string{}
+
+	result, err := StreamLLM(context.Background(), chain, time.Second, "prompt", func(partial string) {
+		updates = append(updates, partial)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result != "message from secondary" {
+		t.Errorf("unexpected result: %q", result)
+	}
+
+	if len(updates) != 2 || updates[0] != "" || updates[1] != result {
+		t.Errorf("unexpected updates: %q", updates)
+	}
+}
+
+// llmFunc adapts a function to LLM.
+type llmFunc func(ctx context.Context) (string, error)
+
+func (f llmFunc) Complete(ctx context.Context, _ string) (string, error) { return f(ctx) }
+func (f llmFunc) Name() string                                           { return "func" }
+
+// TestIsTransient verifies errors are classified.
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err       error
+		transient bool
+	}{
+		{&StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{&StatusError{StatusCode: http.StatusBadGateway}, true},
+		{&StatusError{StatusCode: http.StatusBadRequest}, false},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{errors.New("anthropic: overloaded_error"), true},
+		{errors.New("invalid api key"), false},
+	}
+
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.transient {
+			t.Errorf("%v: expected %v, got %v", tt.err, tt.transient, got)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// LLM is a configured LLM provider, able to complete prompts. Providers of
+// the inference library are adapted with FromInference, others, like the
+// OpenAI-compatible one, implement it directly.
+type LLM interface {
+	// Complete sends the prompt to the LLM, returning its completion.
+	Complete(ctx context.Context, prompt string) (string, error)
+
+	// Name returns the name of the provider, e.g.: "openai".
+	Name() string
+}
+
+// Streamer is implemented by LLMs able to stream their completion as it's
+// generated. LLMs which can't stream are used through Complete.
+type Streamer interface {
+	// Stream sends the prompt to the LLM, calling onUpdate with the completion
+	// received so far, every time it grows. It returns the full completion.
+	Stream(ctx context.Context, prompt string, onUpdate func(partial string)) (string, error)
+}
+
+// inferenceLLM adapts a provider of the inference library to LLM. The
+// library can't stream, so the provider's API is called directly to stream,
+// if streamer is set.
+type inferenceLLM struct {
+	provider.IProvider
+
+	streamer Streamer
+}
+
+//////
+// Exported methods.
+//////
+
+// Complete sends the prompt as a user message.
+func (l inferenceLLM) Complete(ctx context.Context, prompt string) (string, error) {
+	return l.Completion(ctx, provider.WithUserMessages(prompt))
+}
+
+// Name returns the name of the provider.
+func (l inferenceLLM) Name() string {
+	return l.GetName()
+}
+
+// Stream streams the completion through the streamer, if set, otherwise
+// reports the full completion at once.
+func (l inferenceLLM) Stream(ctx context.Context, prompt string, onUpdate func(partial string)) (string, error) {
+	if l.streamer != nil {
+		return l.streamer.Stream(ctx, prompt, onUpdate)
+	}
+
+	response, err := l.Complete(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	onUpdate(response)
+
+	return response, nil
+}
+
+//////
+// Factory.
+//////
+
+// FromInference adapts a provider of the inference library to LLM.
+func FromInference(p provider.IProvider) LLM {
+	return inferenceLLM{IProvider: p}
+}
+
+// FromInferenceStreaming is FromInference, streaming through the streamer.
+func FromInferenceStreaming(p provider.IProvider, streamer Streamer) LLM {
+	return inferenceLLM{IProvider: p, streamer: streamer}
+}
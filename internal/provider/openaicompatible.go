@@ -0,0 +1,223 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// OpenAICompatibleName is the name of the provider for any endpoint speaking
+// the OpenAI chat completions protocol, e.g.: vLLM, LM Studio, or gateways.
+const OpenAICompatibleName = "openai-compatible"
+
+// OpenAICompatibleOptions configures the OpenAI-compatible provider.
+type OpenAICompatibleOptions struct {
+	// BaseURL of the API, e.g.: "http://localhost:1234/v1". Requests are sent
+	// to BaseURL + "/chat/completions".
+	BaseURL string
+
+	// APIKeyEnv is the name of the env var holding the API key, sent as a
+	// bearer token. No key is sent if empty, or unset.
+	APIKeyEnv string
+
+	// Headers are extra headers sent with every request. Values are expanded
+	// with env vars, e.g.: "${GATEWAY_TOKEN}".
+	Headers map[string]string
+}
+
+// StatusError is returned when the API answers with a non-2xx status.
+type StatusError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Body is the body of the response.
+	Body string
+}
+
+// OpenAICompatible is a provider for any endpoint speaking the OpenAI chat
+// completions protocol.
+type OpenAICompatible struct {
+	client  *http.Client
+	model   string
+	options OpenAICompatibleOptions
+}
+
+// chatMessage is a message of the chat completions protocol.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest is the request of the chat completions protocol.
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream,omitempty"`
+}
+
+// chatResponse is the response of the chat completions protocol.
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// chatChunk is an event of a streamed response of the chat completions
+// protocol.
+type chatChunk struct {
+	Choices []struct {
+		Delta chatMessage `json:"delta"`
+	} `json:"choices"`
+}
+
+//////
+// Helpers.
+//////
+
+// send sends the prompt as a user message to the chat completions endpoint,
+// returning the response if its status is 2xx. The caller must close its body.
+func (p *OpenAICompatible) send(ctx context.Context, prompt string, stream bool) (*http.Response, error) {
+	headers := map[string]string{}
+
+	if stream {
+		headers["Accept"] = "text/event-stream"
+	}
+
+	if p.options.APIKeyEnv != "" {
+		if key := os.Getenv(p.options.APIKeyEnv); key != "" {
+			headers["Authorization"] = "Bearer " + key
+		}
+	}
+
+	for name, value := range p.options.Headers {
+		headers[name] = os.ExpandEnv(value)
+	}
+
+	return post(
+		ctx,
+		p.client,
+		strings.TrimSuffix(p.options.BaseURL, "/")+"/chat/completions",
+		headers,
+		chatRequest{
+			Model:    p.model,
+			Messages: []chatMessage{{Role: "user", Content: prompt}},
+			Stream:   stream,
+		},
+	)
+}
+
+//////
+// Exported methods.
+//////
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// Name returns the name of the provider.
+func (p *OpenAICompatible) Name() string {
+	return OpenAICompatibleName
+}
+
+// Complete sends the prompt as a user message to the chat completions
+// endpoint.
+func (p *OpenAICompatible) Complete(ctx context.Context, prompt string) (string, error) {
+	res, err := p.send(ctx, prompt, false)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var completion chatResponse
+
+	if err := json.NewDecoder(res.Body).Decode(&completion); err != nil {
+		return "", err
+	}
+
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("no choices in response")
+	}
+
+	return completion.Choices[0].Message.Content, nil
+}
+
+// Stream sends the prompt as a user message to the chat completions
+// endpoint, reading the completion as server-sent events.
+func (p *OpenAICompatible) Stream(
+	ctx context.Context,
+	prompt string,
+	onUpdate func(partial string),
+) (string, error) {
+	res, err := p.send(ctx, prompt, true)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var completion strings.Builder
+
+	err = scanLines(res.Body, func(line string) (bool, error) {
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			return false, nil
+		}
+
+		data = strings.TrimSpace(data)
+
+		if data == "[DONE]" {
+			return true, nil
+		}
+
+		var chunk chatChunk
+
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, err
+		}
+
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			return false, nil
+		}
+
+		completion.WriteString(chunk.Choices[0].Delta.Content)
+
+		onUpdate(completion.String())
+
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return completion.String(), nil
+}
+
+//////
+// Factory.
+//////
+
+// NewOpenAICompatible creates a provider for the OpenAI-compatible endpoint.
+func NewOpenAICompatible(model string, options OpenAICompatibleOptions) (*OpenAICompatible, error) {
+	if options.BaseURL == "" {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrFailedToSetupLLM,
+			customerror.WithError(fmt.Errorf("%s: base URL is required", OpenAICompatibleName)),
+		).NewFailedToError()
+	}
+
+	return &OpenAICompatible{
+		client:  &http.Client{},
+		model:   model,
+		options: options,
+	}, nil
+}
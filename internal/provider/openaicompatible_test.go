@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestOpenAICompatible verifies requests, and responses of the chat
+// completions protocol against a local stand-in server.
+func TestOpenAICompatible(t *testing.T) {
+	t.Setenv("TEST_COMPAT_KEY", "secret")
+	t.Setenv("TEST_COMPAT_TEAM", "platform")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		if auth := r.Header.Get("Authorization"); auth != "Bearer secret" {
+			t.Errorf("unexpected authorization: %q", auth)
+		}
+
+		if team := r.Header.Get("X-Team"); team != "platform" {
+			t.Errorf("unexpected header: %q", team)
+		}
+
+		var req chatRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if req.Model != "local-model" || len(req.Messages) != 1 || req.Messages[0].Content != "prompt" {
+			t.Errorf("unexpected request: %+v", req)
+		}
+
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"feat: add it"}}]}`))
+	}))
+	defer server.Close()
+
+	llm, err := InitializeLLMProvider(OpenAICompatibleName, "local-model", OpenAICompatibleOptions{
+		BaseURL:   server.URL + "/v1/",
+		APIKeyEnv: "TEST_COMPAT_KEY",
+		Headers:   map[string]string{"X-Team": "${TEST_COMPAT_TEAM}"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := llm.Complete(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result != "feat: add it" {
+		t.Errorf("unexpected result: %q", result)
+	}
+}
+
+// TestOpenAICompatible_Stream verifies completions are streamed as
+// server-sent events.
+func TestOpenAICompatible_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req chatRequest
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !req.Stream {
+			t.Error("expected a streaming request")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		w.Write([]byte(strings.Join([]string{
+			`data: {"choices":[{"delta":{"role":"assistant"}}]}`,
+			`data: {"choices":[{"delta":{"content":"feat: "}}]}`,
+			`: keep-alive`,
+			`data: {"choices":[{"delta":{"content":"add it"}}]}`,
+			`data: [DONE]`,
+		}, "\n\n") + "\n\n"))
+	}))
+	defer server.Close()
+
+	llm, err := NewOpenAICompatible("local-model", OpenAICompatibleOptions{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updates := []string{}
+
+	result, err := llm.Stream(context.Background(), "prompt", func(partial string) {
+		updates = append(updates, partial)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result != "feat: add it" || len(updates) != 2 || updates[0] != "feat: " || updates[1] != result {
+		t.Errorf("unexpected result: %q %q", result, updates)
+	}
+}
+
+// TestOpenAICompatible_Errors verifies misconfiguration, and error statuses
+// are reported.
+func TestOpenAICompatible_Errors(t *testing.T) {
+	t.Run("base URL is required", func(t *testing.T) {
+		if _, err := NewOpenAICompatible("model", OpenAICompatibleOptions{}); err == nil {
+			t.Error("expected error")
+		}
+
+		if llm, err := InitializeLLMProvider(OpenAICompatibleName, "model", OpenAICompatibleOptions{}); err == nil || llm != nil {
+			t.Errorf("expected a nil provider, and an error, got %v, %v", llm, err)
+		}
+	})
+
+	t.Run("non-2xx status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		llm, err := NewOpenAICompatible("model", OpenAICompatibleOptions{BaseURL: server.URL})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = llm.Complete(context.Background(), "prompt")
+
+		var statusErr *StatusError
+
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("expected status error, got %v", err)
+		}
+	})
+}
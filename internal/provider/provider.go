@@ -2,11 +2,15 @@ package provider
 
 import (
 	"context"
-	_ "embed"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/issue"
+	"github.com/thalesfsp/committer/internal/lint"
+	"github.com/thalesfsp/committer/internal/prompt"
 	"github.com/thalesfsp/committer/internal/shared"
 	"github.com/thalesfsp/committer/internal/textsplitter"
 	"github.com/thalesfsp/committer/internal/tui"
@@ -18,15 +22,28 @@ import (
 	"github.com/thalesfsp/inference/provider"
 )
 
-//go:embed commit.prompt
-var commitPrompt string
+// Budget is the number of tokens of the diff fitting in the prompts, counted
+// with the encoding, see ChunkDiff. The summaries of the chunks, merged by the
+// commit prompt, must fit in it too.
+type Budget struct {
+	// Encoding counts the tokens, e.g.: the one of the model.
+	Encoding string
 
-// InitializeLLMProvider initialize the LLM provider.
+	// Tokens is the maximum number of tokens, no limit if not positive.
+	Tokens int
+}
+
+// InitializeLLMProvider initialize the LLM provider. `compat` is only used
+// by the OpenAI-compatible provider.
 func InitializeLLMProvider(
 	llmProvider string,
 	llmModel string,
-) (provider.IProvider, error) {
-	var providerInUse provider.IProvider
+	compat OpenAICompatibleOptions,
+) (LLM, error) {
+	var (
+		providerInUse provider.IProvider
+		streamer      Streamer
+	)
 
 	switch llmProvider {
 	case openai.Name:
@@ -35,21 +52,21 @@ func InitializeLLMProvider(
 			return nil, errorcatalog.MustGet(errorcatalog.ErrFailedToSetupLLM).New()
 		}
 
-		providerInUse = oai
+		providerInUse, streamer = oai, newOpenAIStreamer(llmModel)
 	case anthropic.Name:
 		anth, err := anthropic.NewDefault(provider.WithDefaulModel(llmModel))
 		if err != nil {
 			return nil, errorcatalog.MustGet(errorcatalog.ErrFailedToSetupLLM).New()
 		}
 
-		providerInUse = anth
+		providerInUse, streamer = anth, newAnthropicStreamer(llmModel)
 	case ollama.Name:
 		oll, err := ollama.NewDefault(provider.WithDefaulModel(llmModel))
 		if err != nil {
 			return nil, errorcatalog.MustGet(errorcatalog.ErrFailedToSetupLLM).New()
 		}
 
-		providerInUse = oll
+		providerInUse, streamer = oll, newOllamaStreamer(llmModel)
 	case huggingface.Name:
 		hf, err := huggingface.NewDefault(provider.WithDefaulModel(llmModel))
 		if err != nil {
@@ -57,21 +74,44 @@ func InitializeLLMProvider(
 		}
 
 		providerInUse = hf
+	case OpenAICompatibleName:
+		// Returned explicitly, so the error isn't a nil *OpenAICompatible in a
+		// non-nil LLM.
+		oac, err := NewOpenAICompatible(llmModel, compat)
+		if err != nil {
+			return nil, err
+		}
+
+		return oac, nil
 	default:
 		return nil, errorcatalog.MustGet(errorcatalog.ErrInvalidProvider).New()
 	}
 
-	return providerInUse, nil
+	return FromInferenceStreaming(providerInUse, streamer), nil
 }
 
-// ChunkDiff chunks the diff if it's too big.
-func ChunkDiff(maxChars int, diff string) ([]string, error) {
+// Names lists the available providers.
+var Names = []string{
+	openai.Name,
+	anthropic.Name,
+	ollama.Name,
+	huggingface.Name,
+	OpenAICompatibleName,
+}
+
+// ChunkDiff chunks the diff if it has more than maxTokens tokens, counted
+// with the encoding, e.g.: the one of the model, using the given splitting
+// strategy (see textsplitter.Strategies).
+func ChunkDiff(strategy, encoding string, maxTokens int, diff string) ([]string, error) {
 	// Should do nothing if the diff is smaller than the threshold.
-	if len(diff) <= maxChars {
+	if textsplitter.NewCounter(encoding, "")(diff) <= maxTokens {
 		return []string{diff}, nil
 	}
 
-	splitter := textsplitter.NewTokenSplitter(maxChars)
+	splitter, err := textsplitter.New(strategy, maxTokens, encoding)
+	if err != nil {
+		return nil, err
+	}
 
 	chunks, err := splitter.SplitText(diff)
 	if err != nil {
@@ -84,17 +124,19 @@ func ChunkDiff(maxChars int, diff string) ([]string, error) {
 // CallLLM calls the LLM API (OpenAI, Anthropic, or Ollama, etc).
 func CallLLM(
 	ctx context.Context,
-	providerInUse provider.IProvider,
+	providerInUse LLM,
 	llmAPICallTimeout time.Duration,
 	prompt string,
 ) (string, error) {
+	// Chains apply the timeout to each attempt, not to all of them.
+	if chain, ok := providerInUse.(*Chain); ok {
+		return chain.complete(ctx, llmAPICallTimeout, prompt)
+	}
+
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, llmAPICallTimeout)
 	defer cancel()
 
-	response, err := providerInUse.Completion(
-		ctxWithTimeout,
-		provider.WithUserMessages(prompt),
-	)
+	response, err := providerInUse.Complete(ctxWithTimeout, prompt)
 	if err != nil {
 		return "", err
 	}
@@ -102,6 +144,27 @@ func CallLLM(
 	return response, nil
 }
 
+// StreamLLM is like CallLLM, but calls onUpdate with the completion received
+// so far, as it grows. Providers which can't stream call it once, with the
+// full completion.
+func StreamLLM(
+	ctx context.Context,
+	providerInUse LLM,
+	llmAPICallTimeout time.Duration,
+	prompt string,
+	onUpdate func(partial string),
+) (string, error) {
+	// Chains apply the timeout to each attempt, not to all of them.
+	if chain, ok := providerInUse.(*Chain); ok {
+		return chain.stream(ctx, llmAPICallTimeout, prompt, onUpdate)
+	}
+
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, llmAPICallTimeout)
+	defer cancel()
+
+	return streamLLM(ctxWithTimeout, providerInUse, prompt, onUpdate)
+}
+
 // handleTryAgain handles the "Try again" choice.
 //
 //nolint:lll
@@ -147,112 +210,426 @@ For complex changes, summarize the overall impact rather than listing technical
 	}
 }
 
+// MaxLintRepairs is the maximum number of times a generated commit message
+// violating lint rules is sent back to the LLM to be fixed.
+const MaxLintRepairs = 2
+
+// MaxSummarizeRounds is the maximum number of times summaries not fitting in
+// the commit prompt are summarized again.
+const MaxSummarizeRounds = 3
+
 // GenerateCommitMessageLoop definition.
+//
+// Large diffs are processed map-reduce style: every chunk is summarized once
+// (map), then the summaries are merged into a single commit message (reduce).
+// Summaries not fitting in the `budget` are summarized again, see
+// SummarizeChunks. Retrying only repeats the reduce step. `data` holds the fields common to
+// all prompts, e.g.: stats, and branch. If `rules` is set, messages are
+// linted, and automatically repaired before being shown. If `issues` is set,
+// the issue references of the branch are then added to messages, so the LLM
+// can't drop them.
 func GenerateCommitMessageLoop(
-	providerInUse provider.IProvider,
+	providerInUse LLM,
 	llmAPICallTimeout time.Duration,
-	stats string, chunks []string,
+	templates *prompt.Templates,
+	data prompt.Data,
+	chunks []string,
+	budget Budget,
+	rules lint.Rules,
+	issues *issue.Rules,
 	autoAcceptMode bool,
 ) (string, error) {
-	totalChunks := len(chunks)
+	ctx := context.Background()
 
-	additionalInstructions := ""
+	if len(chunks) == 1 {
+		data.Diff = chunks[0]
+	}
 
-	maxAttempts := 5 // Define a maximum number of attempts to prevent infinite loops
+	// Summarizing can take a while, so, like the generation, Ctrl+C cancels
+	// it.
+	for len(chunks) > 1 && data.Summaries == nil {
+		_, err := tui.Stream(
+			ctx,
+			fmt.Sprintf("Summarizing %d chunks...", len(chunks)),
+			func(ctx context.Context, _ func(string)) (string, error) {
+				summaries, err := SummarizeChunks(ctx, providerInUse, llmAPICallTimeout, templates.Summarize, data, chunks, budget)
 
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		for i, chunk := range chunks {
-			tui.SpinnerStart("Generating commit message...")
-
-			message, err := GenerateCommitMessage(
-				context.Background(),
-				providerInUse,
-				llmAPICallTimeout,
-				stats, chunk,
-				i+1, totalChunks,
-				additionalInstructions,
-			)
-			if err != nil {
-				return "", fmt.Errorf("failed to generate commit message: %w", err)
-			}
+				data.Summaries = summaries
 
-			tui.SpinnerStop()
+				return "", err
+			},
+		)
 
-			fmt.Printf("%s\n\n%s\n\n", tui.QuestionStyle.Render("Generated Commit Message:"), message)
+		if errors.Is(err, context.Canceled) {
+			fmt.Printf("%s\n\n", tui.HintStyle.Render("Summarization cancelled."))
 
-			// In auto-accept mode, approve immediately.
-			if autoAcceptMode {
-				return message, nil
+			if handleCancelled() == "Write commit message yourself" {
+				return writeCommitMessage()
 			}
 
-			choice := tui.MustPromptWithChoices("What would you like to do?", []string{
-				"Approve commit message",
-				"Try again",
-				"Write commit message yourself",
-				"Exit",
-			})
+			continue
+		}
 
-			switch choice {
-			case "Approve commit message":
-				return message, nil
-			case "Try again":
-				additionalInstructions = HandleTryAgain()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	maxAttempts := 5 // Define a maximum number of attempts to prevent infinite loops
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		message, err := tui.Stream(
+			ctx,
+			"Generating commit message...",
+			func(ctx context.Context, update func(string)) (string, error) {
+				return StreamCommitMessage(ctx, providerInUse, llmAPICallTimeout, templates.Commit, data, update)
+			},
+		)
+
+		// Ctrl+C cancels the generation, not the program.
+		if errors.Is(err, context.Canceled) {
+			fmt.Printf("%s\n\n", tui.HintStyle.Render("Generation cancelled."))
 
-				break // Break inner loop to regenerate
+			switch handleCancelled() {
+			case "Try again":
+				continue
 			case "Write commit message yourself":
-				content, err := tui.CommitMessageTextArea()
-				if err != nil {
-					return "", fmt.Errorf("failed to get commit message: %w", err)
-				}
-
-				return content + "\n", nil
-			case "Exit":
-				shared.NothingToDo()
+				return writeCommitMessage()
 			}
+		}
+
+		if err != nil {
+			return "", fmt.Errorf("failed to generate commit message: %w", err)
+		}
+
+		if rules != nil && lint.HasErrors(lint.Lint(message, rules)) {
+			tui.SpinnerStart("Fixing commit message...")
+		}
+
+		message, violations, err := RepairCommitMessage(
+			ctx,
+			providerInUse,
+			llmAPICallTimeout,
+			templates.Commit,
+			data,
+			message,
+			rules,
+		)
+
+		tui.SpinnerStop()
+
+		if err != nil {
+			return "", fmt.Errorf("failed to repair commit message: %w", err)
+		}
+
+		message = issues.Apply(message, data.Branch)
+
+		fmt.Printf("%s\n\n%s\n\n", tui.QuestionStyle.Render("Generated Commit Message:"), message)
+
+		printViolations(violations)
+
+		printUsedProvider(providerInUse)
 
-			break // Break inner loop if "Try again" was selected
+		// In auto-accept mode, approve immediately.
+		if autoAcceptMode {
+			return message, nil
+		}
+
+		choice := tui.MustPromptWithChoices("What would you like to do?", []string{
+			"Approve commit message",
+			"Try again",
+			"Write commit message yourself",
+			"Exit",
+		})
+
+		switch choice {
+		case "Approve commit message":
+			return message, nil
+		case "Try again":
+			data.Instructions = HandleTryAgain()
+		case "Write commit message yourself":
+			return writeCommitMessage()
+		case "Exit":
+			shared.NothingToDo()
 		}
 	}
 
 	return "", fmt.Errorf("maximum attempts reached")
 }
 
-// GenerateCommitMessage generates a commit message using LLM API with
-// additional instructions.
+// GenerateCommitMessageOnce is the non-interactive GenerateCommitMessageLoop:
+// the message is generated, repaired, if `rules` is set, and gets the issue
+// references of the branch, if `issues` is set, without any output, or
+// prompt, e.g.: from a git hook.
+func GenerateCommitMessageOnce(
+	ctx context.Context,
+	providerInUse LLM,
+	llmAPICallTimeout time.Duration,
+	templates *prompt.Templates,
+	data prompt.Data,
+	chunks []string,
+	budget Budget,
+	rules lint.Rules,
+	issues *issue.Rules,
+) (string, error) {
+	if len(chunks) == 1 {
+		data.Diff = chunks[0]
+	}
+
+	summaries, err := SummarizeChunks(ctx, providerInUse, llmAPICallTimeout, templates.Summarize, data, chunks, budget)
+	if err != nil {
+		return "", err
+	}
+
+	data.Summaries = summaries
+
+	message, err := GenerateCommitMessage(ctx, providerInUse, llmAPICallTimeout, templates.Commit, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate commit message: %w", err)
+	}
+
+	message, _, err = RepairCommitMessage(ctx, providerInUse, llmAPICallTimeout, templates.Commit, data, message, rules)
+	if err != nil {
+		return "", fmt.Errorf("failed to repair commit message: %w", err)
+	}
+
+	return issues.Apply(message, data.Branch), nil
+}
+
+// SummarizeChunks is the "map" step of the commit message generation. It asks
+// the LLM to summarize each chunk of a chunked diff, returning one summary per
+// chunk, in order. A diff with a single chunk needs no summarization, so nil is
+// returned. The summaries must fit in the `budget`, like the chunks, to be
+// merged by the commit prompt: if they don't, they're chunked, and summarized
+// again, up to MaxSummarizeRounds times.
+func SummarizeChunks(
+	ctx context.Context,
+	providerInUse LLM,
+	llmAPICallTimeout time.Duration,
+	tmpl *prompt.Template,
+	data prompt.Data,
+	chunks []string,
+	budget Budget,
+) ([]string, error) {
+	if len(chunks) <= 1 {
+		return nil, nil
+	}
+
+	summaries, err := summarizeRound(ctx, providerInUse, llmAPICallTimeout, tmpl, data, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	if budget.Tokens <= 0 {
+		return summaries, nil
+	}
+
+	count := textsplitter.NewCounter(budget.Encoding, "")
+
+	for round := 1; ; round++ {
+		merged := strings.Join(summaries, "\n\n")
+
+		if count(merged) <= budget.Tokens {
+			return summaries, nil
+		}
+
+		if round > MaxSummarizeRounds {
+			return nil, errorcatalog.MustGet(
+				errorcatalog.ErrFailedToSummarizeDiff,
+				customerror.WithField("rounds", MaxSummarizeRounds),
+				customerror.WithError(errors.New("the summaries don't fit in the prompt")),
+			)
+		}
+
+		// Summaries aren't a diff, so the diff strategy splits them at line
+		// boundaries, even if the tokenizer can't be loaded.
+		chunks, err = ChunkDiff(textsplitter.StrategyDiff, budget.Encoding, budget.Tokens, merged)
+		if err != nil {
+			return nil, err
+		}
+
+		summaries, err = summarizeRound(ctx, providerInUse, llmAPICallTimeout, tmpl, data, chunks)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// GenerateCommitMessage generates a commit message using LLM API. It's the
+// "reduce" step of the commit message generation: if `data.Summaries` is set,
+// the diff was chunked, and the default template generates the message from
+// the per-chunk summaries instead of `data.Diff`.
 func GenerateCommitMessage(
 	ctx context.Context,
-	providerInUse provider.IProvider,
+	providerInUse LLM,
 	llmAPICallTimeout time.Duration,
-	stats, diff string,
-	chunkNumber, totalChunks int,
-	additionalInstructions string,
+	tmpl *prompt.Template,
+	data prompt.Data,
 ) (string, error) {
-	var prompt string
-	if totalChunks > 1 {
-		// Diff is chunked.
-		prompt = fmt.Sprintf(commitPrompt,
-			"Git diff is too big, so we chunked it into smaller parts!",
-			stats,
-			fmt.Sprintf("Chunk %d of %d:", chunkNumber, totalChunks),
-			diff,
-			fmt.Sprintf("**%s**", additionalInstructions),
-		)
-	} else {
-		// Diff is not chunked.
-		prompt = fmt.Sprintf(commitPrompt,
-			"",
-			stats,
-			"",
-			diff,
-			fmt.Sprintf("**%s**", additionalInstructions),
-		)
+	return StreamCommitMessage(ctx, providerInUse, llmAPICallTimeout, tmpl, data, nil)
+}
+
+// StreamCommitMessage is like GenerateCommitMessage, but streams the message
+// to onUpdate, if set, as it's generated.
+func StreamCommitMessage(
+	ctx context.Context,
+	providerInUse LLM,
+	llmAPICallTimeout time.Duration,
+	tmpl *prompt.Template,
+	data prompt.Data,
+	onUpdate func(partial string),
+) (string, error) {
+	p, err := tmpl.Render(data)
+	if err != nil {
+		return "", err
+	}
+
+	if onUpdate == nil {
+		return CallLLM(ctx, providerInUse, llmAPICallTimeout, p)
+	}
+
+	return StreamLLM(ctx, providerInUse, llmAPICallTimeout, p, onUpdate)
+}
+
+// RepairCommitMessage lints the message, and while it has errors, asks the
+// LLM to fix them, up to MaxLintRepairs times. It returns the last message,
+// and its remaining violations. Nil rules disable linting.
+func RepairCommitMessage(
+	ctx context.Context,
+	providerInUse LLM,
+	llmAPICallTimeout time.Duration,
+	tmpl *prompt.Template,
+	data prompt.Data,
+	message string,
+	rules lint.Rules,
+) (string, []lint.Violation, error) {
+	if rules == nil {
+		return message, nil, nil
 	}
 
-	// Call LLM API
-	message, err := CallLLM(ctx, providerInUse, llmAPICallTimeout, prompt)
+	instructions := data.Instructions
+
+	violations := lint.Lint(message, rules)
+
+	for repair := 0; repair < MaxLintRepairs && lint.HasErrors(violations); repair++ {
+		data.Instructions = strings.TrimSpace(instructions + "\n\n" + lint.FormatInstructions(violations))
+
+		repaired, err := GenerateCommitMessage(ctx, providerInUse, llmAPICallTimeout, tmpl, data)
+		if err != nil {
+			return "", nil, err
+		}
+
+		message, violations = repaired, lint.Lint(repaired, rules)
+	}
+
+	return message, violations, nil
+}
+
+//////
+// Helpers.
+//////
+
+// summarizeRound asks the LLM to summarize every chunk, in order.
+func summarizeRound(
+	ctx context.Context,
+	providerInUse LLM,
+	llmAPICallTimeout time.Duration,
+	tmpl *prompt.Template,
+	data prompt.Data,
+	chunks []string,
+) ([]string, error) {
+	totalChunks := len(chunks)
+
+	summaries := make([]string, 0, totalChunks)
+
+	for i, chunk := range chunks {
+		data.Diff, data.ChunkIndex, data.ChunkTotal = chunk, i+1, totalChunks
+
+		p, err := tmpl.Render(data)
+		if err != nil {
+			return nil, err
+		}
+
+		summary, err := CallLLM(ctx, providerInUse, llmAPICallTimeout, p)
+		if err != nil {
+			return nil, errorcatalog.MustGet(errorcatalog.ErrFailedToSummarizeDiff, customerror.WithError(err))
+		}
+
+		summaries = append(summaries, strings.TrimSpace(summary))
+	}
+
+	return summaries, nil
+}
+
+// streamLLM streams the completion if the provider is able to, otherwise
+// reports the full completion at once.
+func streamLLM(ctx context.Context, providerInUse LLM, prompt string, onUpdate func(partial string)) (string, error) {
+	if streamer, ok := providerInUse.(Streamer); ok {
+		return streamer.Stream(ctx, prompt, onUpdate)
+	}
+
+	response, err := providerInUse.Complete(ctx, prompt)
 	if err != nil {
 		return "", err
 	}
 
-	return message, nil
+	onUpdate(response)
+
+	return response, nil
+}
+
+// handleCancelled asks what to do after the user cancelled the generation.
+func handleCancelled() string {
+	choice := tui.MustPromptWithChoices("What would you like to do?", []string{
+		"Try again",
+		"Write commit message yourself",
+		"Exit",
+	})
+
+	if choice == "Exit" {
+		shared.NothingToDo()
+	}
+
+	return choice
+}
+
+// writeCommitMessage lets the user write the commit message.
+func writeCommitMessage() (string, error) {
+	content, err := tui.CommitMessageTextArea()
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit message: %w", err)
+	}
+
+	return content + "\n", nil
+}
+
+// printUsedProvider prints which provider of a chain produced the message.
+func printUsedProvider(providerInUse LLM) {
+	chain, ok := providerInUse.(*Chain)
+	if !ok || chain.Used() == "" {
+		return
+	}
+
+	note := fmt.Sprintf("Generated by %s", chain.Used())
+	if chain.IsFallback() {
+		note += " (fallback)"
+	}
+
+	fmt.Printf("%s\n\n", tui.HintStyle.Render(note))
+}
+
+// printViolations prints the lint violations of the generated commit message.
+func printViolations(violations []lint.Violation) {
+	for _, v := range violations {
+		style := tui.WarningStyle
+		if v.Level == lint.Error {
+			style = tui.ErrorStyle
+		}
+
+		fmt.Println(style.Render(fmt.Sprintf("  %s: %s", v.Level, v)))
+	}
+
+	if len(violations) > 0 {
+		fmt.Println()
+	}
 }
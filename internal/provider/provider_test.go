@@ -2,10 +2,17 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"expvar"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/thalesfsp/committer/internal/issue"
+	"github.com/thalesfsp/committer/internal/lint"
+	"github.com/thalesfsp/committer/internal/prompt"
+	"github.com/thalesfsp/committer/internal/textsplitter"
 	"github.com/thalesfsp/inference/provider"
 	"github.com/thalesfsp/sypl/v2"
 	"github.com/thalesfsp/sypl/v2/level"
@@ -20,6 +27,13 @@ func (m *mockProvider) Completion(ctx context.Context, options ...provider.Func)
 	return m.completionFunc(ctx, options...)
 }
 
+// Complete implements LLM, so the mock can be used directly.
+func (m *mockProvider) Complete(ctx context.Context, prompt string) (string, error) {
+	return m.completionFunc(ctx, provider.WithUserMessages(prompt))
+}
+
+func (m *mockProvider) Name() string { return m.GetName() }
+
 func (m *mockProvider) GetClient() any                          { return nil }
 func (m *mockProvider) GetLogger() sypl.ISypl                   { return sypl.NewDefault("test", level.Info) }
 func (m *mockProvider) GetName() string                         { return "mock" }
@@ -27,6 +41,30 @@ func (m *mockProvider) GetType() string                         { return "mock"
 func (m *mockProvider) GetCounterCompletion() *expvar.Int       { return expvar.NewInt("mock_completion") }
 func (m *mockProvider) GetCounterCompletionFailed() *expvar.Int { return expvar.NewInt("mock_failed") }
 
+// TestFromInference verifies inference providers are adapted to LLM.
+func TestFromInference(t *testing.T) {
+	called := false
+
+	mock := &mockProvider{
+		completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+			called = true
+
+			return "message", nil
+		},
+	}
+
+	llm := FromInference(mock)
+
+	result, err := llm.Complete(context.Background(), "prompt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !called || result != "message" || llm.Name() != "mock" {
+		t.Errorf("unexpected result: %v %q %q", called, result, llm.Name())
+	}
+}
+
 // TestCallLLM_RespectsParentContext verifies that CallLLM propagates the
 // parent context rather than discarding it.
 //
@@ -128,7 +166,7 @@ func TestCallLLM_RespectsParentContext(t *testing.T) {
 func TestChunkDiff(t *testing.T) {
 	t.Run("small diff returns single chunk", func(t *testing.T) {
 		diff := "small change"
-		chunks, err := ChunkDiff(1000, diff)
+		chunks, err := ChunkDiff(textsplitter.StrategyDiff, "", 1000, diff)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -139,4 +177,233 @@ func TestChunkDiff(t *testing.T) {
 			t.Errorf("expected chunk to equal input diff")
 		}
 	})
+
+	t.Run("invalid strategy fails", func(t *testing.T) {
+		if _, err := ChunkDiff("invalid", "", 1, "big change"); err == nil {
+			t.Error("expected error for invalid strategy")
+		}
+	})
+}
+
+// TestSummarizeChunks verifies the "map" step summarizes every chunk, until
+// the summaries fit in the budget.
+func TestSummarizeChunks(t *testing.T) {
+	tmpl, data := prompt.MustDefault(prompt.SummarizeName), prompt.Data{Stats: "stats"}
+
+	t.Run("single chunk needs no summarization", func(t *testing.T) {
+		mock := &mockProvider{
+			completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+				t.Error("expected no LLM call for a single chunk")
+
+				return "", nil
+			},
+		}
+
+		summaries, err := SummarizeChunks(context.Background(), mock, time.Second, tmpl, data, []string{"diff"}, Budget{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if summaries != nil {
+			t.Errorf("expected nil summaries, got %v", summaries)
+		}
+	})
+
+	t.Run("every chunk is summarized in order", func(t *testing.T) {
+		calls := 0
+
+		mock := &mockProvider{
+			completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+				calls++
+
+				return fmt.Sprintf("  summary %d\n", calls), nil
+			},
+		}
+
+		chunks := []string{"chunk 1", "chunk 2", "chunk 3"}
+
+		summaries, err := SummarizeChunks(context.Background(), mock, time.Second, tmpl, data, chunks, Budget{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if calls != len(chunks) {
+			t.Errorf("expected %d LLM calls, got %d", len(chunks), calls)
+		}
+
+		for i, summary := range summaries {
+			if expected := fmt.Sprintf("summary %d", i+1); summary != expected {
+				t.Errorf("expected %q, got %q", expected, summary)
+			}
+		}
+	})
+
+	t.Run("summaries not fitting are summarized again", func(t *testing.T) {
+		calls := 0
+
+		mock := &mockProvider{
+			completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+				calls++
+
+				// The first round is too verbose, the second one fits.
+				if calls <= 4 {
+					return strings.Repeat("word ", 20), nil
+				}
+
+				return "short", nil
+			},
+		}
+
+		chunks := []string{"chunk 1", "chunk 2", "chunk 3", "chunk 4"}
+
+		summaries, err := SummarizeChunks(context.Background(), mock, time.Second, tmpl, data, chunks, Budget{Tokens: 60})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if calls <= len(chunks) || len(summaries) >= len(chunks) {
+			t.Errorf("expected another, smaller round, got %d calls, %d summaries", calls, len(summaries))
+		}
+
+		for _, summary := range summaries {
+			if summary != "short" {
+				t.Errorf("expected the summaries of the second round, got %q", summary)
+			}
+		}
+	})
+
+	t.Run("summaries never fitting fail", func(t *testing.T) {
+		mock := &mockProvider{
+			completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+				return strings.Repeat("word ", 40), nil
+			},
+		}
+
+		if _, err := SummarizeChunks(context.Background(), mock, time.Second, tmpl, data, []string{"a", "b"}, Budget{Tokens: 30}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+
+	t.Run("failure in any chunk fails the map step", func(t *testing.T) {
+		mock := &mockProvider{
+			completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+				return "", errors.New("boom")
+			},
+		}
+
+		if _, err := SummarizeChunks(context.Background(), mock, time.Second, tmpl, data, []string{"a", "b"}, Budget{}); err == nil {
+			t.Error("expected error, got nil")
+		}
+	})
+}
+
+// TestRepairCommitMessage verifies messages violating lint rules are sent
+// back to the LLM with the violations as instructions.
+func TestRepairCommitMessage(t *testing.T) {
+	tmpl := prompt.MustDefault(prompt.CommitName)
+
+	t.Run("valid message is not repaired", func(t *testing.T) {
+		mock := &mockProvider{
+			completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+				t.Error("expected no LLM call for a valid message")
+
+				return "", nil
+			},
+		}
+
+		message, violations, err := RepairCommitMessage(context.Background(), mock, time.Second, tmpl, prompt.Data{}, "fix: prevent double charge", lint.DefaultRules())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if message != "fix: prevent double charge" || len(violations) != 0 {
+			t.Errorf("unexpected result: %q %v", message, violations)
+		}
+	})
+
+	t.Run("invalid message is repaired", func(t *testing.T) {
+		calls := 0
+
+		mock := &mockProvider{
+			completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+				calls++
+
+				return "fix: prevent double charge", nil
+			},
+		}
+
+		message, violations, err := RepairCommitMessage(context.Background(), mock, time.Second, tmpl, prompt.Data{}, "Fixed stuff.", lint.DefaultRules())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if calls != 1 || message != "fix: prevent double charge" || len(violations) != 0 {
+			t.Errorf("unexpected result: %d calls, %q %v", calls, message, violations)
+		}
+	})
+
+	t.Run("repairs are bounded", func(t *testing.T) {
+		calls := 0
+
+		mock := &mockProvider{
+			completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+				calls++
+
+				return "Still wrong.", nil
+			},
+		}
+
+		_, violations, err := RepairCommitMessage(context.Background(), mock, time.Second, tmpl, prompt.Data{}, "Wrong.", lint.DefaultRules())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if calls != MaxLintRepairs || !lint.HasErrors(violations) {
+			t.Errorf("expected %d calls, and remaining errors, got %d calls, %v", MaxLintRepairs, calls, violations)
+		}
+	})
+}
+
+// TestGenerateCommitMessageOnce verifies chunks are summarized, then merged
+// into a repaired message, referencing the issue of the branch.
+func TestGenerateCommitMessageOnce(t *testing.T) {
+	templates := &prompt.Templates{
+		Commit:    prompt.MustDefault(prompt.CommitName),
+		Summarize: prompt.MustDefault(prompt.SummarizeName),
+	}
+
+	responses := []string{"summary 1", "summary 2", "Fixed stuff.", "fix: prevent double charge"}
+	calls := 0
+
+	mock := &mockProvider{
+		completionFunc: func(ctx context.Context, options ...provider.Func) (string, error) {
+			calls++
+
+			return responses[calls-1], nil
+		},
+	}
+
+	issues, err := issue.New(nil, issue.PlacementFooter, "", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	message, err := GenerateCommitMessageOnce(
+		context.Background(),
+		mock,
+		time.Second,
+		templates,
+		prompt.Data{Branch: "fix/PAY-7-double-charge"},
+		[]string{"chunk 1", "chunk 2"},
+		Budget{},
+		lint.DefaultRules(),
+		issues,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if calls != len(responses) || message != "fix: prevent double charge\n\nRefs: PAY-7" {
+		t.Errorf("unexpected result: %d calls, %q", calls, message)
+	}
 }
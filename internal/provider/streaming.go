@@ -0,0 +1,310 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Default endpoints of the built-in providers, overridable with env vars:
+// OPENAI_BASE_URL, ANTHROPIC_BASE_URL, and OLLAMA_ENDPOINT, like the non
+// streamed calls, or OLLAMA_HOST, like the Ollama CLI.
+const (
+	defaultOpenAIBaseURL    = "https://api.openai.com/v1"
+	defaultAnthropicBaseURL = "https://api.anthropic.com"
+	defaultOllamaHost       = "http://localhost:11434"
+)
+
+// anthropicVersion is the version of the Anthropic API the requests follow.
+const anthropicVersion = "2023-06-01"
+
+// anthropicMaxTokens caps the completion, required by the Anthropic API.
+const anthropicMaxTokens = 4096
+
+// anthropicStreamer streams completions of the Anthropic messages API, as
+// server-sent events.
+type anthropicStreamer struct {
+	client  *http.Client
+	baseURL string
+	model   string
+}
+
+// anthropicRequest is the request of the Anthropic messages API.
+type anthropicRequest struct {
+	Model     string        `json:"model"`
+	MaxTokens int           `json:"max_tokens"`
+	Messages  []chatMessage `json:"messages"`
+	Stream    bool          `json:"stream"`
+}
+
+// anthropicEvent is an event of a streamed response of the Anthropic
+// messages API.
+type anthropicEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ollamaStreamer streams completions of the Ollama generate API, as
+// newline-delimited JSON.
+type ollamaStreamer struct {
+	client *http.Client
+	host   string
+	model  string
+}
+
+// ollamaRequest is the request of the Ollama generate API.
+type ollamaRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+}
+
+// ollamaChunk is a line of a streamed response of the Ollama generate API.
+type ollamaChunk struct {
+	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error"`
+}
+
+//////
+// Helpers.
+//////
+
+// envOr returns the value of the env var, without trailing slash, or the
+// fallback if it's unset.
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return strings.TrimSuffix(value, "/")
+	}
+
+	return fallback
+}
+
+// post sends the payload as JSON to the URL, returning the response if its
+// status is 2xx, otherwise a StatusError. The caller must close its body.
+func post(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	headers map[string]string,
+	payload any,
+) (*http.Response, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		defer res.Body.Close()
+
+		content, _ := io.ReadAll(res.Body)
+
+		return nil, &StatusError{StatusCode: res.StatusCode, Body: string(content)}
+	}
+
+	return res, nil
+}
+
+// scanLines calls fn with every non-empty line of the streamed response, until
+// fn is done, or fails, or the response ends.
+func scanLines(r io.Reader, fn func(line string) (done bool, err error)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		done, err := fn(line)
+		if err != nil || done {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+//////
+// Exported methods.
+//////
+
+// Stream sends the prompt as a user message to the messages API, reading the
+// completion as server-sent events.
+func (s *anthropicStreamer) Stream(
+	ctx context.Context,
+	prompt string,
+	onUpdate func(partial string),
+) (string, error) {
+	res, err := post(ctx, s.client, s.baseURL+"/v1/messages", map[string]string{
+		"x-api-key":         os.Getenv("ANTHROPIC_API_KEY"),
+		"anthropic-version": anthropicVersion,
+		"Accept":            "text/event-stream",
+	}, anthropicRequest{
+		Model:     s.model,
+		MaxTokens: anthropicMaxTokens,
+		Messages:  []chatMessage{{Role: "user", Content: prompt}},
+		Stream:    true,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var completion strings.Builder
+
+	err = scanLines(res.Body, func(line string) (bool, error) {
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			return false, nil
+		}
+
+		var event anthropicEvent
+
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return false, err
+		}
+
+		switch event.Type {
+		case "content_block_delta":
+			if event.Delta.Text != "" {
+				completion.WriteString(event.Delta.Text)
+
+				onUpdate(completion.String())
+			}
+		case "error":
+			// The type, e.g.: "overloaded_error", tells if it's transient.
+			return false, errors.New(event.Error.Type + ": " + event.Error.Message)
+		case "message_stop":
+			return true, nil
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return completion.String(), nil
+}
+
+// Stream sends the prompt to the generate API, reading the completion as
+// newline-delimited JSON.
+func (s *ollamaStreamer) Stream(
+	ctx context.Context,
+	prompt string,
+	onUpdate func(partial string),
+) (string, error) {
+	res, err := post(ctx, s.client, s.host+"/api/generate", nil, ollamaRequest{
+		Model:  s.model,
+		Prompt: prompt,
+		Stream: true,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	var completion strings.Builder
+
+	err = scanLines(res.Body, func(line string) (bool, error) {
+		var chunk ollamaChunk
+
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return false, err
+		}
+
+		if chunk.Error != "" {
+			return false, errors.New(chunk.Error)
+		}
+
+		if chunk.Response != "" {
+			completion.WriteString(chunk.Response)
+
+			onUpdate(completion.String())
+		}
+
+		return chunk.Done, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return completion.String(), nil
+}
+
+//////
+// Factory.
+//////
+
+// newOpenAIStreamer returns the streamer of the OpenAI provider: the OpenAI
+// API is the reference of the OpenAI-compatible protocol.
+func newOpenAIStreamer(model string) Streamer {
+	return &OpenAICompatible{
+		client: &http.Client{},
+		model:  model,
+		options: OpenAICompatibleOptions{
+			BaseURL:   envOr("OPENAI_BASE_URL", defaultOpenAIBaseURL),
+			APIKeyEnv: "OPENAI_API_KEY",
+		},
+	}
+}
+
+// newAnthropicStreamer returns the streamer of the Anthropic provider.
+func newAnthropicStreamer(model string) Streamer {
+	return &anthropicStreamer{
+		client:  &http.Client{},
+		baseURL: envOr("ANTHROPIC_BASE_URL", defaultAnthropicBaseURL),
+		model:   model,
+	}
+}
+
+// newOllamaStreamer returns the streamer of the Ollama provider, sending to
+// OLLAMA_ENDPOINT, like the non streamed calls, otherwise to OLLAMA_HOST. As
+// with the Ollama CLI, the host may omit the scheme, e.g.: "0.0.0.0:11434".
+func newOllamaStreamer(model string) Streamer {
+	host := envOr("OLLAMA_ENDPOINT", envOr("OLLAMA_HOST", defaultOllamaHost))
+
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+
+	return &ollamaStreamer{
+		client: &http.Client{},
+		host:   host,
+		model:  model,
+	}
+}
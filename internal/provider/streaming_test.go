@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestStreamers verifies completions of the built-in providers are streamed
+// from their APIs, against local stand-in servers.
+func TestStreamers(t *testing.T) {
+	t.Run("ollama", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req ollamaRequest
+
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if r.URL.Path != "/api/generate" || !req.Stream || req.Model != "llama3" || req.Prompt != "prompt" {
+				t.Errorf("unexpected request: %s %+v", r.URL.Path, req)
+			}
+
+			w.Write([]byte(strings.Join([]string{
+				`{"response":"feat: ","done":false}`,
+				`{"response":"add it","done":false}`,
+				`{"response":"","done":true}`,
+			}, "\n") + "\n"))
+		}))
+		defer server.Close()
+
+		// As with the Ollama CLI, the scheme is optional.
+		t.Setenv("OLLAMA_ENDPOINT", "")
+		t.Setenv("OLLAMA_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+		assertStreamed(t, newOllamaStreamer("llama3"), "feat: add it", 2)
+	})
+
+	t.Run("ollama endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte(`{"response":"feat: add it","done":true}` + "\n"))
+		}))
+		defer server.Close()
+
+		// Like the non streamed calls, OLLAMA_ENDPOINT comes first.
+		t.Setenv("OLLAMA_ENDPOINT", server.URL)
+		t.Setenv("OLLAMA_HOST", "localhost:1")
+
+		assertStreamed(t, newOllamaStreamer("llama3"), "feat: add it", 1)
+	})
+
+	t.Run("ollama error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte(`{"error":"model \"llama3\" not found"}` + "\n"))
+		}))
+		defer server.Close()
+
+		t.Setenv("OLLAMA_ENDPOINT", "")
+		t.Setenv("OLLAMA_HOST", server.URL)
+
+		if _, err := newOllamaStreamer("llama3").Stream(context.Background(), "prompt", func(string) {}); err == nil || !strings.Contains(err.Error(), "not found") {
+			t.Errorf("expected the error of Ollama, got %v", err)
+		}
+	})
+
+	t.Run("anthropic", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var req anthropicRequest
+
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if r.URL.Path != "/v1/messages" || !req.Stream || req.MaxTokens == 0 || r.Header.Get("x-api-key") != "secret" {
+				t.Errorf("unexpected request: %s %+v", r.URL.Path, req)
+			}
+
+			w.Write([]byte(strings.Join([]string{
+				"event: message_start\ndata: {\"type\":\"message_start\"}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"feat: \"}}",
+				"event: ping\ndata: {\"type\":\"ping\"}",
+				"event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"add it\"}}",
+				"event: message_stop\ndata: {\"type\":\"message_stop\"}",
+			}, "\n\n") + "\n\n"))
+		}))
+		defer server.Close()
+
+		t.Setenv("ANTHROPIC_BASE_URL", server.URL)
+		t.Setenv("ANTHROPIC_API_KEY", "secret")
+
+		assertStreamed(t, newAnthropicStreamer("claude"), "feat: add it", 2)
+	})
+
+	t.Run("anthropic overloaded", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte("event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"))
+		}))
+		defer server.Close()
+
+		t.Setenv("ANTHROPIC_BASE_URL", server.URL)
+
+		_, err := newAnthropicStreamer("claude").Stream(context.Background(), "prompt", func(string) {})
+		if err == nil || !IsTransient(err) {
+			t.Errorf("expected a transient error, got %v", err)
+		}
+	})
+
+	t.Run("openai", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer secret" {
+				t.Errorf("unexpected request: %s", r.URL.Path)
+			}
+
+			w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"feat: add it\"}}]}\n\ndata: [DONE]\n\n"))
+		}))
+		defer server.Close()
+
+		t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+		t.Setenv("OPENAI_API_KEY", "secret")
+
+		assertStreamed(t, newOpenAIStreamer("gpt-4o"), "feat: add it", 1)
+	})
+}
+
+// assertStreamed checks the streamer completes the prompt with the expected
+// completion, in the expected number of updates.
+func assertStreamed(t *testing.T, streamer Streamer, expected string, updates int) {
+	t.Helper()
+
+	partials := []string{}
+
+	result, err := streamer.Stream(context.Background(), "prompt", func(partial string) {
+		partials = append(partials, partial)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result != expected || len(partials) != updates || partials[len(partials)-1] != expected {
+		t.Errorf("unexpected result: %q %q", result, partials)
+	}
+}
@@ -0,0 +1,3 @@
+// Package pullrequest finds the pull request template of a repository, and
+// parses the title, and description of a pull request generated by the LLM.
+package pullrequest
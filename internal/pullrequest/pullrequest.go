@@ -0,0 +1,88 @@
+package pullrequest
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// TemplateFiles are the pull request templates looked up in the repository,
+// in order, as GitHub does.
+var TemplateFiles = []string{
+	".github/pull_request_template.md",
+	".github/PULL_REQUEST_TEMPLATE.md",
+	"pull_request_template.md",
+	"PULL_REQUEST_TEMPLATE.md",
+	"docs/pull_request_template.md",
+	"docs/PULL_REQUEST_TEMPLATE.md",
+}
+
+// titlePrefixRegex matches decorations LLMs add to titles, e.g.: "# ", or
+// "Title: ".
+var titlePrefixRegex = regexp.MustCompile(`^(?:#+\s*)?(?:\*\*)?(?:(?i:title):\s*)?(?:\*\*)?\s*`)
+
+// PullRequest is the title, and description of a pull request.
+type PullRequest struct {
+	// Title of the pull request.
+	Title string `json:"title"`
+
+	// Body is the description of the pull request, in Markdown.
+	Body string `json:"body"`
+}
+
+//////
+// Exported methods.
+//////
+
+// String returns the title, followed by a blank line, and the body.
+func (pr PullRequest) String() string {
+	if pr.Body == "" {
+		return pr.Title + "\n"
+	}
+
+	return pr.Title + "\n\n" + pr.Body + "\n"
+}
+
+//////
+// Exported functionalities.
+//////
+
+// FindTemplate returns the path of the first pull request template found in
+// dir, or "" if there's none.
+func FindTemplate(dir string) string {
+	for _, name := range TemplateFiles {
+		path := filepath.Join(dir, name)
+
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// Parse splits the response of the LLM into the title, its first non-blank
+// line, and the body, the rest. Code fences wrapping the response, and
+// decorations of the title, e.g.: "# ", or "Title: ", are dropped.
+func Parse(response string) PullRequest {
+	response = strings.TrimSpace(strings.ReplaceAll(response, "\r\n", "\n"))
+
+	if strings.HasPrefix(response, "```") && strings.HasSuffix(response, "```") {
+		_, response, _ = strings.Cut(response, "\n")
+		response = strings.TrimSpace(strings.TrimSuffix(response, "```"))
+	}
+
+	title, body, _ := strings.Cut(response, "\n")
+
+	title = strings.TrimSpace(titlePrefixRegex.ReplaceAllString(strings.TrimSpace(title), ""))
+
+	return PullRequest{
+		Title: strings.TrimSpace(strings.TrimSuffix(title, "**")),
+		Body:  strings.TrimSpace(body),
+	}
+}
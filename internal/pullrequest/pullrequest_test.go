@@ -0,0 +1,50 @@
+package pullrequest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestParse verifies the title, and body are split, dropping decorations.
+func TestParse(t *testing.T) {
+	for _, response := range []string{
+		"Add CSV export\n\n## Summary\n\nExports reports.",
+		"# Add CSV export\n\n## Summary\n\nExports reports.",
+		"**Title:** Add CSV export\n\n## Summary\n\nExports reports.",
+		"```markdown\nTitle: Add CSV export\n\n## Summary\n\nExports reports.\n```",
+	} {
+		pr := Parse(response)
+
+		if pr.Title != "Add CSV export" || pr.Body != "## Summary\n\nExports reports." {
+			t.Errorf("%q: unexpected pull request: %+v", response, pr)
+		}
+	}
+
+	if got := Parse("Add CSV export").String(); got != "Add CSV export\n" {
+		t.Errorf("unexpected pull request: %q", got)
+	}
+}
+
+// TestFindTemplate verifies templates are found in the GitHub locations.
+func TestFindTemplate(t *testing.T) {
+	dir := t.TempDir()
+
+	if path := FindTemplate(dir); path != "" {
+		t.Errorf("expected no template, got %q", path)
+	}
+
+	path := filepath.Join(dir, "docs", "pull_request_template.md")
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("## Why\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if found := FindTemplate(dir); found != path {
+		t.Errorf("expected %q, got %q", path, found)
+	}
+}
@@ -0,0 +1,344 @@
+package release
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/lint"
+	"github.com/thalesfsp/committer/internal/semver"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Categories of Keep a Changelog, in order. See https://keepachangelog.com.
+const (
+	CategoryAdded    = "Added"
+	CategoryChanged  = "Changed"
+	CategoryRemoved  = "Removed"
+	CategoryFixed    = "Fixed"
+	CategorySecurity = "Security"
+)
+
+// Categories are the titles of the categories, in order.
+var Categories = []string{CategoryAdded, CategoryChanged, CategoryRemoved, CategoryFixed, CategorySecurity}
+
+// Unreleased is the version of changes not tagged yet.
+const Unreleased = "Unreleased"
+
+// ChangelogHeader starts a new changelog file.
+const ChangelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// hiddenTypes are commit types irrelevant to users, left out of changelogs.
+var hiddenTypes = map[string]bool{
+	"chore": true,
+	"ci":    true,
+	"docs":  true,
+	"style": true,
+	"test":  true,
+}
+
+//go:embed changelog.tmpl
+var defaultChangelogTemplate string
+
+// versionHeadingRegex matches the version of a release heading, e.g.:
+// "## [1.2.0] - 2024-01-01", or "## v1.2.0".
+var versionHeadingRegex = regexp.MustCompile(`^##\s+\[?([^\]\s]+)\]?`)
+
+// Release is a release of the changelog, the data available to changelog
+// templates, e.g.: {{.Version}}.
+type Release struct {
+	// Version of the release, or Unreleased.
+	Version string
+
+	// Date of the release, e.g.: "2024-01-01", empty if unreleased.
+	Date string
+
+	// Sections are the categories of the changes, in order.
+	Sections []Section
+}
+
+//////
+// Helpers.
+//////
+
+// category returns the Keep a Changelog category of the commit message, or
+// "" if it's irrelevant to users.
+func category(m lint.Message) string {
+	switch {
+	case m.Type == "security" || m.Scope == "security":
+		return CategorySecurity
+	case m.Breaking:
+		return CategoryChanged
+	case hiddenTypes[m.Type]:
+		return ""
+	case m.Type == "feat":
+		return CategoryAdded
+	case m.Type == "fix":
+		return CategoryFixed
+	case m.Type == "revert":
+		return CategoryRemoved
+	default:
+		return CategoryChanged
+	}
+}
+
+// releaseRange returns the start, and end offsets of the section of the
+// version in the changelog, up to the next release heading. It's -1 if
+// there's no such section.
+func releaseRange(changelog, version string) (int, int) {
+	start, offset := -1, 0
+
+	for _, line := range strings.SplitAfter(changelog, "\n") {
+		if match := versionHeadingRegex.FindStringSubmatch(line); match != nil {
+			if start >= 0 {
+				return start, offset
+			}
+
+			if sameVersion(match[1], version) {
+				start = offset
+			}
+		}
+
+		offset += len(line)
+	}
+
+	if start >= 0 {
+		return start, len(changelog)
+	}
+
+	return -1, -1
+}
+
+// separator returns the blank line separating a section from the rest of
+// the changelog, if there's any.
+func separator(rest string) string {
+	if rest == "" {
+		return ""
+	}
+
+	return "\n"
+}
+
+// sameVersion checks if the versions are the same, ignoring case, and the
+// "v" prefix.
+func sameVersion(a, b string) bool {
+	return strings.TrimPrefix(strings.ToLower(a), "v") == strings.TrimPrefix(strings.ToLower(b), "v")
+}
+
+// firstRelease returns the offset of the first release heading of the
+// changelog, or -1 if there's none.
+func firstRelease(changelog string) int {
+	offset := 0
+
+	for _, line := range strings.SplitAfter(changelog, "\n") {
+		if versionHeadingRegex.MatchString(line) {
+			return offset
+		}
+
+		offset += len(line)
+	}
+
+	return -1
+}
+
+//////
+// Exported functionalities.
+//////
+
+// GroupChangelog groups the commits into Keep a Changelog categories, in the
+// order of Categories. Within a category, entries are grouped by scope, the
+// ones without scope first. Merges, and changes irrelevant to users, e.g.:
+// docs, tests, or CI, are left out.
+func GroupChangelog(commits []semver.Commit) []Section {
+	entries := map[string][]Entry{}
+
+	for _, c := range commits {
+		m := lint.Parse(c.Message)
+
+		if m.Header == "" || strings.HasPrefix(m.Header, "Merge ") {
+			continue
+		}
+
+		e := Entry{Hash: c.Hash, Scope: m.Scope, Subject: m.Subject, Breaking: m.Breaking, Message: c.Message}
+		if m.Type == "" {
+			e.Subject = m.Header
+		}
+
+		if title := category(m); title != "" {
+			entries[title] = append(entries[title], e)
+		}
+	}
+
+	sections := []Section{}
+
+	for _, title := range Categories {
+		if len(entries[title]) == 0 {
+			continue
+		}
+
+		sort.SliceStable(entries[title], func(i, j int) bool {
+			return entries[title][i].Scope < entries[title][j].Scope
+		})
+
+		sections = append(sections, Section{Title: title, Entries: entries[title]})
+	}
+
+	return sections
+}
+
+// LoadChangelogTemplate parses the changelog template file at path, rendered
+// with a Release. An empty path loads the default, Keep a Changelog,
+// template.
+func LoadChangelogTemplate(path string) (*template.Template, error) {
+	text := defaultChangelogTemplate
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errorcatalog.MustGet(
+				errorcatalog.ErrInvalidChangelogTemplate,
+				customerror.WithError(err),
+			).NewInvalidError()
+		}
+
+		text = string(content)
+	}
+
+	tmpl, err := template.New("changelog").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidChangelogTemplate,
+			customerror.WithError(err),
+		).NewInvalidError()
+	}
+
+	return tmpl, nil
+}
+
+// RenderChangelog renders the section of the release with the template.
+func RenderChangelog(tmpl *template.Template, release Release) (string, error) {
+	var b strings.Builder
+
+	if err := tmpl.Execute(&b, release); err != nil {
+		return "", errorcatalog.MustGet(
+			errorcatalog.ErrInvalidChangelogTemplate,
+			customerror.WithError(err),
+		).NewInvalidError()
+	}
+
+	return b.String(), nil
+}
+
+// PrependChangelog adds the section of the version before the other releases
+// of the changelog, starting a new one if it's empty. The Unreleased section
+// is replaced, as it's either regenerated, or released, while released
+// versions already in the changelog are an error, so they're never
+// duplicated, nor rewritten.
+func PrependChangelog(changelog, section, version string) (string, error) {
+	section = strings.TrimRight(section, "\n") + "\n"
+
+	if strings.TrimSpace(changelog) == "" {
+		return ChangelogHeader + "\n" + section, nil
+	}
+
+	start, end := releaseRange(changelog, version)
+	if start >= 0 && !sameVersion(version, Unreleased) {
+		return "", errorcatalog.MustGet(
+			errorcatalog.ErrFailedToWriteChangelog,
+			customerror.WithError(fmt.Errorf("%s is already in the changelog", version)),
+		).NewFailedToError()
+	}
+
+	// A release supersedes the unreleased changes.
+	if start < 0 {
+		start, end = releaseRange(changelog, Unreleased)
+	}
+
+	if start >= 0 {
+		return changelog[:start] + section + separator(changelog[end:]) + changelog[end:], nil
+	}
+
+	if start := firstRelease(changelog); start >= 0 {
+		return changelog[:start] + section + "\n" + changelog[start:], nil
+	}
+
+	return strings.TrimRight(changelog, "\n") + "\n\n" + section, nil
+}
+
+// Messages returns the commit messages of the entries of the sections, in
+// order, to be polished by the LLM.
+func Messages(sections []Section) []string {
+	messages := []string{}
+
+	for _, s := range sections {
+		for _, e := range s.Entries {
+			messages = append(messages, e.Message)
+		}
+	}
+
+	return messages
+}
+
+// Polish replaces the subjects of the entries with the ones polished by the
+// LLM, answering with a JSON object: {"entries": ["..."]}, one per message
+// returned by Messages, in order. The sections are left untouched if the
+// response is invalid.
+func Polish(sections []Section, response string) ([]Section, error) {
+	var polished struct {
+		Entries []string `json:"entries"`
+	}
+
+	start, end := strings.Index(response, "{"), strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		start, end = 0, len(response)-1
+	}
+
+	if err := json.Unmarshal([]byte(response[start:end+1]), &polished); err != nil {
+		return sections, errorcatalog.MustGet(
+			errorcatalog.ErrFailedToPolishChangelog,
+			customerror.WithError(fmt.Errorf("%w: %s", err, response)),
+		).NewFailedToError()
+	}
+
+	if expected := len(Messages(sections)); len(polished.Entries) != expected {
+		return sections, errorcatalog.MustGet(
+			errorcatalog.ErrFailedToPolishChangelog,
+			customerror.WithError(fmt.Errorf("expected %d entries, got %d", expected, len(polished.Entries))),
+		).NewFailedToError()
+	}
+
+	result, i := make([]Section, 0, len(sections)), 0
+
+	for _, s := range sections {
+		entries := make([]Entry, 0, len(s.Entries))
+
+		for _, e := range s.Entries {
+			if subject := strings.TrimSpace(polished.Entries[i]); subject != "" {
+				e.Subject = subject
+			}
+
+			entries = append(entries, e)
+			i++
+		}
+
+		result = append(result, Section{Title: s.Title, Entries: entries})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package release
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thalesfsp/committer/internal/semver"
+)
+
+// TestRenderChangelog verifies commits are grouped by category, and scope,
+// leaving out the ones irrelevant to users.
+func TestRenderChangelog(t *testing.T) {
+	commits := []semver.Commit{
+		{Hash: "1", Message: "fix(api): handle nil"},
+		{Hash: "2", Message: "feat(ui): add dark mode"},
+		{Hash: "3", Message: "docs: update readme"},
+		{Hash: "4", Message: "feat: add export"},
+		{Hash: "5", Message: "refactor!: rename config keys"},
+		{Hash: "6", Message: "fix(security): escape html"},
+		{Hash: "7", Message: "Merge branch 'main'"},
+	}
+
+	tmpl, err := LoadChangelogTemplate("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := RenderChangelog(tmpl, Release{Version: "1.3.0", Date: "2024-01-01", Sections: GroupChangelog(commits)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `## [1.3.0] - 2024-01-01
+
+### Added
+
+- add export
+- **ui:** add dark mode
+
+### Changed
+
+- **BREAKING:** rename config keys
+
+### Fixed
+
+- **api:** handle nil
+
+### Security
+
+- **security:** escape html
+`
+
+	if out != expected {
+		t.Errorf("unexpected changelog:\n%s", out)
+	}
+}
+
+// TestPrependChangelog verifies sections are added on top, replacing the
+// unreleased one, and never duplicating released ones.
+func TestPrependChangelog(t *testing.T) {
+	first, err := PrependChangelog("", "## [1.0.0]\n\n### Added\n\n- a\n", "1.0.0")
+	if err != nil || !strings.HasPrefix(first, ChangelogHeader+"\n## [1.0.0]\n") {
+		t.Fatalf("unexpected changelog: %q, %v", first, err)
+	}
+
+	unreleased, err := PrependChangelog(first, "## [Unreleased]\n\n### Fixed\n\n- b\n", Unreleased)
+	if err != nil || !strings.Contains(unreleased, "- b\n\n## [1.0.0]\n") {
+		t.Fatalf("unexpected changelog: %q, %v", unreleased, err)
+	}
+
+	regenerated, err := PrependChangelog(unreleased, "## [Unreleased]\n\n### Fixed\n\n- c\n", Unreleased)
+	if err != nil || strings.Contains(regenerated, "- b") || !strings.Contains(regenerated, "- c\n\n## [1.0.0]\n") {
+		t.Fatalf("unexpected changelog: %q, %v", regenerated, err)
+	}
+
+	released, err := PrependChangelog(regenerated, "## [1.0.1]\n\n### Fixed\n\n- c\n", "v1.0.1")
+	if err != nil || strings.Contains(released, Unreleased) || !strings.Contains(released, "\n## [1.0.1]\n\n### Fixed\n\n- c\n\n## [1.0.0]\n") {
+		t.Fatalf("unexpected changelog: %q, %v", released, err)
+	}
+
+	if _, err := PrependChangelog(released, "## [1.0.0]\n", "v1.0.0"); err == nil {
+		t.Error("expected error for a released version already in the changelog")
+	}
+}
+
+// TestPolish verifies polished entries replace the subjects, in order, and
+// invalid responses are rejected.
+func TestPolish(t *testing.T) {
+	sections := GroupChangelog([]semver.Commit{
+		{Hash: "1", Message: "fix: handle nil"},
+		{Hash: "2", Message: "feat: add export"},
+	})
+
+	if messages := Messages(sections); len(messages) != 2 || messages[0] != "feat: add export" {
+		t.Errorf("unexpected messages: %q", messages)
+	}
+
+	polished, err := Polish(sections, "```json\n{\"entries\": [\"Export reports as CSV\", \"Fix crash on empty reports\"]}\n```")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if polished[0].Entries[0].Subject != "Export reports as CSV" || polished[1].Entries[0].Subject != "Fix crash on empty reports" {
+		t.Errorf("unexpected sections: %+v", polished)
+	}
+
+	if sections[0].Entries[0].Subject != "add export" {
+		t.Error("expected the original sections to be left untouched")
+	}
+
+	if _, err := Polish(sections, `{"entries": ["only one"]}`); err == nil {
+		t.Error("expected error for a mismatched number of entries")
+	}
+}
@@ -0,0 +1,3 @@
+// Package release renders release notes from conventional commits, grouped
+// by type: breaking changes, features, fixes, and so on.
+package release
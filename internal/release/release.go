@@ -0,0 +1,146 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/lint"
+	"github.com/thalesfsp/committer/internal/semver"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Titles of the sections of the release notes, in order.
+const (
+	SectionBreaking = "Breaking Changes"
+	SectionFeatures = "Features"
+	SectionFixes    = "Fixes"
+	SectionPerf     = "Performance"
+	SectionOther    = "Other Changes"
+)
+
+// Sections are the titles of the sections, in order.
+var Sections = []string{SectionBreaking, SectionFeatures, SectionFixes, SectionPerf, SectionOther}
+
+// Entry is a commit of the release notes.
+type Entry struct {
+	// Hash of the commit.
+	Hash string
+
+	// Scope of the commit, if any.
+	Scope string
+
+	// Subject of the commit, or its header if it isn't a conventional commit.
+	Subject string
+
+	// Breaking tells if the change is breaking.
+	Breaking bool
+
+	// Message is the whole commit message.
+	Message string
+}
+
+// Section is a group of commits of the same kind.
+type Section struct {
+	// Title of the section, e.g.: "Features".
+	Title string
+
+	// Entries of the section, in order.
+	Entries []Entry
+}
+
+//////
+// Helpers.
+//////
+
+// section returns the title of the section of the commit message.
+func section(m lint.Message) string {
+	switch {
+	case m.Breaking:
+		return SectionBreaking
+	case m.Type == "feat":
+		return SectionFeatures
+	case m.Type == "fix":
+		return SectionFixes
+	case m.Type == "perf":
+		return SectionPerf
+	default:
+		return SectionOther
+	}
+}
+
+//////
+// Exported methods.
+//////
+
+// String returns the entry as a list item, e.g.: "**api:** add export
+// (abc1234)".
+func (e Entry) String() string {
+	s := e.Subject
+
+	if e.Scope != "" {
+		s = fmt.Sprintf("**%s:** %s", e.Scope, s)
+	}
+
+	if e.Hash != "" {
+		s += fmt.Sprintf(" (%s)", e.Hash[:min(7, len(e.Hash))])
+	}
+
+	return s
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Group groups the commits into sections, in the order of Sections. Empty
+// sections are left out. Merge commits are skipped.
+func Group(commits []semver.Commit) []Section {
+	entries := map[string][]Entry{}
+
+	for _, c := range commits {
+		m := lint.Parse(c.Message)
+
+		if m.Header == "" || strings.HasPrefix(m.Header, "Merge ") {
+			continue
+		}
+
+		e := Entry{Hash: c.Hash, Scope: m.Scope, Subject: m.Subject}
+		if m.Type == "" {
+			e.Subject = m.Header
+		}
+
+		title := section(m)
+		entries[title] = append(entries[title], e)
+	}
+
+	sections := []Section{}
+
+	for _, title := range Sections {
+		if len(entries[title]) > 0 {
+			sections = append(sections, Section{Title: title, Entries: entries[title]})
+		}
+	}
+
+	return sections
+}
+
+// Notes renders release notes of the commits, without LLM: a title, followed
+// by a Markdown list of the commits of every section.
+func Notes(tag string, commits []semver.Commit) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Release %s\n", tag)
+
+	for _, s := range Group(commits) {
+		fmt.Fprintf(&b, "\n## %s\n\n", s.Title)
+
+		for _, e := range s.Entries {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+
+	return b.String()
+}
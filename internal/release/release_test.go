@@ -0,0 +1,47 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/thalesfsp/committer/internal/semver"
+)
+
+// TestNotes verifies commits are grouped by type, in order, skipping merges.
+func TestNotes(t *testing.T) {
+	commits := []semver.Commit{
+		{Hash: "1111111111", Message: "fix(api): handle nil"},
+		{Hash: "2222222222", Message: "feat: add export"},
+		{Hash: "3333333333", Message: "Merge branch 'main'"},
+		{Hash: "4444444444", Message: "Update readme"},
+		{Hash: "5555555555", Message: "refactor!: rename config keys"},
+		{Hash: "6666666666", Message: "feat(ui): add dark mode\n\nDetails."},
+	}
+
+	expected := `Release v1.3.0
+
+## Breaking Changes
+
+- rename config keys (5555555)
+
+## Features
+
+- add export (2222222)
+- **ui:** add dark mode (6666666)
+
+## Fixes
+
+- **api:** handle nil (1111111)
+
+## Other Changes
+
+- Update readme (4444444)
+`
+
+	if got := Notes("v1.3.0", commits); got != expected {
+		t.Errorf("unexpected notes:\n%s", got)
+	}
+
+	if sections := Group(nil); len(sections) != 0 {
+		t.Errorf("expected no sections, got %v", sections)
+	}
+}
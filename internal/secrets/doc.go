@@ -0,0 +1,4 @@
+// Package secrets scans diffs for secrets, and credentials, e.g.: API keys,
+// private keys, JWTs, or high-entropy strings, so they're never sent to LLM
+// providers.
+package secrets
@@ -0,0 +1,568 @@
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/gitdiff"
+	"github.com/thalesfsp/customerror"
+	"gopkg.in/yaml.v3"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Modes define what happens when secrets are found.
+const (
+	// ModeAsk asks the user what to do.
+	ModeAsk = "ask"
+
+	// ModeBlock aborts, reporting the findings.
+	ModeBlock = "block"
+
+	// ModeOff disables scanning.
+	ModeOff = "off"
+
+	// ModeRedact replaces the secrets with placeholders.
+	ModeRedact = "redact"
+)
+
+// Modes lists the available modes.
+var Modes = []string{ModeAsk, ModeBlock, ModeOff, ModeRedact}
+
+// Entropy rule settings. Random base64 strings are well above the threshold,
+// while words, paths, and hex digests, e.g.: commit hashes, are below.
+const (
+	EntropyRuleID     = "high-entropy-string"
+	EntropyThreshold  = 4.5
+	EntropyMinLength  = 24
+	entropyCharacters = `[A-Za-z0-9+/=_\-]`
+)
+
+// entropyCandidate matches the strings checked for entropy.
+var entropyCandidate = regexp.MustCompile(entropyCharacters + `{` + fmt.Sprint(EntropyMinLength) + `,}`)
+
+// EntropyIgnoredFiles are files full of checksums, which aren't checked for
+// entropy, e.g.: go.sum.
+var EntropyIgnoredFiles = []string{
+	"*.lock",
+	"go.sum",
+	"package-lock.json",
+	"pnpm-lock.yaml",
+}
+
+// Rule detects a kind of secret.
+type Rule struct {
+	// ID identifies the rule, e.g.: "aws-access-key-id".
+	ID string `yaml:"id"`
+
+	// Description of the secret, e.g.: "AWS access key ID".
+	Description string `yaml:"description"`
+
+	// Pattern matches the secret.
+	Pattern string `yaml:"pattern"`
+
+	// Group is the capture group of Pattern holding the secret, 0 for the
+	// whole match.
+	Group int `yaml:"group"`
+
+	// Files restricts the rule to files whose base name matches any of these
+	// globs, e.g.: ".env*". Empty means every file.
+	Files []string `yaml:"files"`
+
+	// Multiline matches Pattern against every version of the hunks, instead of
+	// each line, for secrets spanning several lines, e.g.: private keys.
+	Multiline bool `yaml:"multiline"`
+
+	regex *regexp.Regexp
+}
+
+// Finding is a secret found in a diff.
+type Finding struct {
+	// Rule is the ID of the rule which found the secret.
+	Rule string
+
+	// Description of the rule.
+	Description string
+
+	// Path of the file.
+	Path string
+
+	// Line is the line number in the new version of the file, or in the old
+	// one, if Removed.
+	Line int
+
+	// Removed tells if the secret is in a removed line. It's in the diff sent
+	// to providers all the same.
+	Removed bool
+
+	// Secret is the matched value.
+	Secret string
+}
+
+// Scanner finds secrets in diffs.
+type Scanner struct {
+	rules []Rule
+	allow []*regexp.Regexp
+}
+
+//////
+// Helpers.
+//////
+
+// compile compiles the pattern of the rule.
+func (r *Rule) compile() error {
+	if r.ID == "" {
+		return fmt.Errorf("rule without id")
+	}
+
+	regex, err := regexp.Compile(r.Pattern)
+	if err != nil {
+		return fmt.Errorf("rule %s: %w", r.ID, err)
+	}
+
+	if r.Group < 0 || r.Group > regex.NumSubexp() {
+		return fmt.Errorf("rule %s: invalid group %d", r.ID, r.Group)
+	}
+
+	r.regex = regex
+
+	return nil
+}
+
+// appliesTo checks if the rule applies to the file.
+func (r *Rule) appliesTo(filePath string) bool {
+	return len(r.Files) == 0 || matchesAny(filePath, r.Files)
+}
+
+// matchesAny checks if the base name of the file matches any of the globs.
+func matchesAny(filePath string, globs []string) bool {
+	base := path.Base(filePath)
+
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, base); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isMixed checks if the string has digits, lower, and upper case letters, as
+// generated secrets do, unlike identifiers, or paths.
+func isMixed(s string) bool {
+	digit := strings.ContainsAny(s, "0123456789")
+	lower := strings.ContainsAny(s, "abcdefghijklmnopqrstuvwxyz")
+	upper := strings.ContainsAny(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+	return digit && lower && upper
+}
+
+// isAllowed checks if the secret matches any of the allowlist patterns.
+func (s *Scanner) isAllowed(secret string) bool {
+	for _, allow := range s.allow {
+		if allow.MatchString(secret) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scanLine returns the secrets found in a line of the file.
+func (s *Scanner) scanLine(filePath string, line string) []Finding {
+	findings := []Finding{}
+	seen := map[string]bool{}
+
+	for _, rule := range s.rules {
+		if rule.Multiline || !rule.appliesTo(filePath) {
+			continue
+		}
+
+		for _, match := range rule.regex.FindAllStringSubmatch(line, -1) {
+			secret := match[rule.Group]
+
+			if secret == "" || seen[secret] || s.isAllowed(secret) {
+				continue
+			}
+
+			seen[secret] = true
+
+			findings = append(findings, Finding{Rule: rule.ID, Description: rule.Description, Path: filePath, Secret: secret})
+		}
+	}
+
+	if matchesAny(filePath, EntropyIgnoredFiles) {
+		return findings
+	}
+
+	for _, candidate := range entropyCandidate.FindAllString(line, -1) {
+		if !isMixed(candidate) || seenWithin(seen, candidate) || s.isAllowed(candidate) ||
+			Entropy(candidate) < EntropyThreshold {
+			continue
+		}
+
+		seen[candidate] = true
+
+		findings = append(findings, Finding{
+			Rule:        EntropyRuleID,
+			Description: "High-entropy string",
+			Path:        filePath,
+			Secret:      candidate,
+		})
+	}
+
+	return findings
+}
+
+// scanBlocks returns the secrets of the multiline rules found in a version
+// of the lines of a hunk, starting at the line number.
+func (s *Scanner) scanBlocks(filePath string, lines []string, start int, removed bool) []Finding {
+	findings := []Finding{}
+	text := strings.Join(lines, "\n")
+
+	for _, rule := range s.rules {
+		if !rule.Multiline || !rule.appliesTo(filePath) {
+			continue
+		}
+
+		for _, match := range rule.regex.FindAllStringSubmatchIndex(text, -1) {
+			begin, end := match[2*rule.Group], match[2*rule.Group+1]
+			if begin < 0 || begin == end || s.isAllowed(text[begin:end]) {
+				continue
+			}
+
+			findings = append(findings, Finding{
+				Rule:        rule.ID,
+				Description: rule.Description,
+				Path:        filePath,
+				Line:        start + strings.Count(text[:begin], "\n"),
+				Removed:     removed,
+				Secret:      text[begin:end],
+			})
+		}
+	}
+
+	return findings
+}
+
+// scanHunk returns the secrets found in the hunk, in order. Every line is
+// scanned, not only added ones: removed, and context lines are sent to
+// providers too.
+func (s *Scanner) scanHunk(filePath string, hunk gitdiff.Hunk) []Finding {
+	findings := []Finding{}
+
+	// The old version of the lines: context, and removed ones, and the new
+	// one: context, and added ones.
+	oldLines, newLines := []string{}, []string{}
+	oldNumber, newNumber := hunk.OldStart, hunk.NewStart
+
+	lines := []Finding{}
+
+	for _, raw := range hunk.Lines {
+		if raw == "" {
+			continue
+		}
+
+		line := strings.TrimSuffix(raw[1:], "\n")
+		number, removed := newNumber, false
+
+		switch raw[0] {
+		case '+':
+			newLines = append(newLines, line)
+			newNumber++
+		case '-':
+			number, removed = oldNumber, true
+
+			oldLines = append(oldLines, line)
+			oldNumber++
+		case ' ':
+			oldLines, newLines = append(oldLines, line), append(newLines, line)
+			oldNumber, newNumber = oldNumber+1, newNumber+1
+		default:
+			// E.g.: "\ No newline at end of file".
+			continue
+		}
+
+		for _, f := range s.scanLine(filePath, line) {
+			f.Line, f.Removed = number, removed
+
+			lines = append(lines, f)
+		}
+	}
+
+	blocks := map[string]bool{}
+
+	for _, f := range append(s.scanBlocks(filePath, newLines, hunk.NewStart, false), s.scanBlocks(filePath, oldLines, hunk.OldStart, true)...) {
+		// Context lines are in both versions.
+		if !blocks[f.Secret] {
+			blocks[f.Secret] = true
+
+			findings = append(findings, f)
+		}
+	}
+
+	// Lines of blocks, e.g.: the body of a private key, are already reported.
+	for _, f := range lines {
+		if !seenWithin(blocks, f.Secret) {
+			findings = append(findings, f)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+
+	return findings
+}
+
+// seenWithin checks if the candidate overlaps an already found secret.
+func seenWithin(seen map[string]bool, candidate string) bool {
+	for secret := range seen {
+		if strings.Contains(candidate, secret) || strings.Contains(secret, candidate) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// placeholder returns the placeholder replacing the secret.
+func placeholder(f Finding) string {
+	return fmt.Sprintf("[REDACTED:%s]", f.Rule)
+}
+
+//////
+// Exported methods.
+//////
+
+// String implements the fmt.Stringer interface.
+func (f Finding) String() string {
+	location := fmt.Sprintf("%s:%d", f.Path, f.Line)
+	if f.Removed {
+		location += " (removed)"
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", location, f.Description, Mask(f.Secret))
+}
+
+// Scan returns the secrets found in the diff, by file, and line.
+func (s *Scanner) Scan(diff string) []Finding {
+	findings := []Finding{}
+
+	for _, file := range gitdiff.Parse(diff) {
+		for _, hunk := range file.Hunks {
+			findings = append(findings, s.scanHunk(file.Path, hunk)...)
+		}
+	}
+
+	return findings
+}
+
+//////
+// Exported functionalities.
+//////
+
+// BuiltinRules returns the built-in rules.
+func BuiltinRules() []Rule {
+	return []Rule{
+		{
+			// The whole key, up to its end, or the end of the hunk, if it's cut.
+			ID:          "private-key",
+			Description: "Private key",
+			Pattern:     `-----BEGIN[A-Z ]* PRIVATE KEY( BLOCK)?-----(?s:.*?)(?:-----END[A-Z ]* PRIVATE KEY( BLOCK)?-----|\z)`,
+			Multiline:   true,
+		},
+		{ID: "aws-access-key-id", Description: "AWS access key ID", Pattern: `\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`, Group: 1},
+		{ID: "github-token", Description: "GitHub token", Pattern: `\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`, Group: 1},
+		{ID: "gitlab-token", Description: "GitLab token", Pattern: `\b(glpat-[A-Za-z0-9_\-]{20,})\b`, Group: 1},
+		{ID: "slack-token", Description: "Slack token", Pattern: `\b(xox[abposr]-[A-Za-z0-9-]{10,})\b`, Group: 1},
+		{ID: "stripe-key", Description: "Stripe key", Pattern: `\b((?:sk|rk)_live_[A-Za-z0-9]{20,})\b`, Group: 1},
+		{ID: "google-api-key", Description: "Google API key", Pattern: `\b(AIza[0-9A-Za-z_\-]{35})\b`, Group: 1},
+		{ID: "llm-api-key", Description: "LLM API key", Pattern: `\b(sk-(?:ant-|proj-)?[A-Za-z0-9_\-]{20,})\b`, Group: 1},
+		{ID: "jwt", Description: "JSON Web Token", Pattern: `\b(eyJ[A-Za-z0-9_\-]{8,}\.eyJ[A-Za-z0-9_\-]{8,}\.[A-Za-z0-9_\-]{8,})`, Group: 1},
+		{
+			ID:          "credential-assignment",
+			Description: "Hardcoded credential",
+			Pattern:     `(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|credentials?)["']?\s*[:=]\s*["']([^"'\s]{8,})["']`,
+			Group:       1,
+		},
+		{
+			ID:          "dotenv-value",
+			Description: "Value of .env file",
+			Pattern:     `^\s*(?:export\s+)?[A-Za-z_][A-Za-z0-9_]*\s*=\s*["']?([^"'\s#]+)`,
+			Group:       1,
+			Files:       []string{".env", ".env.*", "*.env"},
+		},
+	}
+}
+
+// Entropy returns the Shannon entropy of the string, in bits per character.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := map[rune]int{}
+
+	for _, r := range s {
+		counts[r]++
+	}
+
+	entropy, length := 0.0, float64(len([]rune(s)))
+
+	for _, count := range counts {
+		p := float64(count) / length
+
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// Mask hides most of the secret, so reports don't leak it.
+func Mask(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+
+	return secret[:4] + strings.Repeat("*", len(secret)-4)
+}
+
+// Redact replaces the secrets found in the diff with placeholders, e.g.:
+// "[REDACTED:aws-access-key-id]". Secrets spanning several lines are
+// replaced line by line, as lines are prefixed in the diff.
+func Redact(diff string, findings []Finding) string {
+	// Longest first, so secrets containing others are fully replaced.
+	sorted := append([]Finding{}, findings...)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Secret) > len(sorted[j].Secret)
+	})
+
+	for _, f := range sorted {
+		for _, line := range strings.Split(f.Secret, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				diff = strings.ReplaceAll(diff, line, placeholder(f))
+			}
+		}
+	}
+
+	return diff
+}
+
+// Report renders the findings, one per line, with masked secrets.
+func Report(findings []Finding) string {
+	lines := make([]string, 0, len(findings))
+
+	for _, f := range findings {
+		lines = append(lines, f.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// IsMode checks if the mode is valid.
+func IsMode(mode string) bool {
+	for _, m := range Modes {
+		if m == mode {
+			return true
+		}
+	}
+
+	return false
+}
+
+//////
+// Factory.
+//////
+
+// New creates a scanner with the built-in rules, extended by the rule file,
+// if any. The file is YAML, or JSON:
+//
+//	rules:
+//	  - id: internal-token
+//	    description: Internal token
+//	    pattern: 'itk_([a-z0-9]{32})'
+//	    group: 1
+//	allow:
+//	  - 'EXAMPLE'
+//
+// Rules with the ID of a built-in rule replace it. Secrets matching any of
+// the allow patterns are ignored.
+func New(rulesPath string) (*Scanner, error) {
+	rules := BuiltinRules()
+	allow := []string{}
+
+	if rulesPath != "" {
+		content, err := os.ReadFile(rulesPath)
+		if err != nil {
+			return nil, errorcatalog.MustGet(
+				errorcatalog.ErrFailedToLoadSecretRules,
+				customerror.WithError(err),
+			).NewFailedToError()
+		}
+
+		file := struct {
+			Rules []Rule   `yaml:"rules"`
+			Allow []string `yaml:"allow"`
+		}{}
+
+		if err := yaml.Unmarshal(content, &file); err != nil {
+			return nil, errorcatalog.MustGet(
+				errorcatalog.ErrFailedToLoadSecretRules,
+				customerror.WithError(fmt.Errorf("%s: %w", rulesPath, err)),
+			).NewFailedToError()
+		}
+
+		for _, custom := range file.Rules {
+			replaced := false
+
+			for i := range rules {
+				if rules[i].ID == custom.ID {
+					rules[i], replaced = custom, true
+				}
+			}
+
+			if !replaced {
+				rules = append(rules, custom)
+			}
+		}
+
+		allow = file.Allow
+	}
+
+	scanner := &Scanner{}
+
+	for _, rule := range rules {
+		if err := rule.compile(); err != nil {
+			return nil, errorcatalog.MustGet(
+				errorcatalog.ErrFailedToLoadSecretRules,
+				customerror.WithError(fmt.Errorf("%s: %w", rulesPath, err)),
+			).NewFailedToError()
+		}
+
+		scanner.rules = append(scanner.rules, rule)
+	}
+
+	for _, pattern := range allow {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errorcatalog.MustGet(
+				errorcatalog.ErrFailedToLoadSecretRules,
+				customerror.WithError(fmt.Errorf("%s: allow %q: %w", rulesPath, pattern, err)),
+			).NewFailedToError()
+		}
+
+		scanner.allow = append(scanner.allow, regex)
+	}
+
+	return scanner, nil
+}
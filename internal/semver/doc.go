@@ -0,0 +1,4 @@
+// Package semver parses, and bumps semantic versions, inferring the bump from
+// conventional commits: breaking changes bump the major, features the minor,
+// and fixes, or performance improvements the patch.
+package semver
@@ -0,0 +1,351 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/lint"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Bump is the part of the version to be incremented.
+type Bump int
+
+// Bumps, from the lowest to the highest.
+const (
+	None Bump = iota
+	Patch
+	Minor
+	Major
+)
+
+// versionRegex matches semantic versions, optionally prefixed with "v", e.g.:
+// "v1.2.3-rc.1+build.5".
+var versionRegex = regexp.MustCompile(
+	`^(v?)(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`,
+)
+
+// identifiersRegex matches dot separated pre-release, or build identifiers.
+var identifiersRegex = regexp.MustCompile(`^[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*$`)
+
+// Version is a semantic version.
+type Version struct {
+	// Prefix is "v", or "".
+	Prefix string
+
+	Major int
+	Minor int
+	Patch int
+
+	// PreRelease identifiers, e.g.: ["rc", "1"].
+	PreRelease []string
+
+	// Build metadata identifiers, e.g.: ["build", "5"].
+	Build []string
+}
+
+// Commit is a commit to be released.
+type Commit struct {
+	// Hash of the commit.
+	Hash string
+
+	// Message of the commit.
+	Message string
+}
+
+// Reason is a commit, and the bump it requires.
+type Reason struct {
+	Commit
+
+	// Header of the commit message.
+	Header string
+
+	// Bump required by the commit.
+	Bump Bump
+}
+
+//////
+// Helpers.
+//////
+
+// covers checks if a pre-release already includes the bump from the previous
+// release, e.g.: 1.3.0-rc.1 includes a minor bump, 2.0.0-rc.1 a major one.
+func (v Version) covers(bump Bump) bool {
+	switch bump {
+	case Major:
+		return v.Minor == 0 && v.Patch == 0
+	case Minor:
+		return v.Patch == 0
+	default:
+		return true
+	}
+}
+
+// comparePreRelease compares pre-release identifiers, as in the semver spec:
+// numeric identifiers are compared numerically, and have lower precedence
+// than alphanumeric ones. A version without pre-release has the highest
+// precedence.
+func comparePreRelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		x, xErr := strconv.Atoi(a[i])
+		y, yErr := strconv.Atoi(b[i])
+
+		switch {
+		case xErr == nil && yErr == nil:
+			if x != y {
+				return compareInts(x, y)
+			}
+		case xErr == nil:
+			return -1
+		case yErr == nil:
+			return 1
+		case a[i] != b[i]:
+			return strings.Compare(a[i], b[i])
+		}
+	}
+
+	return compareInts(len(a), len(b))
+}
+
+// compareInts returns -1, 0, or 1.
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// splitIdentifiers validates, and splits dot separated identifiers.
+func splitIdentifiers(kind, identifiers string) ([]string, error) {
+	if identifiers == "" {
+		return nil, nil
+	}
+
+	if !identifiersRegex.MatchString(identifiers) {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidVersion,
+			customerror.WithField(kind, identifiers),
+		).NewInvalidError()
+	}
+
+	return strings.Split(identifiers, "."), nil
+}
+
+//////
+// Exported methods.
+//////
+
+// String returns the name of the bump.
+func (b Bump) String() string {
+	switch b {
+	case Major:
+		return "major"
+	case Minor:
+		return "minor"
+	case Patch:
+		return "patch"
+	default:
+		return "none"
+	}
+}
+
+// String returns the version, e.g.: "v1.2.3-rc.1+build.5".
+func (v Version) String() string {
+	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+
+	if len(v.PreRelease) > 0 {
+		s += "-" + strings.Join(v.PreRelease, ".")
+	}
+
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+
+	return s
+}
+
+// Compare returns -1, 0, or 1 if the version has lower, the same, or higher
+// precedence than the other. Prefix, and build metadata are ignored.
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]int{
+		{v.Major, other.Major},
+		{v.Minor, other.Minor},
+		{v.Patch, other.Patch},
+	} {
+		if c := compareInts(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+
+	return comparePreRelease(v.PreRelease, other.PreRelease)
+}
+
+// Next returns the next version, given the bump, the pre-release identifier,
+// e.g.: "rc", and the build metadata, both optional.
+//
+// Before 1.0.0 anything may change, so bumps are shifted down: breaking
+// changes bump the minor, and features the patch. A pre-release is released,
+// or incremented, e.g.: "v1.3.0-rc.1" to "v1.3.0", or "v1.3.0-rc.2", unless
+// the bump goes beyond it, e.g.: a breaking change after "v1.3.0-rc.1" is
+// "v2.0.0". A None bump is a patch.
+func (v Version) Next(bump Bump, preRelease, build string) (Version, error) {
+	pre, err := splitIdentifiers("preRelease", preRelease)
+	if err != nil {
+		return v, err
+	}
+
+	meta, err := splitIdentifiers("build", build)
+	if err != nil {
+		return v, err
+	}
+
+	if v.Major == 0 && bump > Patch {
+		bump--
+	}
+
+	next := Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch, Build: meta}
+
+	isPreRelease := len(v.PreRelease) > 0
+
+	if !isPreRelease || !v.covers(bump) {
+		switch bump {
+		case Major:
+			next.Major, next.Minor, next.Patch = v.Major+1, 0, 0
+		case Minor:
+			next.Minor, next.Patch = v.Minor+1, 0
+		default:
+			next.Patch = v.Patch + 1
+		}
+	}
+
+	if len(pre) == 0 {
+		return next, nil
+	}
+
+	// Same version, and identifier: increment the pre-release number.
+	if next.Compare(Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}) == 0 &&
+		len(v.PreRelease) == len(pre)+1 &&
+		strings.Join(v.PreRelease[:len(pre)], ".") == preRelease {
+		if n, err := strconv.Atoi(v.PreRelease[len(pre)]); err == nil {
+			next.PreRelease = append(pre, strconv.Itoa(n+1))
+
+			return next, nil
+		}
+	}
+
+	next.PreRelease = append(pre, "1")
+
+	return next, nil
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Parse parses a semantic version, optionally prefixed with "v".
+func Parse(version string) (Version, error) {
+	match := versionRegex.FindStringSubmatch(version)
+	if match == nil {
+		return Version{}, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidVersion,
+			customerror.WithField("version", version),
+		).NewInvalidError()
+	}
+
+	v := Version{Prefix: match[1]}
+
+	// Matched by the regex, so valid numbers.
+	v.Major, _ = strconv.Atoi(match[2])
+	v.Minor, _ = strconv.Atoi(match[3])
+	v.Patch, _ = strconv.Atoi(match[4])
+
+	if match[5] != "" {
+		v.PreRelease = strings.Split(match[5], ".")
+	}
+
+	if match[6] != "" {
+		v.Build = strings.Split(match[6], ".")
+	}
+
+	return v, nil
+}
+
+// Latest returns the tag with the highest version, and its version. Tags
+// which aren't semantic versions are ignored. It's false if there's none.
+func Latest(tags []string) (string, Version, bool) {
+	latestTag, latest, found := "", Version{}, false
+
+	for _, tag := range tags {
+		v, err := Parse(tag)
+		if err != nil {
+			continue
+		}
+
+		if !found || v.Compare(latest) > 0 {
+			latestTag, latest, found = tag, v, true
+		}
+	}
+
+	return latestTag, latest, found
+}
+
+// Classify returns the bump required by a conventional commit message.
+// Messages which aren't conventional commits require no bump.
+func Classify(message string) Bump {
+	m := lint.Parse(message)
+
+	switch {
+	case m.Type == "":
+		return None
+	case m.Breaking:
+		return Major
+	case m.Type == "feat":
+		return Minor
+	case m.Type == "fix", m.Type == "perf":
+		return Patch
+	default:
+		return None
+	}
+}
+
+// Infer returns the bump required by the commits, and the commits which
+// drove it: the ones requiring that bump, in order.
+func Infer(commits []Commit) (Bump, []Reason) {
+	bump, reasons := None, []Reason{}
+
+	for _, c := range commits {
+		b := Classify(c.Message)
+
+		if b == None || b < bump {
+			continue
+		}
+
+		if b > bump {
+			bump, reasons = b, []Reason{}
+		}
+
+		reasons = append(reasons, Reason{Commit: c, Header: lint.Parse(c.Message).Header, Bump: b})
+	}
+
+	return bump, reasons
+}
@@ -0,0 +1,133 @@
+package semver
+
+import (
+	"testing"
+)
+
+// TestParse verifies versions are parsed, and printed back.
+func TestParse(t *testing.T) {
+	for _, version := range []string{"v1.2.3", "0.1.0", "v1.3.0-rc.1", "v2.0.0-alpha.beta+build.5", "1.0.0+20240101"} {
+		v, err := Parse(version)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", version, err)
+
+			continue
+		}
+
+		if v.String() != version {
+			t.Errorf("expected %q, got %q", version, v.String())
+		}
+	}
+
+	for _, version := range []string{"", "1.2", "v1.2.3.4", "01.2.3", "release-1", "v1.2.3-"} {
+		if _, err := Parse(version); err == nil {
+			t.Errorf("%q: expected error", version)
+		}
+	}
+}
+
+// TestCompare verifies the precedence of versions, as in the semver spec.
+func TestCompare(t *testing.T) {
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+
+	for i := 1; i < len(ordered); i++ {
+		a, _ := Parse(ordered[i-1])
+		b, _ := Parse(ordered[i])
+
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Errorf("expected %s < %s", a, b)
+		}
+	}
+
+	tag, latest, ok := Latest([]string{"v1.2.0", "nightly", "v1.10.0-rc.1", "v1.9.3"})
+	if !ok || tag != "v1.10.0-rc.1" || latest.Minor != 10 {
+		t.Errorf("unexpected latest: %q %v %v", tag, latest, ok)
+	}
+}
+
+// TestNext verifies bumps, the 0.x rules, pre-releases, and build metadata.
+func TestNext(t *testing.T) {
+	tests := []struct {
+		version    string
+		bump       Bump
+		preRelease string
+		build      string
+		expected   string
+	}{
+		{"v1.2.3", None, "", "", "v1.2.4"},
+		{"v1.2.3", Patch, "", "", "v1.2.4"},
+		{"v1.2.3", Minor, "", "", "v1.3.0"},
+		{"v1.2.3", Major, "", "", "v2.0.0"},
+		{"v0.4.2", Major, "", "", "v0.5.0"},
+		{"v0.4.2", Minor, "", "", "v0.4.3"},
+		{"v1.2.3", Minor, "rc", "", "v1.3.0-rc.1"},
+		{"v1.3.0-rc.1", Patch, "rc", "", "v1.3.0-rc.2"},
+		{"v1.3.0-rc.1", Minor, "", "", "v1.3.0"},
+		{"v1.3.0-rc.1", Major, "", "", "v2.0.0"},
+		{"v1.3.0-beta.3", Minor, "rc", "", "v1.3.0-rc.1"},
+		{"v1.2.3+old", Patch, "", "build.7", "v1.2.4+build.7"},
+	}
+
+	for _, tt := range tests {
+		v, err := Parse(tt.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		next, err := v.Next(tt.bump, tt.preRelease, tt.build)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.version, err)
+
+			continue
+		}
+
+		if next.String() != tt.expected {
+			t.Errorf("%s %s %q: expected %s, got %s", tt.version, tt.bump, tt.preRelease, tt.expected, next)
+		}
+	}
+
+	v, _ := Parse("v1.2.3")
+
+	if _, err := v.Next(Patch, "rc 1", ""); err == nil {
+		t.Error("expected error for invalid pre-release")
+	}
+}
+
+// TestInfer verifies the bump is inferred from conventional commits, along
+// with the commits driving it.
+func TestInfer(t *testing.T) {
+	commits := []Commit{
+		{Hash: "a", Message: "docs: update readme"},
+		{Hash: "b", Message: "fix(api): handle nil"},
+		{Hash: "c", Message: "feat: add export"},
+		{Hash: "d", Message: "Merge branch 'main'"},
+		{Hash: "e", Message: "feat(ui): add dark mode"},
+	}
+
+	bump, reasons := Infer(commits)
+	if bump != Minor || len(reasons) != 2 || reasons[0].Hash != "c" || reasons[1].Header != "feat(ui): add dark mode" {
+		t.Errorf("unexpected result: %s %+v", bump, reasons)
+	}
+
+	commits = append(commits, Commit{Hash: "f", Message: "refactor: rename\n\nBREAKING CHANGE: renamed config keys"})
+
+	if bump, reasons = Infer(commits); bump != Major || len(reasons) != 1 || reasons[0].Hash != "f" {
+		t.Errorf("unexpected result: %s %+v", bump, reasons)
+	}
+
+	if bump, _ = Infer([]Commit{{Hash: "a", Message: "chore: tidy"}}); bump != None {
+		t.Errorf("expected no bump, got %s", bump)
+	}
+}
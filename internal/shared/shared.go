@@ -20,9 +20,10 @@ func IsDebugMode() bool {
 	return syplLevel == "debug"
 }
 
-// NothingToDo prints a message and exits the program.
+// NothingToDo prints a message on stderr, so it never mixes with results, and
+// exits the program.
 func NothingToDo() {
-	fmt.Println("Nothing to do, exiting...")
+	fmt.Fprintln(os.Stderr, "Nothing to do, exiting...")
 
 	os.Exit(0)
 }
@@ -0,0 +1,4 @@
+// Package split plans how staged changes are split into atomic commits: the
+// LLM groups the staged files, and the user edits the groups before they're
+// committed, in order.
+package split
@@ -0,0 +1,203 @@
+package split
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Default titles of groups.
+const (
+	// NewGroupTitle is the title of groups created while editing the plan.
+	NewGroupTitle = "New commit"
+
+	// RemainingTitle is the title of the group of files the LLM left out.
+	RemainingTitle = "Remaining changes"
+)
+
+// Group is a commit of the plan.
+type Group struct {
+	// Title describes the commit, e.g.: "Add login form". It's a hint for the
+	// user, the commit message is generated from the changes.
+	Title string `json:"title"`
+
+	// Files are the paths of the files of the commit, relative to the root
+	// of the repository. Files aren't split: all their staged changes go in
+	// the commit.
+	Files []string `json:"files"`
+}
+
+// Plan is the ordered list of commits the staged changes are split into.
+type Plan struct {
+	// Groups are the commits, in order.
+	Groups []Group `json:"commits"`
+}
+
+//////
+// Helpers.
+//////
+
+// compact removes empty groups.
+func (p *Plan) compact() {
+	groups := p.Groups[:0]
+
+	for _, g := range p.Groups {
+		if len(g.Files) > 0 {
+			groups = append(groups, g)
+		}
+	}
+
+	p.Groups = groups
+}
+
+// extractJSON returns the outermost JSON object of the response, LLMs often
+// wrap it in prose, or code fences.
+func extractJSON(response string) string {
+	start, end := strings.Index(response, "{"), strings.LastIndex(response, "}")
+	if start < 0 || end < start {
+		return ""
+	}
+
+	return response[start : end+1]
+}
+
+//////
+// Exported methods.
+//////
+
+// Files returns the files of all groups, in order.
+func (p *Plan) Files() []string {
+	files := []string{}
+
+	for _, g := range p.Groups {
+		files = append(files, g.Files...)
+	}
+
+	return files
+}
+
+// Find returns the index of the group of the file, or -1.
+func (p *Plan) Find(file string) int {
+	for i, g := range p.Groups {
+		for _, f := range g.Files {
+			if f == file {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// Move moves the file to the group at the index. An index past the last
+// group creates a new one. Groups left empty are removed.
+func (p *Plan) Move(file string, to int) {
+	from := p.Find(file)
+	if from < 0 || from == to || to < 0 {
+		return
+	}
+
+	if to >= len(p.Groups) {
+		p.Groups = append(p.Groups, Group{Title: NewGroupTitle})
+		to = len(p.Groups) - 1
+	}
+
+	files := []string{}
+
+	for _, f := range p.Groups[from].Files {
+		if f != file {
+			files = append(files, f)
+		}
+	}
+
+	p.Groups[from].Files = files
+	p.Groups[to].Files = append(p.Groups[to].Files, file)
+
+	p.compact()
+}
+
+// Merge moves all files of the group at the index into the previous group.
+func (p *Plan) Merge(i int) {
+	if i <= 0 || i >= len(p.Groups) {
+		return
+	}
+
+	p.Groups[i-1].Files = append(p.Groups[i-1].Files, p.Groups[i].Files...)
+	p.Groups = append(p.Groups[:i], p.Groups[i+1:]...)
+}
+
+// Rename changes the title of the group at the index. Blank titles are
+// ignored.
+func (p *Plan) Rename(i int, title string) {
+	if i < 0 || i >= len(p.Groups) || strings.TrimSpace(title) == "" {
+		return
+	}
+
+	p.Groups[i].Title = strings.TrimSpace(title)
+}
+
+//////
+// Exported functionalities.
+//////
+
+// ParsePlan parses the plan proposed by the LLM, as JSON, for the staged
+// files. The plan is fixed, rather than rejected, where possible: unknown
+// files are dropped, files in several groups are kept in the first one, and
+// files left out are grouped in a last commit.
+func ParsePlan(response string, files []string) (*Plan, error) {
+	var proposed Plan
+
+	if err := json.Unmarshal([]byte(extractJSON(response)), &proposed); err != nil {
+		return nil, errorcatalog.MustGet(
+			errorcatalog.ErrInvalidCommitPlan,
+			customerror.WithError(fmt.Errorf("%w: %s", err, response)),
+		).NewInvalidError()
+	}
+
+	staged, planned := map[string]bool{}, map[string]bool{}
+
+	for _, f := range files {
+		staged[f] = true
+	}
+
+	plan := &Plan{}
+
+	for _, g := range proposed.Groups {
+		group := Group{Title: strings.TrimSpace(g.Title)}
+
+		if group.Title == "" {
+			group.Title = NewGroupTitle
+		}
+
+		for _, f := range g.Files {
+			if staged[f] && !planned[f] {
+				planned[f] = true
+
+				group.Files = append(group.Files, f)
+			}
+		}
+
+		plan.Groups = append(plan.Groups, group)
+	}
+
+	remaining := Group{Title: RemainingTitle}
+
+	for _, f := range files {
+		if !planned[f] {
+			remaining.Files = append(remaining.Files, f)
+		}
+	}
+
+	plan.Groups = append(plan.Groups, remaining)
+
+	plan.compact()
+
+	return plan, nil
+}
@@ -0,0 +1,70 @@
+package split
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParsePlan verifies the plan proposed by the LLM is parsed, and fixed.
+func TestParsePlan(t *testing.T) {
+	files := []string{"a.go", "a_test.go", "README.md", "go.mod"}
+
+	response := "Here's the plan:\n```json\n" + `{"commits": [
+		{"title": "Add a", "files": ["a.go", "a_test.go", "unknown.go"]},
+		{"title": "", "files": ["README.md", "a.go"]}
+	]}` + "\n```"
+
+	plan, err := ParsePlan(response, files)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Group{
+		{Title: "Add a", Files: []string{"a.go", "a_test.go"}},
+		{Title: NewGroupTitle, Files: []string{"README.md"}},
+		{Title: RemainingTitle, Files: []string{"go.mod"}},
+	}
+
+	if !reflect.DeepEqual(plan.Groups, expected) {
+		t.Errorf("unexpected plan: %+v", plan.Groups)
+	}
+
+	if _, err := ParsePlan("I can't do that.", files); err == nil {
+		t.Error("expected error for a response without JSON")
+	}
+}
+
+// TestPlan_Edit verifies files are moved, and groups merged, and renamed.
+func TestPlan_Edit(t *testing.T) {
+	plan := &Plan{Groups: []Group{
+		{Title: "First", Files: []string{"a.go", "b.go"}},
+		{Title: "Second", Files: []string{"c.go"}},
+	}}
+
+	// Moving past the last group creates a new one.
+	plan.Move("b.go", 2)
+
+	if len(plan.Groups) != 3 || plan.Find("b.go") != 2 || plan.Groups[2].Title != NewGroupTitle {
+		t.Fatalf("unexpected plan: %+v", plan.Groups)
+	}
+
+	// Groups left empty are removed.
+	plan.Move("c.go", 0)
+
+	if len(plan.Groups) != 2 || plan.Find("c.go") != 0 || plan.Find("b.go") != 1 {
+		t.Fatalf("unexpected plan: %+v", plan.Groups)
+	}
+
+	plan.Rename(1, "  Docs ")
+	plan.Rename(0, " ")
+
+	if plan.Groups[0].Title != "First" || plan.Groups[1].Title != "Docs" {
+		t.Errorf("unexpected titles: %+v", plan.Groups)
+	}
+
+	plan.Merge(1)
+
+	if len(plan.Groups) != 1 || !reflect.DeepEqual(plan.Files(), []string{"a.go", "c.go", "b.go"}) {
+		t.Errorf("unexpected plan: %+v", plan.Groups)
+	}
+}
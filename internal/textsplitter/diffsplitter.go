@@ -0,0 +1,256 @@
+/*
+Diff-aware Text Splitter
+
+This file implements a splitter that understands the structure of a unified
+diff. Instead of cutting the diff at arbitrary token boundaries, it packs whole
+hunks into chunks, so the model always knows which file a change belongs to.
+
+Flow:
+graph TD
+    A[Input Diff] --> B[Initialize Tokenizer]
+    B --> C[Parse Diff into Files and Hunks]
+    C --> D[Pack Hunks into Chunks]
+    D --> E[Return Diff Chunks]
+
+    subgraph "Chunk Processing"
+        D --> F{Hunk fits in chunk?}
+        F -->|Yes| G[Append Hunk]
+        F -->|No| H[Start New Chunk]
+        H --> I[Repeat File Header]
+        I --> G
+        F -->|Bigger than a chunk| J[Split Hunk by Lines]
+    end
+
+NOTE:
+Every chunk continuing a file starts with the file's "diff --git" header. A
+hunk larger than a whole chunk is split at line boundaries, and a line larger
+than a whole chunk is split by tokens, as the TokenSplitter does.
+*/
+
+package textsplitter
+
+import (
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/gitdiff"
+)
+
+//////
+// Types
+//////
+
+// DiffSplitter implements diff splitting functionality based on the structure
+// of the diff, and on token count.
+type DiffSplitter struct {
+	// ChunkSize defines the maximum number of tokens per chunk.
+	ChunkSize int
+
+	// EncodingName specifies the encoding scheme to use for tokenization.
+	EncodingName string
+
+	// ModelName defines the model to determine token encoding.
+	ModelName string
+}
+
+// diffPacker accumulates files, and hunks into chunks.
+type diffPacker struct {
+	budget int
+	count  Counter
+
+	chunks  []string
+	current strings.Builder
+	tokens  int
+
+	// file is the header of the file the current chunk is in, if any.
+	file string
+}
+
+//////
+// Helpers.
+//////
+
+// flush closes the current chunk, if any.
+func (p *diffPacker) flush() {
+	if p.current.Len() > 0 {
+		p.chunks = append(p.chunks, p.current.String())
+	}
+
+	p.current.Reset()
+	p.tokens = 0
+	p.file = ""
+}
+
+// write appends text to the current chunk, opening the file first if the
+// chunk isn't in it yet.
+func (p *diffPacker) write(header, text string, tokens int) {
+	if p.file != header {
+		p.current.WriteString(header)
+		p.tokens += p.count(header)
+		p.file = header
+	}
+
+	p.current.WriteString(text)
+	p.tokens += tokens
+}
+
+// add appends a unit (a hunk, or a file without hunks) to the chunks, starting
+// a new chunk if it doesn't fit in the current one.
+func (p *diffPacker) add(header, text string) {
+	tokens := p.count(text)
+
+	needed := tokens
+	if p.file != header {
+		needed += p.count(header)
+	}
+
+	if p.tokens+needed <= p.budget {
+		p.write(header, text, tokens)
+
+		return
+	}
+
+	p.flush()
+
+	if p.count(header)+tokens <= p.budget {
+		p.write(header, text, tokens)
+
+		return
+	}
+
+	p.addOversized(header, text)
+}
+
+// addOversized splits a unit larger than a whole chunk at line boundaries. Each
+// resulting chunk repeats the file header, and the hunk header.
+func (p *diffPacker) addOversized(header, text string) {
+	lines := strings.SplitAfter(text, "\n")
+
+	// The hunk header ("@@ ... @@") is repeated as part of the file header.
+	if strings.HasPrefix(lines[0], gitdiff.HunkHeaderPrefix) {
+		header += lines[0]
+		lines = lines[1:]
+	}
+
+	// A header larger than a chunk can't be helped, give up on the budget.
+	room := p.budget - p.count(header)
+	if room <= 0 {
+		room = p.budget
+	}
+
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+
+		tokens := p.count(line)
+
+		if p.tokens+tokens > p.budget {
+			p.flush()
+		}
+
+		if p.count(header)+tokens <= p.budget {
+			p.write(header, line, tokens)
+
+			continue
+		}
+
+		// A single line larger than a chunk, last resort: split by tokens.
+		for _, piece := range splitByCount(line, room, p.count) {
+			p.flush()
+			p.write(header, piece, p.count(piece))
+		}
+	}
+
+	p.flush()
+}
+
+// splitByCount splits text into pieces of at most `size` tokens, by runes,
+// without relying on a specific tokenizer.
+func splitByCount(text string, size int, count Counter) []string {
+	pieces := []string{}
+
+	runes := []rune(text)
+
+	for len(runes) > 0 {
+		// Binary search the longest prefix fitting in the size.
+		lo, hi := 1, len(runes)
+
+		for lo < hi {
+			mid := (lo + hi + 1) / 2
+
+			if count(string(runes[:mid])) <= size {
+				lo = mid
+			} else {
+				hi = mid - 1
+			}
+		}
+
+		pieces = append(pieces, string(runes[:lo]))
+		runes = runes[lo:]
+	}
+
+	return pieces
+}
+
+// splitDiff packs the files, and hunks of the diff into chunks of at most
+// `budget` tokens, whenever possible.
+func splitDiff(diff string, budget int, count Counter) []string {
+	files := gitdiff.Parse(diff)
+
+	// Not a diff, nothing to preserve. Treat it as a single, big line.
+	if len(files) == 0 {
+		files = []gitdiff.File{{Hunks: []gitdiff.Hunk{{Lines: []string{diff}}}}}
+	}
+
+	p := &diffPacker{budget: budget, count: count}
+
+	for _, f := range files {
+		if len(f.Hunks) == 0 {
+			p.add("", f.Header)
+
+			continue
+		}
+
+		for _, h := range f.Hunks {
+			p.add(f.Header, h.String())
+		}
+	}
+
+	p.flush()
+
+	return p.chunks
+}
+
+//////
+// Exported functionalities.
+//////
+
+// SplitText divides the input diff into chunks based on its files, hunks, and
+// on token count. Tokens are estimated if the tokenizer can't be loaded, as
+// the diff doesn't need to be split at exact token boundaries.
+func (s DiffSplitter) SplitText(text string) ([]string, error) {
+	return splitDiff(text, s.ChunkSize, NewCounter(s.EncodingName, s.ModelName)), nil
+}
+
+//////
+// Factory.
+//////
+
+// NewDiffSplitter creates a new DiffSplitter with default configuration.
+// It allows overriding the default chunk size through the chunkThreshold
+// parameter.
+func NewDiffSplitter(chunkThreshold int) DiffSplitter {
+	// Initialize with default values.
+	ds := DiffSplitter{
+		ChunkSize:    DefaultTokenChunkSize,
+		EncodingName: DefaultTokenEncoding,
+		ModelName:    DefaultTokenModelName,
+	}
+
+	// Override default chunk size if a threshold is provided.
+	if chunkThreshold > 0 {
+		ds.ChunkSize = chunkThreshold
+	}
+
+	return ds
+}
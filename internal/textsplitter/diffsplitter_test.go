@@ -0,0 +1,118 @@
+package textsplitter
+
+import (
+	"strings"
+	"testing"
+)
+
+// byteCounter counts bytes instead of tokens, so tests don't depend on the
+// tokenizer encodings being available.
+func byteCounter(text string) int {
+	return len(text)
+}
+
+const (
+	headerA = "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n"
+	hunkA1  = "@@ -1 +1 @@\n-one\n+uno\n"
+	hunkA2  = "@@ -5 +5 @@\n-five\n+cinco\n"
+	headerB = "diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n"
+	hunkB1  = "@@ -1 +1 @@\n-two\n+dos\n"
+)
+
+// TestSplitDiff verifies whole hunks are packed into chunks, and file headers
+// are repeated on every chunk continuing a file.
+func TestSplitDiff(t *testing.T) {
+	diff := headerA + hunkA1 + hunkA2 + headerB + hunkB1
+
+	t.Run("diff fitting in the budget is a single chunk", func(t *testing.T) {
+		chunks := splitDiff(diff, len(diff), byteCounter)
+
+		if len(chunks) != 1 || chunks[0] != diff {
+			t.Errorf("expected the diff as a single chunk, got %q", chunks)
+		}
+	})
+
+	t.Run("hunks are never cut, and headers are repeated", func(t *testing.T) {
+		budget := len(headerA) + len(hunkA1) + len(hunkA2) - 1
+
+		chunks := splitDiff(diff, budget, byteCounter)
+
+		expected := []string{
+			headerA + hunkA1,
+			headerA + hunkA2,
+			headerB + hunkB1,
+		}
+
+		if len(chunks) != len(expected) {
+			t.Fatalf("expected %d chunks, got %d: %q", len(expected), len(chunks), chunks)
+		}
+
+		for i := range expected {
+			if chunks[i] != expected[i] {
+				t.Errorf("chunk %d: expected %q, got %q", i, expected[i], chunks[i])
+			}
+		}
+	})
+
+	t.Run("files are packed together when they fit", func(t *testing.T) {
+		budget := len(headerA) + len(hunkA1) + len(headerB) + len(hunkB1)
+
+		chunks := splitDiff(headerA+hunkA1+headerB+hunkB1+headerA+hunkA2, budget, byteCounter)
+
+		if len(chunks) != 2 {
+			t.Fatalf("expected 2 chunks, got %d: %q", len(chunks), chunks)
+		}
+
+		if chunks[0] != headerA+hunkA1+headerB+hunkB1 {
+			t.Errorf("unexpected first chunk: %q", chunks[0])
+		}
+	})
+
+	t.Run("oversized hunks are split by lines", func(t *testing.T) {
+		big := "@@ -1,4 +1,4 @@\n" + strings.Repeat("+0123456789\n", 4)
+		budget := len(headerA) + len("@@ -1,4 +1,4 @@\n") + 2*len("+0123456789\n")
+
+		chunks := splitDiff(headerA+big, budget, byteCounter)
+
+		if len(chunks) != 2 {
+			t.Fatalf("expected 2 chunks, got %d: %q", len(chunks), chunks)
+		}
+
+		for _, chunk := range chunks {
+			if !strings.HasPrefix(chunk, headerA+"@@ -1,4 +1,4 @@\n") {
+				t.Errorf("expected file, and hunk headers to be repeated: %q", chunk)
+			}
+
+			if len(chunk) > budget {
+				t.Errorf("chunk exceeds budget: %d > %d", len(chunk), budget)
+			}
+		}
+	})
+}
+
+// TestNew verifies the splitter factory.
+func TestNew(t *testing.T) {
+	for _, strategy := range Strategies {
+		if _, err := New(strategy, 100, ""); err != nil {
+			t.Errorf("unexpected error for %q: %v", strategy, err)
+		}
+	}
+
+	if _, err := New("invalid", 100, ""); err == nil {
+		t.Error("expected error for invalid strategy")
+	}
+}
+
+// TestNewCounter verifies tokens are estimated if the tokenizer can't be
+// loaded.
+func TestNewCounter(t *testing.T) {
+	count := NewCounter("unknown_encoding", "")
+
+	if tokens := count("twelve chars"); tokens != 3 {
+		t.Errorf("expected 3 estimated tokens, got %d", tokens)
+	}
+
+	if EstimateTokens("") != 0 || EstimateTokens("a") != 1 {
+		t.Error("unexpected estimate")
+	}
+}
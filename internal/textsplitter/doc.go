@@ -1,3 +1,3 @@
-// Package textsplitter provides a simple text splitter that can be used to
-// split text into words.
+// Package textsplitter provides text splitters that can be used to split
+// large texts, such as diffs, into chunks of a maximum number of tokens.
 package textsplitter
@@ -0,0 +1,118 @@
+package textsplitter
+
+import (
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Available splitting strategies.
+const (
+	// StrategyDiff splits along the files, and hunks of a diff.
+	StrategyDiff = "diff"
+
+	// StrategyToken splits at token boundaries, regardless of the content.
+	StrategyToken = "token"
+)
+
+// Strategies lists the available splitting strategies.
+var Strategies = []string{StrategyDiff, StrategyToken}
+
+// charsPerToken is the average length of a token, good enough to estimate
+// counts when no tokenizer is available.
+const charsPerToken = 4
+
+// Counter counts the tokens of a text.
+type Counter func(text string) int
+
+var (
+	counters      = map[string]Counter{} // Counters by encoding, and model.
+	countersMutex sync.Mutex             // Ensures counters are loaded once.
+)
+
+// Splitter divides text into chunks.
+type Splitter interface {
+	// SplitText divides the input text into chunks.
+	SplitText(text string) ([]string, error)
+}
+
+//////
+// Helpers.
+//////
+
+// newTokenizer sets up the tokenizer based on the encoding, or the model name.
+func newTokenizer(encodingName, modelName string) (*tiktoken.Tiktoken, error) {
+	if encodingName != "" {
+		return tiktoken.GetEncoding(encodingName)
+	}
+
+	return tiktoken.EncodingForModel(modelName)
+}
+
+//////
+// Exported functionalities.
+//////
+
+// EstimateTokens estimates the number of tokens of the text, from its length.
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// NewCounter returns the Counter of the encoding, e.g.: "cl100k_base", or of
+// the model, if the encoding is empty. If the tokenizer can't be loaded,
+// e.g.: offline, tokens are estimated with EstimateTokens.
+func NewCounter(encodingName, modelName string) Counter {
+	countersMutex.Lock()
+	defer countersMutex.Unlock()
+
+	key := encodingName + "/" + modelName
+
+	if counter, ok := counters[key]; ok {
+		return counter
+	}
+
+	counter := Counter(EstimateTokens)
+
+	if tk, err := newTokenizer(encodingName, modelName); err == nil {
+		counter = func(text string) int {
+			return len(tk.Encode(text, nil, nil))
+		}
+	}
+
+	counters[key] = counter
+
+	return counter
+}
+
+//////
+// Factory.
+//////
+
+// New creates the splitter for the given strategy, with chunks of at most
+// chunkThreshold tokens, counted with the encoding, or the default one if
+// empty.
+func New(strategy string, chunkThreshold int, encodingName string) (Splitter, error) {
+	switch strategy {
+	case StrategyDiff:
+		s := NewDiffSplitter(chunkThreshold)
+		if encodingName != "" {
+			s.EncodingName = encodingName
+		}
+
+		return s, nil
+	case StrategyToken:
+		s := NewTokenSplitter(chunkThreshold)
+		if encodingName != "" {
+			s.EncodingName = encodingName
+		}
+
+		return s, nil
+	default:
+		return nil, errorcatalog.MustGet(errorcatalog.ErrInvalidChunkStrategy).New()
+	}
+}
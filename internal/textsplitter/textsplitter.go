@@ -90,11 +90,7 @@ type TokenSplitter struct {
 
 // initializeTokenizer sets up the tokenizer based on the configuration.
 func (s TokenSplitter) initializeTokenizer() (*tiktoken.Tiktoken, error) {
-	if s.EncodingName != "" {
-		return tiktoken.GetEncoding(s.EncodingName)
-	}
-
-	return tiktoken.EncodingForModel(s.ModelName)
+	return newTokenizer(s.EncodingName, s.ModelName)
 }
 
 // splitTextIntoChunks performs the actual text splitting operation.
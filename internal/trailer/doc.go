@@ -0,0 +1,4 @@
+// Package trailer builds the trailers of commit messages, e.g.:
+// "Signed-off-by", for the DCO, "Co-authored-by", for pair programming, and
+// custom ones, validating them before git adds them.
+package trailer
@@ -0,0 +1,169 @@
+package trailer
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Tokens of the well-known trailers.
+const (
+	// CoAuthoredBy credits a co-author, e.g.:
+	// "Co-authored-by: Jane Doe <jane@example.com>".
+	CoAuthoredBy = "Co-authored-by"
+
+	// SignedOffBy certifies the Developer Certificate of Origin, e.g.:
+	// "Signed-off-by: Jane Doe <jane@example.com>".
+	SignedOffBy = "Signed-off-by"
+)
+
+// token matches the tokens of trailers, e.g.: "Reviewed-by".
+var token = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// identity matches identities, e.g.: "Jane Doe <jane@example.com>".
+var identity = regexp.MustCompile(`^[^<>]+ <[^<>\s]+@[^<>\s]+>$`)
+
+//////
+// Helpers.
+//////
+
+// email returns the email of the identity, lowercased.
+func email(identity string) string {
+	_, address, _ := strings.Cut(identity, "<")
+
+	return strings.ToLower(strings.TrimSuffix(address, ">"))
+}
+
+// invalidCoAuthor returns the error of a co-author which can't be credited,
+// listing the candidates matching it, if ambiguous.
+func invalidCoAuthor(coAuthor string, matches []string) error {
+	opts := []customerror.Option{customerror.WithField("co-author", coAuthor)}
+
+	if len(matches) > 1 {
+		opts = append(opts, customerror.WithField("matches", strings.Join(matches, "; ")))
+	}
+
+	return errorcatalog.MustGet(errorcatalog.ErrInvalidCoAuthor, opts...).NewInvalidError()
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Parse validates the trailer, e.g.: "Reviewed-by: Jane <jane@example.com>",
+// and returns it formatted like git does: "Token: value".
+func Parse(trailer string) (string, error) {
+	key, value, found := strings.Cut(trailer, ":")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+	if !found || !token.MatchString(key) || value == "" || strings.Contains(value, "\n") {
+		return "", errorcatalog.MustGet(
+			errorcatalog.ErrInvalidTrailer,
+			customerror.WithField("trailer", trailer),
+		).NewInvalidError()
+	}
+
+	return key + ": " + value, nil
+}
+
+// FromMessage returns the trailers of the message, e.g.: "Signed-off-by: Jane
+// <jane@example.com>", formatted like git does: its last paragraph, if it's
+// not the only one, and it only has trailers.
+func FromMessage(message string) []string {
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	trailers := []string{}
+
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		trailer, err := Parse(line)
+		if err != nil {
+			return nil
+		}
+
+		trailers = append(trailers, trailer)
+	}
+
+	return trailers
+}
+
+// Candidates returns the co-authors to pick from: the configured ones first,
+// then the authors of the repository, unique by email, without the user.
+func Candidates(user string, configured, authors []string) []string {
+	seen := []string{email(user)}
+	candidates := []string{}
+
+	for _, candidate := range slices.Concat(configured, authors) {
+		if !identity.MatchString(candidate) || slices.Contains(seen, email(candidate)) {
+			continue
+		}
+
+		seen = append(seen, email(candidate))
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates
+}
+
+// Resolve returns the co-author the query refers to: an identity, e.g.:
+// "Jane <jane@example.com>", as is, otherwise the only candidate containing
+// it, ignoring case, e.g.: "jane".
+func Resolve(query string, candidates []string) (string, error) {
+	query = strings.TrimSpace(query)
+
+	if identity.MatchString(query) {
+		return query, nil
+	}
+
+	matches := []string{}
+
+	for _, candidate := range candidates {
+		if query != "" && strings.Contains(strings.ToLower(candidate), strings.ToLower(query)) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	if len(matches) != 1 {
+		return "", invalidCoAuthor(query, matches)
+	}
+
+	return matches[0], nil
+}
+
+// Build returns the trailers of a commit: the custom ones, crediting the
+// co-authors, and signing off as the user, if set, in this order.
+func Build(custom, coAuthors []string, signoff string) ([]string, error) {
+	trailers := []string{}
+
+	for _, t := range custom {
+		trailer, err := Parse(t)
+		if err != nil {
+			return nil, err
+		}
+
+		trailers = append(trailers, trailer)
+	}
+
+	for _, coAuthor := range coAuthors {
+		if !identity.MatchString(coAuthor) {
+			return nil, invalidCoAuthor(coAuthor, nil)
+		}
+
+		trailers = append(trailers, CoAuthoredBy+": "+coAuthor)
+	}
+
+	if signoff != "" {
+		trailers = append(trailers, SignedOffBy+": "+signoff)
+	}
+
+	return trailers, nil
+}
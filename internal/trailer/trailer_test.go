@@ -0,0 +1,109 @@
+package trailer
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParse verifies trailers are formatted like git does, and invalid ones
+// are rejected.
+func TestParse(t *testing.T) {
+	tests := []struct {
+		trailer string
+		want    string
+		wantErr bool
+	}{
+		{"Reviewed-by: Jane <jane@example.com>", "Reviewed-by: Jane <jane@example.com>", false},
+		{"  Ticket:PAY-1 ", "Ticket: PAY-1", false},
+		{"Reviewed by: Jane", "", true},
+		{"Ticket:", "", true},
+		{"PAY-1", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.trailer)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Parse(%q) = %q, %v, want %q", tt.trailer, got, err, tt.want)
+		}
+	}
+}
+
+// TestFromMessage verifies only the last paragraph is parsed, if it only has
+// trailers.
+func TestFromMessage(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"fix: a\n\nBody.\n\nSigned-off-by: Me <me@example.com>\nTicket:PAY-1\n", "Signed-off-by: Me <me@example.com>,Ticket: PAY-1"},
+		{"fix: a\n\nCo-authored-by: Jane <jane@example.com>", "Co-authored-by: Jane <jane@example.com>"},
+		{"fix: a\n\nSigned-off-by: Me <me@example.com>\nand some prose", ""},
+		{"Ticket: PAY-1", ""},
+	}
+
+	for _, tt := range tests {
+		if got := strings.Join(FromMessage(tt.message), ","); got != tt.want {
+			t.Errorf("FromMessage(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+// TestResolve verifies co-authors are resolved from candidates, unique by
+// email, without the user.
+func TestResolve(t *testing.T) {
+	candidates := Candidates(
+		"Me <me@example.com>",
+		[]string{"Jane Doe <jane@example.com>", "invalid"},
+		[]string{"Me <ME@example.com>", "Jane <JANE@example.com>", "John Doe <john@example.com>"},
+	)
+
+	if strings.Join(candidates, ",") != "Jane Doe <jane@example.com>,John Doe <john@example.com>" {
+		t.Fatalf("candidates = %v", candidates)
+	}
+
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"jane", "Jane Doe <jane@example.com>", false},
+		{"john@", "John Doe <john@example.com>", false},
+		{"Ann <ann@example.com>", "Ann <ann@example.com>", false},
+		{"doe", "", true},
+		{"ann", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := Resolve(tt.query, candidates)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.query, got, err, tt.want)
+		}
+	}
+}
+
+// TestBuild verifies custom trailers come first, then co-authors, and the
+// sign-off.
+func TestBuild(t *testing.T) {
+	trailers, err := Build(
+		[]string{"Reviewed-by: Ann <ann@example.com>"},
+		[]string{"Jane <jane@example.com>"},
+		"Me <me@example.com>",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "Reviewed-by: Ann <ann@example.com>\nCo-authored-by: Jane <jane@example.com>\nSigned-off-by: Me <me@example.com>"
+	if got := strings.Join(trailers, "\n"); got != want {
+		t.Errorf("trailers = %q, want %q", got, want)
+	}
+
+	if _, err := Build(nil, []string{"jane"}, ""); err == nil {
+		t.Error("expected an error for a co-author without email")
+	}
+
+	if _, err := Build([]string{"oops"}, nil, ""); err == nil {
+		t.Error("expected an error for an invalid trailer")
+	}
+}
@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// CompareWidth is the default total width of compared texts.
+const CompareWidth = 100
+
+// columnStyle is the style of a column of compared texts.
+var columnStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color("#767676")).
+	Padding(0, 1)
+
+//////
+// Exported functionalities.
+//////
+
+// Compare renders two versions of a text side by side, e.g.: the old, and the
+// new message of a commit, in titled columns of the same width, fitting the
+// total width. Long lines are wrapped.
+func Compare(leftTitle, left, rightTitle, right string, width int) string {
+	// Borders take a column on each side, and the columns are one apart.
+	inner := max((width-1)/2-2, 10)
+
+	column := func(title, text string, titleStyle lipgloss.Style) string {
+		return columnStyle.Width(inner).Render(titleStyle.Render(title) + "\n\n" + text)
+	}
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		column(leftTitle, left, HintStyle),
+		" ",
+		column(rightTitle, right, QuestionStyle),
+	)
+}
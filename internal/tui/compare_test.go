@@ -0,0 +1,32 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestCompare(t *testing.T) {
+	old := "wip"
+	updated := "feat(api): add the export endpoint, so reports can be downloaded as CSV, or JSON files"
+
+	out := Compare("Old", old, "New", updated, 60)
+
+	for _, s := range []string{"Old", "New", "wip", "feat(api)", "JSON files"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in:\n%s", s, out)
+		}
+	}
+
+	for _, line := range strings.Split(out, "\n") {
+		if w := lipgloss.Width(line); w > 60 {
+			t.Errorf("line is %d wide, more than 60: %q", w, line)
+		}
+	}
+
+	// Both columns are on the same lines.
+	if first := strings.Split(out, "\n")[1]; !strings.Contains(first, "Old") || !strings.Contains(first, "New") {
+		t.Errorf("expected titles side by side, got %q", first)
+	}
+}
@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/shared"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// MultiChoiceModel holds the state of prompts where any number of choices can
+// be picked, including none.
+type MultiChoiceModel struct {
+	question string   // The question to be presented.
+	choices  []string // List of possible choices.
+	selected []bool   // Whether each choice is picked.
+	cursor   int      // Current position of the cursor.
+	accepted bool     // Whether the user confirmed the selection.
+}
+
+//////
+// Exported methods.
+//////
+
+// Init initializes the model.
+func (m MultiChoiceModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update processes key presses: navigation, and toggling choices.
+func (m MultiChoiceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case tea.KeyCtrlC.String(), tea.KeyEsc.String(), "q":
+		// Exit program if user presses Ctrl+C, Esc, or 'q'.
+		shared.NothingToDo()
+	case "enter":
+		m.accepted = true
+
+		return m, tea.Quit
+	case "down", "j":
+		m.cursor = (m.cursor + 1) % len(m.choices)
+	case "up", "k":
+		m.cursor = (m.cursor - 1 + len(m.choices)) % len(m.choices)
+	case " ":
+		m.selected[m.cursor] = !m.selected[m.cursor]
+	}
+
+	return m, nil
+}
+
+// View renders the question, and the choices, with their check boxes.
+func (m MultiChoiceModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(QuestionStyle.Render(m.question))
+	s.WriteString("\n\n")
+
+	for i, choice := range m.choices {
+		cursor := "  "
+
+		if m.cursor == i {
+			cursor = CursorStyle.Render("➤ ")
+		}
+
+		check := "[ ] "
+		if m.selected[i] {
+			check = "[x] "
+		}
+
+		s.WriteString(cursor + check + ChoiceStyle.Render(choice) + "\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(HintStyle.Render(
+		`(Use ↑/↓ to navigate, Space to toggle, Enter to confirm the selection, or "q" to quit)`,
+	))
+	s.WriteString("\n\n")
+
+	return s.String()
+}
+
+// Selection returns the picked choices, in order.
+func (m MultiChoiceModel) Selection() []string {
+	selection := []string{}
+
+	for i, choice := range m.choices {
+		if m.selected[i] {
+			selection = append(selection, choice)
+		}
+	}
+
+	return selection
+}
+
+//////
+// Factory.
+//////
+
+// NewMultiChoiceModel creates the prompt of the choices, none picked.
+func NewMultiChoiceModel(question string, choices []string) MultiChoiceModel {
+	return MultiChoiceModel{
+		question: question,
+		choices:  choices,
+		selected: make([]bool, len(choices)),
+	}
+}
+
+//////
+// Exported functionalities.
+//////
+
+// MustPickMany prompts the user to pick any number of the choices, and
+// returns them, in order. There must be at least a choice.
+func MustPickMany(question string, choices []string) []string {
+	p := tea.NewProgram(NewMultiChoiceModel(question, choices))
+
+	// Runs the program and handles any initialization errors.
+	model, err := p.Run()
+	if err != nil {
+		panic(errorcatalog.
+			MustGet(errorcatalog.ErrFailedToInitTea).
+			NewFailedToError(customerror.WithError(err)),
+		)
+	}
+
+	m, ok := model.(MultiChoiceModel)
+	if !ok || !m.accepted {
+		shared.NothingToDo()
+	}
+
+	return m.Selection()
+}
@@ -0,0 +1,39 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestMultiChoiceModel_Update verifies choices are toggled, and the selection
+// keeps their order.
+func TestMultiChoiceModel_Update(t *testing.T) {
+	var m tea.Model = NewMultiChoiceModel("Who paired?", []string{"Jane", "John", "Ann"})
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+
+	// Pick Ann, wrapping around, then John, and Jane, then unpick John.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = m.Update(space)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = m.Update(space)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m, _ = m.Update(space)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(space)
+
+	if view := m.View(); !strings.Contains(view, "[x] ") || !strings.Contains(view, "Who paired?") {
+		t.Errorf("unexpected view:\n%s", view)
+	}
+
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Error("enter must quit")
+	}
+
+	if got := m.(MultiChoiceModel).Selection(); strings.Join(got, ",") != "Jane,Ann" {
+		t.Errorf("selection = %v", got)
+	}
+}
@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/shared"
+	"github.com/thalesfsp/committer/internal/split"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// PlanModel holds the state of the editor of a commit plan. Rows are the
+// titles of the groups, each followed by its files.
+type PlanModel struct {
+	plan     *split.Plan     // The plan being edited.
+	cursor   int             // Current row.
+	renaming bool            // Whether the title of a group is being edited.
+	input    textinput.Model // The new title of the group.
+	accepted bool            // Whether the user accepted the plan.
+}
+
+// planRow is a row of the editor: a group title if file is empty, otherwise
+// a file of the group.
+type planRow struct {
+	group int
+	file  string
+}
+
+//////
+// Helpers.
+//////
+
+// rows returns the rows of the plan.
+func (m PlanModel) rows() []planRow {
+	rows := []planRow{}
+
+	for i, g := range m.plan.Groups {
+		rows = append(rows, planRow{group: i})
+
+		for _, f := range g.Files {
+			rows = append(rows, planRow{group: i, file: f})
+		}
+	}
+
+	return rows
+}
+
+// clampCursor keeps the cursor within the rows, after they changed.
+func (m *PlanModel) clampCursor() {
+	if total := len(m.rows()); m.cursor >= total {
+		m.cursor = total - 1
+	}
+
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// focus moves the cursor to the row of the file.
+func (m *PlanModel) focus(file string) {
+	for i, row := range m.rows() {
+		if row.file == file {
+			m.cursor = i
+		}
+	}
+}
+
+// updateRenaming handles messages while the title of a group is edited.
+func (m PlanModel) updateRenaming(msg tea.Msg, row planRow) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "enter":
+			m.plan.Rename(row.group, m.input.Value())
+			m.renaming = false
+
+			return m, nil
+		case tea.KeyEsc.String():
+			m.renaming = false
+
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+
+	m.input, cmd = m.input.Update(msg)
+
+	return m, cmd
+}
+
+//////
+// Exported methods.
+//////
+
+// Init initializes the model.
+func (m PlanModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update processes key presses: navigation, moving files between groups,
+// merging, and renaming groups.
+func (m PlanModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	rows := m.rows()
+	row := rows[m.cursor]
+
+	if m.renaming {
+		return m.updateRenaming(msg, row)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key := keyMsg.String(); key {
+	case tea.KeyCtrlC.String(), tea.KeyEsc.String(), "q":
+		// Exit program if user presses Ctrl+C, Esc, or 'q'.
+		shared.NothingToDo()
+	case "enter":
+		m.accepted = true
+
+		return m, tea.Quit
+	case "down", "j":
+		m.cursor = (m.cursor + 1) % len(rows)
+	case "up", "k":
+		m.cursor = (m.cursor - 1 + len(rows)) % len(rows)
+	case "n":
+		// Move the file to a new group.
+		if row.file != "" {
+			m.plan.Move(row.file, len(m.plan.Groups))
+			m.focus(row.file)
+		}
+	case "m":
+		// Merge the group into the previous one.
+		m.plan.Merge(row.group)
+		m.clampCursor()
+	case "r":
+		m.renaming = true
+		m.input = textinput.New()
+		m.input.SetValue(m.plan.Groups[row.group].Title)
+		m.input.Focus()
+
+		return m, textinput.Blink
+	default:
+		// Move the file to the group of the number.
+		if n, err := strconv.Atoi(key); err == nil && row.file != "" && n >= 1 && n <= len(m.plan.Groups) {
+			m.plan.Move(row.file, n-1)
+			m.focus(row.file)
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the plan, and the available actions.
+func (m PlanModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(QuestionStyle.Render("Proposed commits:"))
+	s.WriteString("\n\n")
+
+	for i, row := range m.rows() {
+		cursor := "  "
+
+		if m.cursor == i {
+			cursor = CursorStyle.Render("➤ ")
+		}
+
+		s.WriteString(cursor)
+
+		switch {
+		case row.file != "":
+			s.WriteString("    " + ChoiceStyle.Render(row.file))
+		case m.renaming && m.cursor == i:
+			s.WriteString(fmt.Sprintf("%d. ", row.group+1) + InputStyle.Render(m.input.View()))
+		default:
+			s.WriteString(QuestionStyle.Render(fmt.Sprintf("%d. %s", row.group+1, m.plan.Groups[row.group].Title)))
+		}
+
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+
+	if m.renaming {
+		s.WriteString(HintStyle.Render("(Press Enter to rename, or Esc to cancel)"))
+	} else {
+		s.WriteString(HintStyle.Render(
+			"(Use ↑/↓ to navigate, 1-9 to move the file to that commit, n to move it to a new one,\n" +
+				" m to merge the commit into the previous one, r to rename it, Enter to accept, or \"q\" to quit)",
+		))
+	}
+
+	s.WriteString("\n\n")
+
+	return s.String()
+}
+
+//////
+// Exported functionalities.
+//////
+
+// MustEditPlan lets the user edit the commit plan, returning it once
+// accepted.
+func MustEditPlan(plan *split.Plan) *split.Plan {
+	p := tea.NewProgram(PlanModel{plan: plan})
+
+	// Runs the program and handles any initialization errors.
+	model, err := p.Run()
+	if err != nil {
+		panic(errorcatalog.
+			MustGet(errorcatalog.ErrFailedToInitTea).
+			NewFailedToError(customerror.WithError(err)),
+		)
+	}
+
+	if m, ok := model.(PlanModel); !ok || !m.accepted {
+		shared.NothingToDo()
+	}
+
+	return plan
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thalesfsp/committer/internal/split"
+)
+
+// key returns the message of the key press.
+func key(s string) tea.KeyMsg {
+	switch s {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	}
+
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// TestPlanModel_Update verifies the plan is edited with key presses.
+func TestPlanModel_Update(t *testing.T) {
+	plan := &split.Plan{Groups: []split.Group{
+		{Title: "First", Files: []string{"a.go", "b.go"}},
+		{Title: "Second", Files: []string{"c.go"}},
+	}}
+
+	var m tea.Model = PlanModel{plan: plan}
+
+	// Move b.go (row 2) to the second group, the cursor follows it.
+	m, _ = m.Update(key("down"))
+	m, _ = m.Update(key("down"))
+	m, _ = m.Update(key("2"))
+
+	if plan.Find("b.go") != 1 || m.(PlanModel).cursor != 4 {
+		t.Fatalf("unexpected plan: %+v, cursor %d", plan.Groups, m.(PlanModel).cursor)
+	}
+
+	// Rename the second group.
+	m, _ = m.Update(key("r"))
+
+	for _, r := range " (docs)" {
+		m, _ = m.Update(key(string(r)))
+	}
+
+	m, _ = m.Update(key("enter"))
+
+	if plan.Groups[1].Title != "Second (docs)" {
+		t.Errorf("unexpected title: %q", plan.Groups[1].Title)
+	}
+
+	// Merge it into the first one, and accept.
+	m, _ = m.Update(key("m"))
+	m, cmd := m.Update(key("enter"))
+
+	if len(plan.Groups) != 1 || !m.(PlanModel).accepted || cmd == nil {
+		t.Errorf("unexpected plan: %+v", plan.Groups)
+	}
+
+	if m.View() == "" {
+		t.Error("expected non-empty view")
+	}
+}
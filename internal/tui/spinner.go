@@ -21,6 +21,7 @@ var spinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF875F"))
 
 var (
 	spinnerProgram *tea.Program // Represents the spinner's program instance
+	spinnerText    string       // Text of the running spinner, to resume it
 	spinnerMutex   sync.Mutex   // Ensures thread-safe operation for the spinner
 )
 
@@ -73,8 +74,9 @@ func (m SpinnerModel) View() string {
 
 // SpinnerStart starts the spinner with the given text.
 func SpinnerStart(text string) {
-	// Skip spinner in debug mode to avoid noisy output.
-	if shared.IsDebugMode() {
+	// Skip spinner in debug mode to avoid noisy output, and when there's no
+	// terminal to draw it.
+	if shared.IsDebugMode() || !IsInteractive() {
 		return
 	}
 
@@ -98,7 +100,8 @@ func SpinnerStart(text string) {
 	}
 
 	// Start a new Bubble Tea program for the spinner.
-	spinnerProgram = tea.NewProgram(model)
+	spinnerProgram = tea.NewProgram(model, tea.WithOutput(output))
+	spinnerText = text
 
 	// Run the spinner program asynchronously.
 	go func() {
@@ -124,3 +127,27 @@ func SpinnerStop() {
 		spinnerProgram = nil
 	}
 }
+
+// SpinnerPause stops the running spinner, if any, until fn returns, then
+// resumes it. The spinner has exited, giving back the terminal, before fn is
+// called, so fn can use it, e.g.: git prompting for the passphrase of the
+// signing key.
+func SpinnerPause(fn func() error) error {
+	spinnerMutex.Lock()
+
+	program, text := spinnerProgram, spinnerText
+	spinnerProgram = nil
+
+	spinnerMutex.Unlock()
+
+	if program == nil {
+		return fn()
+	}
+
+	program.Quit()
+	program.Wait()
+
+	defer SpinnerStart(text)
+
+	return fn()
+}
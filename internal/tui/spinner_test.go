@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -93,6 +94,25 @@ func TestSpinnerStop_Idempotent(t *testing.T) {
 	SpinnerStop()
 }
 
+// TestSpinnerStart_NotInteractive verifies no spinner is started without a
+// terminal, e.g.: from scripts, where it used to panic.
+func TestSpinnerStart_NotInteractive(t *testing.T) {
+	previous := interactive
+	interactive = false
+
+	t.Cleanup(func() { interactive = previous })
+
+	SpinnerStart("Working...")
+	defer SpinnerStop()
+
+	spinnerMutex.Lock()
+	defer spinnerMutex.Unlock()
+
+	if spinnerProgram != nil {
+		t.Error("expected no spinner without a terminal")
+	}
+}
+
 // TestSpinnerModel_View_ContainsText verifies the spinner view renders output.
 func TestSpinnerModel_View_ContainsText(t *testing.T) {
 	s := spinner.New()
@@ -108,3 +128,23 @@ func TestSpinnerModel_View_ContainsText(t *testing.T) {
 		t.Error("expected non-empty view")
 	}
 }
+
+// TestSpinnerPause_NoSpinner verifies the function runs, and its error is
+// returned, when no spinner is running.
+func TestSpinnerPause_NoSpinner(t *testing.T) {
+	called := false
+
+	err := SpinnerPause(func() error {
+		called = true
+
+		return errors.New("failed")
+	})
+
+	if !called || err == nil || err.Error() != "failed" {
+		t.Errorf("called = %v, err = %v", called, err)
+	}
+
+	if spinnerProgram != nil {
+		t.Error("no spinner must be started")
+	}
+}
@@ -0,0 +1,360 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/thalesfsp/committer/internal/errorcatalog"
+	"github.com/thalesfsp/committer/internal/gitdiff"
+	"github.com/thalesfsp/committer/internal/shared"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Styles of the diff preview.
+var (
+	addedStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#5FD75F"))
+	removedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
+	rangeStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#5FD7FF"))
+)
+
+// previewLines is the maximum number of lines of the hunk preview.
+const previewLines = 15
+
+// StageModel holds the state of the picker of the changes to be staged. Rows
+// are the files, each followed by its hunks, if expanded.
+type StageModel struct {
+	files    []stageFile // Files with changes.
+	cursor   int         // Current row.
+	accepted bool        // Whether the user confirmed the selection.
+}
+
+// stageFile is a file of the picker.
+type stageFile struct {
+	diff      gitdiff.File // Diff of the file, empty for untracked files.
+	untracked bool         // Whether the file is untracked.
+	atomic    bool         // Whether the file can only be staged as a whole.
+	selected  []bool       // Selected hunks, or the file, if atomic.
+	expanded  bool         // Whether the hunks are listed.
+}
+
+// stageRow is a row of the picker: a file if hunk is -1, otherwise a hunk of
+// the file.
+type stageRow struct {
+	file int
+	hunk int
+}
+
+//////
+// Helpers.
+//////
+
+// isAtomic checks if the file can only be staged as a whole: untracked,
+// binary, deleted, renamed, or mode changes.
+func isAtomic(f gitdiff.File) bool {
+	return len(f.Hunks) == 0 ||
+		strings.Contains(f.Header, "\ndeleted file mode ") ||
+		strings.Contains(f.Header, "\nnew file mode ")
+}
+
+// path returns the path of the file.
+func (f stageFile) path() string {
+	return f.diff.Path
+}
+
+// state returns whether none, some, or all of the file is selected.
+func (f stageFile) state() string {
+	count := 0
+
+	for _, s := range f.selected {
+		if s {
+			count++
+		}
+	}
+
+	switch count {
+	case 0:
+		return "[ ]"
+	case len(f.selected):
+		return "[x]"
+	default:
+		return "[~]"
+	}
+}
+
+// setAll selects, or deselects the whole file.
+func (f *stageFile) setAll(selected bool) {
+	for i := range f.selected {
+		f.selected[i] = selected
+	}
+}
+
+// rows returns the rows of the picker.
+func (m StageModel) rows() []stageRow {
+	rows := []stageRow{}
+
+	for i, f := range m.files {
+		rows = append(rows, stageRow{file: i, hunk: -1})
+
+		if f.expanded && !f.atomic {
+			for h := range f.diff.Hunks {
+				rows = append(rows, stageRow{file: i, hunk: h})
+			}
+		}
+	}
+
+	return rows
+}
+
+// preview renders the hunk, colored, up to previewLines lines.
+func preview(h gitdiff.Hunk) string {
+	var b strings.Builder
+
+	b.WriteString(rangeStyle.Render(strings.TrimSuffix(h.Header, "\n")))
+	b.WriteString("\n")
+
+	for i, line := range h.Lines {
+		if i == previewLines {
+			b.WriteString(HintStyle.Render(fmt.Sprintf("… %d more lines", len(h.Lines)-previewLines)))
+			b.WriteString("\n")
+
+			break
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case strings.HasPrefix(line, "+"):
+			line = addedStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			line = removedStyle.Render(line)
+		default:
+			line = HintStyle.Render(line)
+		}
+
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// selection returns the selected changes: the patch of the tracked files,
+// with only the selected hunks, and the untracked files.
+func (m StageModel) selection() ([]gitdiff.File, []string) {
+	patch, untracked := []gitdiff.File{}, []string{}
+
+	for _, f := range m.files {
+		switch {
+		case f.state() == "[ ]":
+			continue
+		case f.untracked:
+			untracked = append(untracked, f.path())
+		case f.atomic:
+			patch = append(patch, f.diff)
+		default:
+			partial := f.diff
+			partial.Hunks = nil
+
+			for h, hunk := range f.diff.Hunks {
+				if f.selected[h] {
+					partial.Hunks = append(partial.Hunks, hunk)
+				}
+			}
+
+			patch = append(patch, partial)
+		}
+	}
+
+	return patch, untracked
+}
+
+//////
+// Exported methods.
+//////
+
+// Init initializes the model.
+func (m StageModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update processes key presses: navigation, expanding files, and toggling
+// files, or hunks.
+func (m StageModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	rows := m.rows()
+	row := rows[m.cursor]
+	file := &m.files[row.file]
+
+	switch keyMsg.String() {
+	case tea.KeyCtrlC.String(), tea.KeyEsc.String(), "q":
+		// Exit program if user presses Ctrl+C, Esc, or 'q'.
+		shared.NothingToDo()
+	case "enter":
+		m.accepted = true
+
+		return m, tea.Quit
+	case "down", "j":
+		m.cursor = (m.cursor + 1) % len(rows)
+	case "up", "k":
+		m.cursor = (m.cursor - 1 + len(rows)) % len(rows)
+	case "right", "l":
+		file.expanded = true
+	case "left", "h":
+		// Collapse, moving the cursor to the file.
+		file.expanded = false
+
+		for i, r := range m.rows() {
+			if r.file == row.file && r.hunk == -1 {
+				m.cursor = i
+			}
+		}
+	case " ":
+		if row.hunk >= 0 {
+			file.selected[row.hunk] = !file.selected[row.hunk]
+		} else {
+			file.setAll(file.state() != "[x]")
+		}
+	case "a":
+		// Select all, unless all are selected, then deselect all.
+		all := true
+
+		for _, f := range m.files {
+			all = all && f.state() == "[x]"
+		}
+
+		for i := range m.files {
+			m.files[i].setAll(!all)
+		}
+	}
+
+	return m, nil
+}
+
+// View renders the files, their hunks, and a preview of the current hunk.
+func (m StageModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(QuestionStyle.Render("Which changes would you like to stage?"))
+	s.WriteString("\n\n")
+
+	rows := m.rows()
+
+	for i, row := range rows {
+		cursor := "  "
+
+		if m.cursor == i {
+			cursor = CursorStyle.Render("➤ ")
+		}
+
+		f := m.files[row.file]
+
+		s.WriteString(cursor)
+
+		if row.hunk >= 0 {
+			check := "[ ]"
+			if f.selected[row.hunk] {
+				check = "[x]"
+			}
+
+			s.WriteString(fmt.Sprintf("    %s %s", check,
+				rangeStyle.Render(strings.TrimSuffix(f.diff.Hunks[row.hunk].Header, "\n"))))
+		} else {
+			note := ""
+
+			switch {
+			case f.untracked:
+				note = " (untracked)"
+			case !f.atomic:
+				note = fmt.Sprintf(" (%d hunks)", len(f.diff.Hunks))
+			}
+
+			s.WriteString(fmt.Sprintf("%s %s%s", f.state(), ChoiceStyle.Render(f.path()), HintStyle.Render(note)))
+		}
+
+		s.WriteString("\n")
+	}
+
+	if row := rows[m.cursor]; row.hunk >= 0 {
+		s.WriteString("\n")
+		s.WriteString(preview(m.files[row.file].diff.Hunks[row.hunk]))
+	}
+
+	s.WriteString("\n")
+	s.WriteString(HintStyle.Render(
+		"(Use ↑/↓ to navigate, →/← to expand/collapse hunks, Space to toggle, a to toggle all,\n" +
+			" Enter to stage the selection, or \"q\" to quit)",
+	))
+	s.WriteString("\n\n")
+
+	return s.String()
+}
+
+//////
+// Factory.
+//////
+
+// NewStageModel creates the picker of the changes of the diff, unstaged, and
+// the untracked files. Nothing is selected.
+func NewStageModel(diff []gitdiff.File, untracked []string) StageModel {
+	m := StageModel{}
+
+	for _, f := range diff {
+		file := stageFile{diff: f, atomic: isAtomic(f)}
+
+		if file.atomic {
+			file.selected = make([]bool, 1)
+		} else {
+			file.selected = make([]bool, len(f.Hunks))
+		}
+
+		m.files = append(m.files, file)
+	}
+
+	for _, path := range untracked {
+		m.files = append(m.files, stageFile{
+			diff:      gitdiff.File{Path: path},
+			untracked: true,
+			atomic:    true,
+			selected:  make([]bool, 1),
+		})
+	}
+
+	return m
+}
+
+//////
+// Exported functionalities.
+//////
+
+// MustPickChanges lets the user pick the files, and hunks to be staged. It
+// returns the patch of the selected changes of tracked files, and the
+// selected untracked files. There must be at least a change.
+func MustPickChanges(diff []gitdiff.File, untracked []string) ([]gitdiff.File, []string) {
+	p := tea.NewProgram(NewStageModel(diff, untracked))
+
+	// Runs the program and handles any initialization errors.
+	model, err := p.Run()
+	if err != nil {
+		panic(errorcatalog.
+			MustGet(errorcatalog.ErrFailedToInitTea).
+			NewFailedToError(customerror.WithError(err)),
+		)
+	}
+
+	m, ok := model.(StageModel)
+	if !ok || !m.accepted {
+		shared.NothingToDo()
+	}
+
+	return m.selection()
+}
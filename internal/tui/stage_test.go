@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thalesfsp/committer/internal/gitdiff"
+)
+
+// stageDiff changes two hunks of a file.
+const stageDiff = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-// old
++// new
+ 
+@@ -10,3 +10,4 @@ func main() {
+ 	a()
++	b()
+ 	c()
+ }
+`
+
+// TestStageModel_Update verifies files, and hunks are toggled, and the
+// selection only has the selected hunks.
+func TestStageModel_Update(t *testing.T) {
+	var m tea.Model = NewStageModel(gitdiff.Parse(stageDiff), []string{"new.go"})
+
+	// Expand main.go, and select its second hunk.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+
+	if view := m.View(); !strings.Contains(view, "[~]") || !strings.Contains(view, "b()") {
+		t.Errorf("expected partially selected file, and hunk preview:\n%s", view)
+	}
+
+	// Select the untracked file.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+
+	patch, untracked := m.(StageModel).selection()
+
+	if len(patch) != 1 || len(patch[0].Hunks) != 1 || patch[0].Hunks[0].NewStart != 10 {
+		t.Errorf("unexpected patch: %+v", patch)
+	}
+
+	if len(untracked) != 1 || untracked[0] != "new.go" {
+		t.Errorf("unexpected untracked files: %v", untracked)
+	}
+
+	// Toggling all selects everything, then nothing.
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+
+	if patch, _ := m.(StageModel).selection(); len(patch[0].Hunks) != 2 {
+		t.Errorf("expected all hunks, got %+v", patch)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+
+	if patch, untracked := m.(StageModel).selection(); len(patch) != 0 || len(untracked) != 0 {
+		t.Errorf("expected nothing, got %+v %v", patch, untracked)
+	}
+}
@@ -0,0 +1,144 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/thalesfsp/committer/internal/shared"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// StreamFunc produces text, reporting it through update as it grows. It must
+// stop when the context is cancelled.
+type StreamFunc func(ctx context.Context, update func(text string)) (string, error)
+
+// StreamModel renders text as it's streamed, e.g.: the tokens of a commit
+// message while the LLM generates it.
+type StreamModel struct {
+	spinner spinner.Model      // Instance of the spinner model
+	title   string             // Text to be displayed alongside the spinner
+	text    string             // Text streamed so far
+	cancel  context.CancelFunc // Cancels the streaming
+	done    bool               // Whether the streaming is over
+}
+
+// streamUpdateMsg carries the text streamed so far.
+type streamUpdateMsg string
+
+// streamDoneMsg signals the streaming is over.
+type streamDoneMsg struct{}
+
+//////
+// Exported methods.
+//////
+
+// Init initializes the stream view, starting the spinner tick loop.
+func (m StreamModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+// Update handles streamed text, and cancellation requests.
+func (m StreamModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// Cancel the streaming, instead of exiting, if the user presses
+		// Ctrl+C, or Esc. The caller decides what to do next.
+		switch msg.String() {
+		case tea.KeyCtrlC.String(), tea.KeyEsc.String():
+			m.cancel()
+		}
+	case streamUpdateMsg:
+		m.text = string(msg)
+	case streamDoneMsg:
+		m.done = true
+
+		return m, tea.Quit
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+
+		m.spinner, cmd = m.spinner.Update(msg)
+
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// View renders the spinner, and the text streamed so far. Nothing is left on
+// screen once the streaming is over, so the caller can print the result.
+func (m StreamModel) View() string {
+	if m.done {
+		return ""
+	}
+
+	view := ChoiceStyle.Render(fmt.Sprintf("%s %s", m.spinner.View(), m.title)) + "\n\n"
+
+	if m.text != "" {
+		view += m.text + "\n\n"
+	}
+
+	return view + HintStyle.Render("(Press Ctrl+C to cancel)") + "\n"
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Stream runs fn, rendering the text it streams below the title, until it
+// returns. Ctrl+C cancels the context given to fn, so fn returns
+// context.Canceled.
+func Stream(ctx context.Context, title string, fn StreamFunc) (string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Skip the view in debug mode to avoid noisy output.
+	if shared.IsDebugMode() {
+		return fn(ctx, func(string) {})
+	}
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = spinnerStyle
+
+	program := tea.NewProgram(StreamModel{
+		spinner: s,
+		title:   title,
+		cancel:  cancel,
+	}, tea.WithOutput(output))
+
+	var (
+		result string
+		err    error
+	)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		result, err = fn(ctx, func(text string) {
+			program.Send(streamUpdateMsg(text))
+		})
+
+		program.Send(streamDoneMsg{})
+	}()
+
+	// If the view can't run, e.g.: no terminal, fn still runs to completion,
+	// just without being rendered.
+	_, _ = program.Run()
+
+	<-done
+
+	// Providers don't always wrap the context error, make sure callers can
+	// tell a cancellation apart.
+	if err != nil && ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+
+	return result, err
+}
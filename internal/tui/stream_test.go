@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// TestStreamModel_Update verifies streamed text is rendered, and Ctrl+C
+// cancels the streaming instead of exiting.
+func TestStreamModel_Update(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var m tea.Model = StreamModel{
+		spinner: spinner.New(),
+		title:   "Generating commit message...",
+		cancel:  cancel,
+	}
+
+	m, _ = m.Update(streamUpdateMsg("feat: add"))
+	if view := m.View(); !strings.Contains(view, "feat: add") {
+		t.Errorf("expected streamed text in view, got %q", view)
+	}
+
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if ctx.Err() == nil {
+		t.Error("expected Ctrl+C to cancel the context")
+	}
+
+	m, cmd := m.Update(streamDoneMsg{})
+	if cmd == nil || m.View() != "" {
+		t.Errorf("expected the view to quit, and clear once done, got %q", m.View())
+	}
+}
@@ -1,7 +1,11 @@
 package tui
 
 import (
+	"io"
+	"os"
+
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
 )
 
 //////
@@ -12,7 +16,41 @@ import (
 var (
 	ChoiceStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF"))
 	CursorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
+	ErrorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF5F5F"))
 	HintStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#767676"))
 	InputStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00"))
 	QuestionStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF06B7")).Bold(true)
+	WarningStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFAF00"))
 )
+
+// interactive tells if both stdin, and stdout are terminals.
+var interactive = isTerminal(os.Stdin) && isTerminal(os.Stdout)
+
+// output is where spinners, and streams are drawn.
+var output io.Writer = os.Stdout
+
+//////
+// Helpers.
+//////
+
+// isTerminal checks if the file is a terminal, e.g.: not a pipe, a file, nor
+// /dev/null.
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+//////
+// Exported functionalities.
+//////
+
+// IsInteractive checks if the user can be prompted, and spinners shown: both
+// stdin, and stdout are terminals, e.g.: not from scripts, editors, or CI.
+func IsInteractive() bool {
+	return interactive
+}
+
+// SetOutput sets where spinners, and streams are drawn, e.g.: stderr, so
+// stdout only has the result.
+func SetOutput(w io.Writer) {
+	output = w
+}